@@ -5,6 +5,7 @@ go 1.25.5
 require (
 	github.com/cfichtmueller/goparts v0.3.0
 	github.com/cfichtmueller/srv v0.5.1
+	github.com/klauspost/compress v1.20.1
 	github.com/mattn/go-sqlite3 v1.14.32
 	github.com/spf13/cobra v1.10.2
 	golang.org/x/crypto v0.46.0
@@ -5,8 +5,13 @@
 package api
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
 	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/ec"
 )
 
 type CreateArchiveResult struct {
@@ -22,11 +27,22 @@ func handleCreateArchive(c *srv.Context) *srv.Response {
 		return r
 	}
 	t := c.Query("type")
+	source, err := archiveSource(c)
+	if err != nil {
+		return responseFromError(err)
+	}
+	if source != nil {
+		if err := authorize(c, apikey.ActionRead, b.Name, source.Prefix); err != nil {
+			return responseFromError(err)
+		}
+	}
 
 	id, err := archive.Create(c, archive.CreateCommand{
-		Bucket: b.Name,
-		Key:    key,
-		Type:   t,
+		Bucket:    b.Name,
+		Key:       key,
+		Type:      t,
+		Source:    source,
+		CreatedBy: contextGetPrincipal(c),
 	})
 	if err != nil {
 		return responseFromError(err)
@@ -39,10 +55,95 @@ func handleCreateArchive(c *srv.Context) *srv.Response {
 	})
 }
 
+// archiveSource parses the listing an archive is created from. Returns nil if the archive is created from
+// explicit entries.
+func archiveSource(c *srv.Context) (*archive.Source, error) {
+	q := c.Request().URL.Query()
+	if !q.Has("prefix") && !q.Has(queryTag) && !q.Has("modified-since") {
+		return nil, nil
+	}
+	s := &archive.Source{
+		Prefix:      c.Query("prefix"),
+		Delimiter:   c.Query("delimiter"),
+		StripPrefix: q.Has("strip-prefix"),
+	}
+	var err error
+	if s.Tags, err = tagFilter(c); err != nil {
+		return nil, err
+	}
+	if s.ModifiedSince, err = timeQuery(c, "modified-since"); err != nil {
+		return nil, err
+	}
+	if v := c.Query("depth"); v != "" {
+		if s.Depth, err = strconv.Atoi(v); err != nil || s.Depth < 0 {
+			return nil, ec.InvalidArgument
+		}
+	}
+	return s, nil
+}
+
 type ArchiveResponse struct {
-	ID    string `json:"id"`
-	State string `json:"state"`
-	Type  string `json:"type"`
+	ID             string     `json:"id"`
+	Key            string     `json:"key"`
+	State          string     `json:"state"`
+	Type           string     `json:"type"`
+	EntriesDone    int64      `json:"entriesDone"`
+	EntriesTotal   int64      `json:"entriesTotal"`
+	EntriesSkipped int64      `json:"entriesSkipped"`
+	BytesWritten   int64      `json:"bytesWritten"`
+	FailureReason  string     `json:"failureReason,omitempty"`
+	CreatedBy      string     `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"`
+}
+
+func newArchiveResponse(a *archive.Archive) ArchiveResponse {
+	return ArchiveResponse{
+		ID:             a.ID,
+		Key:            a.Key,
+		State:          a.State,
+		Type:           a.Type,
+		EntriesDone:    a.EntriesDone,
+		EntriesTotal:   a.EntriesTotal,
+		EntriesSkipped: a.EntriesSkipped,
+		BytesWritten:   a.BytesWritten,
+		FailureReason:  a.FailureReason,
+		CreatedBy:      a.CreatedBy,
+		CreatedAt:      a.CreatedAt,
+		FinishedAt:     a.FinishedAt,
+	}
+}
+
+const maxArchives = 1000
+
+type ListArchivesResponse struct {
+	Archives []ArchiveResponse `json:"archives"`
+	// Cursor is passed to the next request to receive the archives after this response
+	Cursor      string `json:"cursor"`
+	IsTruncated bool   `json:"isTruncated"`
+}
+
+// handleListArchives lists the archives of a bucket from the newest to the oldest
+func handleListArchives(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	limit, r := c.IntQueryOrDefault("max-archives", maxArchives)
+	if r != nil {
+		return r
+	}
+	limit = max(1, min(limit, maxArchives))
+	archives, err := archive.List(c, b.Name, c.Query(queryCursor), limit)
+	if err != nil {
+		return responseFromError(err)
+	}
+	res := ListArchivesResponse{
+		Archives:    make([]ArchiveResponse, 0, len(archives)),
+		IsTruncated: len(archives) == limit,
+	}
+	for _, a := range archives {
+		res.Archives = append(res.Archives, newArchiveResponse(a))
+		res.Cursor = a.ID
+	}
+	return srv.Respond().Json(res)
 }
 
 func handleGetArchive(c *srv.Context) *srv.Response {
@@ -58,11 +159,7 @@ func handleGetArchive(c *srv.Context) *srv.Response {
 	if r != nil {
 		return r
 	}
-	return srv.Respond().Json(ArchiveResponse{
-		ID:    arch.ID,
-		State: arch.State,
-		Type:  arch.Type,
-	})
+	return srv.Respond().Json(newArchiveResponse(arch))
 }
 
 type AddArchiveEntriesRequest struct {
@@ -78,6 +175,11 @@ func handleAddArchiveEntries(c *srv.Context) *srv.Response {
 	if r := c.BindJSON(&req); r != nil {
 		return r
 	}
+	for _, e := range req.Entries {
+		if err := authorize(c, apikey.ActionRead, arch.Bucket, e.Key); err != nil {
+			return responseFromError(err)
+		}
+	}
 
 	if err := archive.AddEntries(c, arch, req.Entries); err != nil {
 		return responseFromError(err)
@@ -117,3 +219,29 @@ func handleAbortArchive(c *srv.Context) *srv.Response {
 
 	return srv.Respond().NoContent()
 }
+
+func handleRetryArchive(c *srv.Context) *srv.Response {
+	arch, r := archiveFilter(c)
+	if r != nil {
+		return r
+	}
+
+	if err := archive.Retry(c, arch); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().NoContent()
+}
+
+func handleCancelArchive(c *srv.Context) *srv.Response {
+	arch, r := archiveFilter(c)
+	if r != nil {
+		return r
+	}
+
+	if err := archive.Cancel(c, arch); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().NoContent()
+}
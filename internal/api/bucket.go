@@ -35,6 +35,8 @@ type ObjectReference struct {
 
 type DeleteResults struct {
 	Results []DeleteResult `json:"results"`
+	// IsTruncated is true if more objects match the filter of the request
+	IsTruncated bool `json:"isTruncated,omitempty"`
 }
 
 type DeleteResult struct {
@@ -47,10 +49,67 @@ func handleBucketPost(c *srv.Context) *srv.Response {
 	q := c.Request().URL.Query()
 	if q.Has("delete") {
 		return handleDeleteObjects(c)
+	} else if q.Has("copy") {
+		return handleCopyObjects(c)
+	} else if q.Has(queryMovePrefix) {
+		return handleMovePrefix(c)
+	} else if q.Has(queryDeletePrefix) {
+		return handleDeletePrefix(c)
+	} else if q.Has(queryCopyPrefix) {
+		return handleCopyPrefix(c)
+	} else if q.Has(queryDownloadZip) {
+		return handleDownloadZip(c)
 	}
 	return srv.Respond().MethodNotAllowed()
 }
 
+func handleBucketGet(c *srv.Context) *srv.Response {
+	if c.Request().URL.Query().Has(queryDownloadZip) {
+		return handleGetZipDownload(c)
+	} else if c.Request().URL.Query().Has(queryLifecycle) {
+		return handleGetLifecycle(c)
+	} else if c.Request().URL.Query().Has(queryObjectLock) {
+		return handleGetBucketObjectLock(c)
+	} else if c.Request().URL.Query().Has(queryNotification) {
+		return handleGetNotification(c)
+	} else if c.Request().URL.Query().Has(queryChanges) {
+		return handleListChanges(c)
+	} else if c.Request().URL.Query().Has(queryArchives) {
+		return handleListArchives(c)
+	} else if c.Request().URL.Query().Has(queryEvents) {
+		return handleStreamEvents(c)
+	} else if c.Query(queryMoveId) != "" {
+		return handleGetMove(c)
+	} else if c.Query(queryJobId) != "" && c.Request().URL.Query().Has(queryResults) {
+		return handleListExtractResults(c)
+	} else if c.Query(queryJobId) != "" {
+		return handleGetJob(c)
+	}
+	return handleListObjects(c)
+}
+
+func handleBucketPut(c *srv.Context) *srv.Response {
+	if c.Request().URL.Query().Has(queryLifecycle) {
+		return handlePutLifecycle(c)
+	} else if c.Request().URL.Query().Has(queryObjectLock) {
+		return handlePutBucketObjectLock(c)
+	} else if c.Request().URL.Query().Has(queryNotification) {
+		return handlePutNotification(c)
+	}
+	return handleCreateBucket(c)
+}
+
+func handleBucketDelete(c *srv.Context) *srv.Response {
+	if c.Request().URL.Query().Has(queryLifecycle) {
+		return handleDeleteLifecycle(c)
+	} else if c.Request().URL.Query().Has(queryNotification) {
+		return handleDeleteNotification(c)
+	} else if c.Query(queryJobId) != "" {
+		return handleCancelJob(c)
+	}
+	return handleDeleteBucket(c)
+}
+
 func handleCreateBucket(c *srv.Context) *srv.Response {
 	name := c.PathValue("bucketName")
 	if err := bucket.ValidateName(name); err != nil {
@@ -0,0 +1,108 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+const (
+	maxChanges = 1000
+	// maxChangesWait is the longest time a request waits for changes
+	maxChangesWait = 60
+)
+
+type ListChangesResponse struct {
+	Changes []ChangeResponse `json:"changes"`
+	// Cursor is passed to the next request to receive the changes after this response
+	Cursor      string `json:"cursor"`
+	IsTruncated bool   `json:"isTruncated"`
+}
+
+type ChangeResponse struct {
+	Type      string    `json:"type"`
+	Key       string    `json:"key"`
+	VersionId string    `json:"versionId"`
+	ETag      string    `json:"etag"`
+	Size      int64     `json:"size"`
+	Time      time.Time `json:"time"`
+}
+
+// handleListChanges lists the changes of a bucket after a cursor. The request waits up to wait seconds
+// for new changes if there are none.
+func handleListChanges(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	var after int64
+	if cursor := c.Query(queryCursor); cursor != "" {
+		v, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || v < 0 {
+			return responseFromError(ec.InvalidArgument)
+		}
+		after = v
+	}
+	limit, r := c.IntQueryOrDefault("max-changes", maxChanges)
+	if r != nil {
+		return r
+	}
+	if limit > maxChanges {
+		limit = maxChanges
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	wait, r := c.IntQueryOrDefault("wait", 0)
+	if r != nil {
+		return r
+	}
+	if wait > maxChangesWait {
+		wait = maxChangesWait
+	}
+
+	deadline := time.NewTimer(time.Duration(wait) * time.Second)
+	defer deadline.Stop()
+	for {
+		// the signal is taken before listing so that changes committed in between aren't missed
+		signal := object.ChangesSignal()
+		changes, cursor, err := object.Changes(c, b.Name, after, limit)
+		if err != nil {
+			return responseFromError(err)
+		}
+		if len(changes) > 0 || wait <= 0 {
+			return srv.Respond().Json(newListChangesResponse(changes, cursor, limit))
+		}
+		select {
+		case <-signal:
+			after = cursor
+		case <-deadline.C:
+			return srv.Respond().Json(newListChangesResponse(changes, cursor, limit))
+		case <-c.Done():
+			return srv.Respond().Json(newListChangesResponse(changes, cursor, limit))
+		}
+	}
+}
+
+func newListChangesResponse(changes []*object.Change, cursor int64, limit int) ListChangesResponse {
+	res := ListChangesResponse{
+		Changes:     make([]ChangeResponse, 0, len(changes)),
+		Cursor:      strconv.FormatInt(cursor, 10),
+		IsTruncated: len(changes) == limit,
+	}
+	for _, ch := range changes {
+		res.Changes = append(res.Changes, ChangeResponse{
+			Type:      ch.Type,
+			Key:       ch.Key,
+			VersionId: ch.Version,
+			ETag:      ch.ETag,
+			Size:      ch.Size,
+			Time:      ch.CreatedAt,
+		})
+	}
+	return res
+}
@@ -6,6 +6,7 @@ package api
 
 import (
 	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
 	"github.com/cfichtmueller/stor/internal/domain/object"
 )
@@ -23,6 +24,23 @@ func contextSetBucket(c *srv.Context, b *bucket.Bucket) {
 	c.Set("bucket", b)
 }
 
+// contextGetApiKey returns the API key that authenticated the request. Requests that are authenticated with a
+// nonce don't have one.
+func contextGetApiKey(c *srv.Context) (*apikey.ApiKey, bool) {
+	v, ok := c.Get("apiKey")
+	if !ok {
+		return nil, false
+	}
+	return v.(*apikey.ApiKey), true
+}
+
+// contextGetPrincipal returns the principal that authenticated the request
+func contextGetPrincipal(c *srv.Context) string {
+	p, _ := c.Get("principal")
+	principal, _ := p.(string)
+	return principal
+}
+
 func contextGetObjectKey(c *srv.Context) (string, *srv.Response) {
 	key := c.PathValue(paramObjectKey)
 	if err := object.ValidateKey(key); err != nil {
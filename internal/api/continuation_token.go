@@ -0,0 +1,65 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+// continuationToken is the cursor of a list objects request. Clients treat it as an opaque string.
+type continuationToken struct {
+	// StartAfter is the last key or common prefix of the previous page
+	StartAfter string `json:"s"`
+	Prefix     string `json:"p,omitempty"`
+	Delimiter  string `json:"d,omitempty"`
+	// Sort and Descending are the order of the listing. The position in a listing that is not sorted by
+	// key is completed by CreatedAt or Size of the last object.
+	Sort       string     `json:"o,omitempty"`
+	Descending bool       `json:"r,omitempty"`
+	CreatedAt  *time.Time `json:"t,omitempty"`
+	Size       int64      `json:"z,omitempty"`
+}
+
+func (t continuationToken) encode() string {
+	d, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(d)
+}
+
+// decodeContinuationToken decodes a token and checks that it belongs to a listing with the same prefix, delimiter and order
+func decodeContinuationToken(s, prefix, delimiter string, sort object.Sort) (*continuationToken, error) {
+	d, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ec.InvalidContinuationToken
+	}
+	var t continuationToken
+	if err := json.Unmarshal(d, &t); err != nil {
+		return nil, ec.InvalidContinuationToken
+	}
+	if t.Prefix != prefix || t.Delimiter != delimiter || t.sort() != sort {
+		return nil, ec.InvalidContinuationToken
+	}
+	return &t, nil
+}
+
+func (t continuationToken) sort() object.Sort {
+	if t.Sort == "" {
+		return object.Sort{Field: object.SortKey, Descending: t.Descending}
+	}
+	return object.Sort{Field: t.Sort, Descending: t.Descending}
+}
+
+// cursor returns the position of the token in a sorted listing
+func (t continuationToken) cursor() *object.SortCursor {
+	c := &object.SortCursor{Key: t.StartAfter, Size: t.Size}
+	if t.CreatedAt != nil {
+		c.CreatedAt = *t.CreatedAt
+	}
+	return c
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/ec"
+	"github.com/cfichtmueller/stor/internal/uc"
+	"github.com/cfichtmueller/stor/internal/util"
+)
+
+type CopyObjectReference struct {
+	// Source is the copy source in the form [/bucket/]key[?version-id=id]
+	Source string `json:"source"`
+	Key    string `json:"key"`
+	// ContentType replaces the source's content type if set
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type CopyObjectsRequest struct {
+	Objects []CopyObjectReference `json:"objects"`
+}
+
+func (r CopyObjectsRequest) Validate() error {
+	v := srv.RequireMinLengthSlice("objects", 1, r.Objects, nil)
+	v = srv.RequireMaxLengthSlice("objects", 1000, r.Objects, v)
+	if v != nil {
+		return v
+	}
+
+	for i, o := range r.Objects {
+		v = srv.RequireNotEmptyIndexed("objects[%d].source", i, o.Source, v)
+		v = srv.RequireNotEmptyIndexed("objects[%d].key", i, o.Key, v)
+	}
+	return srv.Validate(v)
+}
+
+type CopyResults struct {
+	Results []CopyResult `json:"results"`
+}
+
+type CopyResult struct {
+	Key    string    `json:"key"`
+	Copied bool      `json:"copied"`
+	ETag   string    `json:"etag,omitempty"`
+	Error  *ec.Error `json:"error,omitempty"`
+}
+
+func handleCopyObjects(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	var req CopyObjectsRequest
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+	for _, o := range req.Objects {
+		if err := authorizeCopySource(c, b.Name, o.Source); err != nil {
+			return responseFromError(err)
+		}
+		if err := authorize(c, apikey.ActionWrite, b.Name, o.Key); err != nil {
+			return responseFromError(err)
+		}
+	}
+
+	results, err := uc.CopyObjects(c, b, util.MapMany(req.Objects, func(r CopyObjectReference) uc.CopyObjectCommand {
+		return uc.CopyObjectCommand{
+			Source:      r.Source,
+			Key:         r.Key,
+			ContentType: r.ContentType,
+		}
+	}))
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().Json(CopyResults{
+		Results: util.MapMany(results, func(r uc.CopyObjectResult) CopyResult {
+			if _, ok := r.Error.(*srv.ValidationError); ok {
+				return CopyResult{Key: r.Key, Error: ec.InvalidArgument}
+			}
+			if r.Error != nil {
+				return CopyResult{Key: r.Key, Error: ec.Wrap(r.Error)}
+			}
+			return CopyResult{Key: r.Key, Copied: true, ETag: r.Object.ETag}
+		}),
+	})
+}
@@ -8,19 +8,42 @@ import (
 	"log/slog"
 
 	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
 	"github.com/cfichtmueller/stor/internal/domain/object"
 	"github.com/cfichtmueller/stor/internal/ec"
 	"github.com/cfichtmueller/stor/internal/uc"
 	"github.com/cfichtmueller/stor/internal/util"
 )
 
+const maxDeleteObjects = 1000
+
 type DeleteObjectsRequest struct {
 	Objects []ObjectReference `json:"objects"`
+	// Filter selects the objects to delete instead of Objects. At most 1000 objects are deleted per request.
+	Filter *ObjectFilter `json:"filter,omitempty"`
+}
+
+type ObjectFilter struct {
+	Prefix string            `json:"prefix"`
+	Tags   map[string]string `json:"tags"`
+}
+
+func (f ObjectFilter) toFilter() object.Filter {
+	return object.Filter{
+		Prefix: f.Prefix,
+		Tags:   f.Tags,
+	}
 }
 
 func (r DeleteObjectsRequest) Validate() error {
+	if r.Filter != nil {
+		v := srv.RequireMaxLengthSlice("objects", 0, r.Objects, nil)
+		v = srv.Require("filter", srv.ValidationCodeInvalid, "filter must have a prefix or tags", r.Filter.Prefix != "" || len(r.Filter.Tags) > 0, v)
+		v = object.ValidateTagsField("filter.tags", r.Filter.Tags, v)
+		return srv.Validate(v)
+	}
 	v := srv.RequireMinLengthSlice("objects", 1, r.Objects, nil)
-	v = srv.RequireMaxLengthSlice("objects", 1000, r.Objects, v)
+	v = srv.RequireMaxLengthSlice("objects", maxDeleteObjects, r.Objects, v)
 	if v != nil {
 		return v
 	}
@@ -37,19 +60,36 @@ func handleDeleteObjects(c *srv.Context) *srv.Response {
 	if r := c.BindJSON(&req); r != nil {
 		return r
 	}
-	objectKeys := util.MapMany(req.Objects, func(r ObjectReference) string { return r.Key })
-	objects, err := object.FindMany(c, b.Name, objectKeys, false)
+	if req.Filter != nil {
+		if err := authorize(c, apikey.ActionDelete, b.Name, req.Filter.Prefix); err != nil {
+			return responseFromError(err)
+		}
+	}
+	for _, o := range req.Objects {
+		if err := authorize(c, apikey.ActionDelete, b.Name, o.Key); err != nil {
+			return responseFromError(err)
+		}
+	}
+	var objects []*object.Object
+	var err error
+	if req.Filter != nil {
+		objects, err = object.ListFiltered(c, b.Name, req.Filter.toFilter(), "", maxDeleteObjects)
+	} else {
+		objectKeys := util.MapMany(req.Objects, func(r ObjectReference) string { return r.Key })
+		objects, err = object.FindMany(c, b.Name, objectKeys, false)
+	}
 	if err != nil {
 		return responseFromError(ec.Wrap(err))
 	}
 	var deletedCount int64 = 0
 	var deletedSize int64 = 0
 	index := make(map[string]*DeleteResult)
+	failed := uc.DeleteObjects(c, objects)
 	for _, o := range objects {
 		res := &DeleteResult{
 			Key: o.Key,
 		}
-		if err := object.Delete(c, o); err != nil {
+		if err, ok := failed[o.Key]; ok {
 			res.Error = ec.Wrap(err)
 		} else {
 			res.Deleted = true
@@ -78,6 +118,7 @@ func handleDeleteObjects(c *srv.Context) *srv.Response {
 	}
 
 	return srv.Respond().Json(DeleteResults{
-		Results: res,
+		Results:     res,
+		IsTruncated: req.Filter != nil && len(objects) == maxDeleteObjects,
 	})
 }
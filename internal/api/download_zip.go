@@ -0,0 +1,132 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/domain/nonce"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+type DownloadZipRequest struct {
+	Keys []string `json:"keys"`
+	// Prefix downloads all objects under the prefix instead of Keys
+	Prefix      string `json:"prefix"`
+	StripPrefix bool   `json:"stripPrefix"`
+}
+
+func (r DownloadZipRequest) toCommand() archive.DownloadCommand {
+	return archive.DownloadCommand{
+		Keys:        r.Keys,
+		Prefix:      r.Prefix,
+		StripPrefix: r.StripPrefix,
+	}
+}
+
+// handleDownloadZip streams a zip archive of the requested objects. With the nonces query parameter, it
+// creates a nonce for a single download of the archive instead.
+func handleDownloadZip(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	var req DownloadZipRequest
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+	cmd := req.toCommand()
+	if err := cmd.Validate(); err != nil {
+		return responseFromError(err)
+	}
+	if err := authorizeDownloadZip(c, b.Name, cmd); err != nil {
+		return responseFromError(err)
+	}
+	if c.HasQuery(queryNonces) {
+		return createDownloadZipNonce(c, b.Name, cmd)
+	}
+	return downloadZip(c, b.Name, cmd)
+}
+
+// authorizeDownloadZip checks that the API key of a request may read the objects of a download. A nonce for the
+// download is authorized when it is created.
+func authorizeDownloadZip(c *srv.Context, bucketName string, cmd archive.DownloadCommand) error {
+	if len(cmd.Keys) == 0 {
+		return authorize(c, apikey.ActionRead, bucketName, cmd.Prefix)
+	}
+	for _, key := range cmd.Keys {
+		if err := authorize(c, apikey.ActionRead, bucketName, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createDownloadZipNonce(c *srv.Context, bucketName string, cmd archive.DownloadCommand) *srv.Response {
+	if !c.HasQuery("ttl") {
+		return responseFromError(ec.InvalidArgument)
+	}
+	ttl, r := c.IntQuery("ttl")
+	if r != nil {
+		return r
+	}
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		return responseFromError(err)
+	}
+	n, err := nonce.Create(c, bucketName, "", nonce.CreateCommand{
+		TTL:     time.Duration(ttl) * time.Second,
+		Payload: string(payload),
+	})
+	if err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().Created(NonceResponse{
+		Nonce:     n.ID,
+		ExpiresAt: n.ExpiresAt,
+	})
+}
+
+// handleGetZipDownload streams the zip archive a nonce has been created for. The nonce can only be used once.
+func handleGetZipDownload(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	id := c.Query("nonce")
+	if id == "" {
+		return responseFromError(ec.InvalidArgument)
+	}
+	n, err := nonce.GetAndInvalidate(c, id)
+	if err != nil {
+		if errors.Is(err, nonce.ErrNotFound) {
+			return responseFromError(ec.Unauthorized)
+		}
+		return responseFromError(err)
+	}
+	if n.Bucket != b.Name || n.Key != "" || n.Payload == "" {
+		return responseFromError(ec.Unauthorized)
+	}
+	var cmd archive.DownloadCommand
+	if err := json.Unmarshal([]byte(n.Payload), &cmd); err != nil {
+		return responseFromError(fmt.Errorf("unable to decode nonce payload: %w", err))
+	}
+	return downloadZip(c, b.Name, cmd)
+}
+
+func downloadZip(c *srv.Context, bucketName string, cmd archive.DownloadCommand) *srv.Response {
+	d, err := archive.NewDownload(c, bucketName, cmd)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().
+		ContentLength(d.Size()).
+		Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": d.Name})).
+		BodyFn("application/zip", func(w io.Writer) error {
+			return d.Write(c, w)
+		})
+}
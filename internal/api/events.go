@@ -0,0 +1,196 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/bus"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+const (
+	// eventsBufferSize is the number of bus events buffered for a stream before they are dropped
+	eventsBufferSize = 64
+	// eventsKeepAlive is the time after which an idle stream sends a comment to keep connections open
+	eventsKeepAlive = 15 * time.Second
+)
+
+type ArchiveEventResponse struct {
+	Key       string    `json:"key"`
+	ArchiveId string    `json:"archiveId"`
+	Time      time.Time `json:"time"`
+}
+
+// handleStreamEvents streams the activity of a bucket as server-sent events. Object events are read from the
+// change journal and carry its seq as id, so that clients can resume with Last-Event-ID. Archive events are
+// sent as they happen and are not replayed.
+func handleStreamEvents(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	prefixes := c.Request().URL.Query()["prefix"]
+
+	var cursor int64
+	if id := c.Header("Last-Event-ID"); id != "" {
+		v, err := strconv.ParseInt(id, 10, 64)
+		if err != nil || v < 0 {
+			return responseFromError(ec.InvalidArgument)
+		}
+		cursor = v
+	} else {
+		head, err := object.ChangesHead(c)
+		if err != nil {
+			return responseFromError(err)
+		}
+		cursor = head
+	}
+
+	// the listener is subscribed before the journal is read so that no archive events are missed
+	l := bus.Listen(archive.EventCompleted, eventsBufferSize)
+	signal := object.ChangesSignal()
+	changes, cursor, err := object.Changes(c, b.Name, cursor, maxChanges)
+	if err != nil {
+		l.Close()
+		return responseFromError(err)
+	}
+
+	return srv.Respond().
+		CacheControl("no-cache").
+		Header("X-Accel-Buffering", "no").
+		BodyFn("text/event-stream", func(w io.Writer) error {
+			defer l.Close()
+			s := &eventStream{w: w, prefixes: prefixes}
+			if f, ok := w.(http.Flusher); ok {
+				s.flusher = f
+			}
+			keepAlive := time.NewTicker(eventsKeepAlive)
+			defer keepAlive.Stop()
+
+			// an empty comment sends the headers right away
+			if err := s.comment(""); err != nil {
+				return nil
+			}
+			for {
+				for _, ch := range changes {
+					if err := s.change(ch); err != nil {
+						return nil
+					}
+				}
+				if len(changes) < maxChanges {
+					s.flush()
+					select {
+					case <-signal:
+					case e := <-l.C:
+						if e.Bucket == b.Name {
+							if err := s.archiveCompleted(e); err != nil {
+								return nil
+							}
+						}
+						changes = nil
+						continue
+					case <-keepAlive.C:
+						if err := s.comment("keep-alive"); err != nil {
+							return nil
+						}
+						changes = nil
+						continue
+					case <-c.Done():
+						return nil
+					}
+				}
+				signal = object.ChangesSignal()
+				changes, cursor, err = object.Changes(c, b.Name, cursor, maxChanges)
+				if err != nil {
+					// the stream is ended so that the client resumes from its last event
+					return nil
+				}
+			}
+		})
+}
+
+type eventStream struct {
+	w        io.Writer
+	flusher  http.Flusher
+	prefixes []string
+}
+
+func (s *eventStream) change(ch *object.Change) error {
+	if !s.matches(ch.Key) {
+		return nil
+	}
+	return s.event(strconv.FormatInt(ch.Seq, 10), "object."+ch.Type, ChangeResponse{
+		Type:      ch.Type,
+		Key:       ch.Key,
+		VersionId: ch.Version,
+		ETag:      ch.ETag,
+		Size:      ch.Size,
+		Time:      ch.CreatedAt,
+	})
+}
+
+func (s *eventStream) archiveCompleted(e archive.CompletedEvent) error {
+	if !s.matches(e.Key) {
+		return nil
+	}
+	if err := s.event("", archive.EventCompleted.Name(), ArchiveEventResponse{
+		Key:       e.Key,
+		ArchiveId: e.ArchiveId,
+		Time:      domain.TimeNow(),
+	}); err != nil {
+		return err
+	}
+	s.flush()
+	return nil
+}
+
+func (s *eventStream) matches(key string) bool {
+	if len(s.prefixes) == 0 {
+		return true
+	}
+	for _, p := range s.prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// event writes an event. Events without id don't move the Last-Event-ID of the client.
+func (s *eventStream) event(id, name string, data any) error {
+	d, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	if id != "" {
+		fmt.Fprintf(&sb, "id: %s\n", id)
+	}
+	fmt.Fprintf(&sb, "event: %s\ndata: %s\n\n", name, d)
+	_, err = io.WriteString(s.w, sb.String())
+	return err
+}
+
+func (s *eventStream) comment(text string) error {
+	if _, err := fmt.Fprintf(s.w, ": %s\n\n", text); err != nil {
+		return err
+	}
+	s.flush()
+	return nil
+}
+
+func (s *eventStream) flush() {
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/uc"
+)
+
+const extractResultsPageSize = 1000
+
+type ExtractArchiveRequest struct {
+	// Prefix is prepended to the names of the files
+	Prefix string `json:"prefix"`
+	// Overwrite is one of skip, replace or fail. Existing objects are skipped by default.
+	Overwrite string `json:"overwrite"`
+	// Type is detected from the key and content type of the archive if empty
+	Type string `json:"type"`
+}
+
+type ExtractResultsResponse struct {
+	Files      map[string]int64        `json:"files"`
+	Bytes      int64                   `json:"bytes"`
+	Results    []ExtractResultResponse `json:"results"`
+	NextCursor int                     `json:"nextCursor,omitempty"`
+}
+
+type ExtractResultResponse struct {
+	Seq    int    `json:"seq"`
+	Name   string `json:"name"`
+	Key    string `json:"key,omitempty"`
+	Result string `json:"result"`
+	Size   int64  `json:"size"`
+	Error  string `json:"error,omitempty"`
+}
+
+func handleExtractArchive(c *srv.Context) *srv.Response {
+	o, r := objectFilter(c)
+	if r != nil {
+		return r
+	}
+	var req ExtractArchiveRequest
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+	if err := authorize(c, apikey.ActionWrite, o.Bucket, req.Prefix); err != nil {
+		return responseFromError(err)
+	}
+
+	j, err := uc.ExtractArchive(c, o, uc.ExtractArchiveCommand{
+		Type:      req.Type,
+		Prefix:    req.Prefix,
+		Overwrite: req.Overwrite,
+	})
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().Status(http.StatusAccepted).Json(newJobResponse(j))
+}
+
+func handleListExtractResults(c *srv.Context) *srv.Response {
+	j, err := findBucketJob(c)
+	if err != nil {
+		return responseFromError(err)
+	}
+	cursor, r := c.IntQueryOrDefault(queryCursor, 0)
+	if r != nil {
+		return r
+	}
+	summary, err := archive.Summarize(c, j.ID)
+	if err != nil {
+		return responseFromError(err)
+	}
+	results, err := archive.Results(c, j.ID, cursor, extractResultsPageSize)
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	res := ExtractResultsResponse{
+		Files:   summary.Files,
+		Bytes:   summary.Bytes,
+		Results: make([]ExtractResultResponse, 0, len(results)),
+	}
+	for _, r := range results {
+		res.Results = append(res.Results, ExtractResultResponse{
+			Seq:    r.Seq,
+			Name:   r.Name,
+			Key:    r.Key,
+			Result: r.Result,
+			Size:   r.Size,
+			Error:  r.Error,
+		})
+	}
+	if len(results) == extractResultsPageSize {
+		res.NextCursor = results[len(results)-1].Seq
+	}
+	return srv.Respond().Json(res)
+}
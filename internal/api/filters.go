@@ -7,7 +7,6 @@ package api
 import (
 	"errors"
 	"strings"
-	"time"
 
 	"github.com/cfichtmueller/srv"
 	"github.com/cfichtmueller/stor/internal/domain/apikey"
@@ -16,12 +15,6 @@ import (
 	"github.com/cfichtmueller/stor/internal/domain/nonce"
 	"github.com/cfichtmueller/stor/internal/domain/object"
 	"github.com/cfichtmueller/stor/internal/ec"
-	"github.com/cfichtmueller/stor/internal/util"
-)
-
-var (
-	tokenCache = util.NewCache()
-	tokenTTL   = time.Minute
 )
 
 func bucketFilter(c *srv.Context, next srv.Handler) *srv.Response {
@@ -93,22 +86,30 @@ func authenticatedFilter(c *srv.Context, next srv.Handler) *srv.Response {
 	return next(c)
 }
 
+// authenticatedOrZipNonceFilter lets zip downloads with a nonce through without an API key.
+// handleGetZipDownload authenticates the nonce.
+func authenticatedOrZipNonceFilter(c *srv.Context, next srv.Handler) *srv.Response {
+	if c.Header("Authorization") == "" && c.Query("nonce") != "" && c.HasQuery(queryDownloadZip) {
+		return next(c)
+	}
+	return authenticatedFilter(c, next)
+}
+
+// authenticateApiKey authenticates the API key of a request and checks that its policy grants the request
 func authenticateApiKey(c *srv.Context) (string, bool, error) {
 	auth := c.Header("Authorization")
 	if !strings.HasPrefix(auth, "Bearer ") {
 		return "", false, nil
 	}
-	token := auth[7:]
-	if p, ok := tokenCache.Get(token); ok {
-		return p.(string), true, nil
-	}
-	key, err := apikey.Authenticate(c, token)
+	key, err := apikey.Authenticate(c, auth[7:])
 	if err != nil {
 		return "", false, err
 	}
-	principal := "apikey:" + key.ID
-	tokenCache.SetTTL(token, principal, tokenTTL)
-	return principal, true, nil
+	if err := authorizeRequest(c, key.Policy); err != nil {
+		return "", false, err
+	}
+	c.Set("apiKey", key)
+	return "apikey:" + key.ID, true, nil
 }
 
 func mustAuthenticateApiKey(c *srv.Context) *srv.Response {
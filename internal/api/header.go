@@ -0,0 +1,21 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+var (
+	headerBypassGovernanceRetention = "Stor-Bypass-Governance-Retention"
+	headerCopySource                = "Stor-Copy-Source"
+	headerExpiresAt                 = "X-Stor-Expires-At"
+	headerMetadataDirective         = "Stor-Metadata-Directive"
+	headerObjectLockLegalHold       = "Stor-Object-Lock-Legal-Hold"
+	headerObjectLockMode            = "Stor-Object-Lock-Mode"
+	headerObjectLockRetainUntilDate = "Stor-Object-Lock-Retain-Until-Date"
+	headerTagging                   = "Stor-Tagging"
+	headerTTL                       = "X-Stor-Ttl"
+	headerVersionId                 = "Stor-Version-Id"
+
+	metadataDirectiveCopy    = "COPY"
+	metadataDirectiveReplace = "REPLACE"
+)
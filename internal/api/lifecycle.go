@@ -0,0 +1,85 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+)
+
+type LifecycleConfiguration struct {
+	Rules []lifecycle.RuleCommand `json:"rules"`
+}
+
+func (r LifecycleConfiguration) Validate() error {
+	return lifecycle.ValidateRules(r.Rules)
+}
+
+type LifecycleConfigurationResponse struct {
+	Rules []LifecycleRuleResponse `json:"rules"`
+}
+
+type LifecycleRuleResponse struct {
+	ID                  string            `json:"id"`
+	Prefix              string            `json:"prefix"`
+	Tags                map[string]string `json:"tags,omitempty"`
+	ExpirationDays      int               `json:"expirationDays"`
+	KeepVersions        int               `json:"keepVersions"`
+	AbortIncompleteDays int               `json:"abortIncompleteDays"`
+	CreatedAt           time.Time         `json:"createdAt"`
+}
+
+func newLifecycleConfigurationResponse(rules []*lifecycle.Rule) LifecycleConfigurationResponse {
+	res := make([]LifecycleRuleResponse, 0, len(rules))
+	for _, r := range rules {
+		res = append(res, LifecycleRuleResponse{
+			ID:                  r.ID,
+			Prefix:              r.Prefix,
+			Tags:                r.Tags,
+			ExpirationDays:      r.ExpirationDays,
+			KeepVersions:        r.KeepVersions,
+			AbortIncompleteDays: r.AbortIncompleteDays,
+			CreatedAt:           r.CreatedAt,
+		})
+	}
+	return LifecycleConfigurationResponse{Rules: res}
+}
+
+func handleGetLifecycle(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	rules, err := lifecycle.List(c, b.Name)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().Json(newLifecycleConfigurationResponse(rules))
+}
+
+func handlePutLifecycle(c *srv.Context) *srv.Response {
+	b, r := mustGetBucket(c)
+	if r != nil {
+		return r
+	}
+	var req LifecycleConfiguration
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+
+	rules, err := lifecycle.Replace(c, b.Name, req.Rules)
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().Json(newLifecycleConfigurationResponse(rules))
+}
+
+func handleDeleteLifecycle(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	if err := lifecycle.DeleteAll(c, b.Name); err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().NoContent()
+}
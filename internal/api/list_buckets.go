@@ -2,7 +2,9 @@ package api
 
 import (
 	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/ec"
 	"github.com/cfichtmueller/stor/internal/util"
 )
 
@@ -12,23 +14,50 @@ type ListBucketResponse struct {
 }
 
 func handleListBuckets(c *srv.Context) *srv.Response {
+	if c.HasQuery(querySearch) {
+		return handleSearchObjects(c)
+	}
 	startAfter := c.Query("start-after")
 	maxBuckets, r := c.IntQueryOrDefault("max-buckets", 1000)
 	if r != nil {
 		return r
 	}
 
-	buckets, err := bucket.List(c, startAfter, maxBuckets)
-	if err != nil {
-		return responseFromError(err)
-	}
-	count, err := bucket.Count(c, startAfter)
+	buckets, truncated, err := listAllowedBuckets(c, startAfter, maxBuckets)
 	if err != nil {
 		return responseFromError(err)
 	}
 
 	return srv.Respond().Json(ListBucketResponse{
 		Buckets:     util.MapMany(buckets, newBucketResponse),
-		IsTruncated: count > len(buckets),
+		IsTruncated: truncated,
 	})
 }
+
+// listAllowedBuckets lists up to limit buckets the API key of the request may list objects of
+func listAllowedBuckets(c *srv.Context, startAfter string, limit int) ([]*bucket.Bucket, bool, error) {
+	k, ok := contextGetApiKey(c)
+	if !ok {
+		return nil, false, ec.AccessDenied
+	}
+	allowed := make([]*bucket.Bucket, 0)
+	for {
+		buckets, err := bucket.List(c, startAfter, limit)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, b := range buckets {
+			if !k.Policy.AllowsObjects(apikey.ActionList, b.Name) {
+				continue
+			}
+			if len(allowed) == limit {
+				return allowed, true, nil
+			}
+			allowed = append(allowed, b)
+		}
+		if len(buckets) == 0 || len(buckets) < limit {
+			return allowed, false, nil
+		}
+		startAfter = buckets[len(buckets)-1].Name
+	}
+}
@@ -5,20 +5,39 @@
 package api
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/cfichtmueller/srv"
 	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
 	"github.com/cfichtmueller/stor/internal/uc"
-	"github.com/cfichtmueller/stor/internal/util"
 )
 
+var includeMetadata = "metadata"
+
 type ListObjectsResponse struct {
-	IsTruncated    bool             `json:"isTruncated"`
-	Objects        []ObjectResponse `json:"objects"`
-	Name           string           `json:"name"`
-	MaxKeys        int              `json:"maxKeys"`
-	KeyCount       int              `json:"keyCount"`
-	StartAfter     *string          `json:"startAfter,omitempty"`
-	CommonPrefixes []string         `json:"commonPrefixes,omitempty"`
+	IsTruncated           bool             `json:"isTruncated"`
+	Objects               []ObjectResponse `json:"objects"`
+	Name                  string           `json:"name"`
+	MaxKeys               int              `json:"maxKeys"`
+	KeyCount              int              `json:"keyCount"`
+	StartAfter            *string          `json:"startAfter,omitempty"`
+	ContinuationToken     string           `json:"continuationToken,omitempty"`
+	NextContinuationToken string           `json:"nextContinuationToken,omitempty"`
+	NextMarker            string           `json:"nextMarker,omitempty"`
+	CommonPrefixes        []string         `json:"commonPrefixes,omitempty"`
+}
+
+// ObjectMetadataResponse is added to the objects of a listing with include=metadata
+type ObjectMetadataResponse struct {
+	VersionId       string            `json:"versionId"`
+	ExpiresAt       *time.Time        `json:"expiresAt,omitempty"`
+	RetentionMode   string            `json:"retentionMode,omitempty"`
+	RetainUntilDate *time.Time        `json:"retainUntilDate,omitempty"`
+	LegalHold       bool              `json:"legalHold"`
+	Tags            map[string]string `json:"tags,omitempty"`
 }
 
 func handleListObjects(c *srv.Context) *srv.Response {
@@ -30,47 +49,223 @@ func handleListObjects(c *srv.Context) *srv.Response {
 	if maxKeys > 1000 {
 		maxKeys = 1000
 	}
+	if maxKeys < 0 {
+		maxKeys = 0
+	}
 	delimiter := c.Query("delimiter")
-	prefix := c.Query("prefix")
+	filter, err := listFilter(c)
+	if err != nil {
+		return responseFromError(err)
+	}
+	sort, err := listSort(c)
+	if err != nil {
+		return responseFromError(err)
+	}
+	if sort.Field != object.SortKey && startAfter != "" {
+		return responseFromError(ec.InvalidArgument)
+	}
+	// delimited listings are always in ascending key order
+	if delimiter != "" && (sort.Field != object.SortKey || sort.Descending) {
+		return responseFromError(ec.InvalidArgument)
+	}
+	var cursor *object.SortCursor
+	if startAfter != "" {
+		cursor = &object.SortCursor{Key: startAfter}
+	}
+	token := c.Query(queryContinuationToken)
+	if token != "" {
+		t, err := decodeContinuationToken(token, filter.Prefix, delimiter, sort)
+		if err != nil {
+			return responseFromError(err)
+		}
+		startAfter = t.StartAfter
+		cursor = t.cursor()
+	}
+	withMetadata, err := parseInclude(c.Query(queryInclude))
+	if err != nil {
+		return responseFromError(err)
+	}
 	b := contextGetBucket(c)
 
-	if delimiter != "" {
-		r, err := uc.ObjectPrefixSearch(c, b, delimiter, prefix, startAfter, maxKeys)
+	var objects []*object.Object
+	var commonPrefixes []string
+	var isTruncated bool
+	var nextMarker string
+	switch {
+	case maxKeys == 0:
+		// an empty page has no key to continue after, so it is never reported as truncated
+	case delimiter != "":
+		r, err := uc.ObjectPrefixSearch(c, b, delimiter, filter, startAfter, maxKeys)
 		if err != nil {
 			return responseFromError(err)
 		}
+		objects, commonPrefixes, isTruncated, nextMarker = r.Objects, r.CommonPrefixes, r.IsTruncated, r.NextMarker
+	default:
+		// one more object than requested is fetched to find out whether the listing is truncated
+		objects, err = object.ListSorted(c, b.Name, filter, sort, cursor, maxKeys+1)
+		if err != nil {
+			return responseFromError(err)
+		}
+		if len(objects) > maxKeys {
+			objects = objects[:maxKeys]
+			isTruncated = true
+		}
+		if isTruncated && len(objects) > 0 {
+			nextMarker = objects[len(objects)-1].Key
+		}
+	}
 
-		return srv.Respond().Json(ListObjectsResponse{
-			IsTruncated:    r.IsTruncated,
-			Objects:        util.MapMany(r.Objects, newObjectResponse),
-			Name:           b.Name,
-			MaxKeys:        maxKeys,
-			KeyCount:       len(r.Objects),
-			StartAfter:     &startAfter,
-			CommonPrefixes: r.CommonPrefixes,
-		})
+	res := ListObjectsResponse{
+		IsTruncated:       isTruncated,
+		Objects:           make([]ObjectResponse, 0, len(objects)),
+		Name:              b.Name,
+		MaxKeys:           maxKeys,
+		KeyCount:          len(objects),
+		ContinuationToken: token,
+		CommonPrefixes:    commonPrefixes,
+	}
+	if startAfter != "" && token == "" {
+		res.StartAfter = &startAfter
+	}
+	if nextMarker != "" {
+		next := continuationToken{
+			StartAfter: nextMarker,
+			Prefix:     filter.Prefix,
+			Delimiter:  delimiter,
+			Descending: sort.Descending,
+		}
+		if sort.Field == object.SortKey {
+			// markers are only meaningful for listings in key order
+			res.NextMarker = nextMarker
+		} else {
+			last := objects[len(objects)-1]
+			next.Sort = sort.Field
+			next.CreatedAt = &last.CreatedAt
+			next.Size = last.Size
+		}
+		res.NextContinuationToken = next.encode()
 	}
 
-	contents, err := object.List(c, b.Name, startAfter, maxKeys)
+	var objectTags map[string]map[string]string
+	if withMetadata {
+		objectTags, err = object.TagsMany(c, objects)
+		if err != nil {
+			return responseFromError(err)
+		}
+	}
+	for _, o := range objects {
+		or := newObjectResponse(o)
+		if withMetadata {
+			or.Metadata = newObjectMetadataResponse(o, objectTags[o.ID])
+		}
+		res.Objects = append(res.Objects, or)
+	}
+
+	return srv.Respond().Json(res)
+}
+
+// listFilter parses the filters of a list objects request
+func listFilter(c *srv.Context) (object.Filter, error) {
+	f := object.Filter{
+		Prefix:      c.Query("prefix"),
+		ContentType: c.Query("content-type"),
+	}
+	tags, err := tagFilter(c)
 	if err != nil {
-		return responseFromError(err)
+		return f, err
+	}
+	f.Tags = tags
+	if f.CreatedAfter, err = timeQuery(c, "modified-after"); err != nil {
+		return f, err
+	}
+	if f.CreatedBefore, err = timeQuery(c, "modified-before"); err != nil {
+		return f, err
+	}
+	if f.MinSize, err = sizeQuery(c, "min-size"); err != nil {
+		return f, err
+	}
+	if f.MaxSize, err = sizeQuery(c, "max-size"); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// listSort parses the order of a list objects request. Objects are sorted by key in ascending order by default.
+func listSort(c *srv.Context) (object.Sort, error) {
+	s := object.Sort{Field: object.SortKey}
+	switch c.Query("sort-by") {
+	case "", "key":
+	case "last-modified":
+		s.Field = object.SortCreatedAt
+	case "size":
+		s.Field = object.SortSize
+	default:
+		return s, ec.InvalidArgument
+	}
+	switch c.Query("order") {
+	case "", "asc":
+	case "desc":
+		s.Descending = true
+	default:
+		return s, ec.InvalidArgument
 	}
-	keyCount := len(contents)
-	totalKeys, err := object.Count(c, b.Name, startAfter)
+	return s, nil
+}
+
+// timeQuery parses an optional RFC 3339 query parameter
+func timeQuery(c *srv.Context, name string) (*time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
 	if err != nil {
-		return responseFromError(err)
+		return nil, ec.InvalidArgument
 	}
-	var startAfterRes *string
-	if startAfter != "" {
-		startAfterRes = &startAfter
+	t = t.UTC()
+	return &t, nil
+}
+
+// sizeQuery parses an optional non-negative size query parameter
+func sizeQuery(c *srv.Context, name string) (*int64, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return nil, ec.InvalidArgument
 	}
+	return &n, nil
+}
+
+// parseInclude parses the expansions of a list objects request. Returns true if metadata is requested.
+func parseInclude(s string) (bool, error) {
+	if s == "" {
+		return false, nil
+	}
+	metadata := false
+	for _, v := range strings.Split(s, ",") {
+		switch strings.TrimSpace(v) {
+		case includeMetadata:
+			metadata = true
+		default:
+			return false, ec.InvalidArgument
+		}
+	}
+	return metadata, nil
+}
 
-	return srv.Respond().Json(ListObjectsResponse{
-		IsTruncated: totalKeys > keyCount,
-		Objects:     util.MapMany(contents, newObjectResponse),
-		Name:        b.Name,
-		MaxKeys:     maxKeys,
-		KeyCount:    keyCount,
-		StartAfter:  startAfterRes,
-	})
+func newObjectMetadataResponse(o *object.Object, tags map[string]string) *ObjectMetadataResponse {
+	r := &ObjectMetadataResponse{
+		VersionId: o.CurrentVersion,
+		ExpiresAt: o.ExpiresAt,
+		LegalHold: o.LegalHold,
+		Tags:      tags,
+	}
+	if o.Retention != nil {
+		r.RetentionMode = o.Retention.Mode
+		r.RetainUntilDate = &o.Retention.RetainUntil
+	}
+	return r
 }
@@ -15,11 +15,11 @@ func Configure() *srv.Server {
 
 	server.GET("", handleListBuckets, authenticatedFilter)
 
-	bucketGroup := server.Group("/{bucketName}", authenticatedFilter)
-	bucketGroup.POST("", handleBucketPost, bucketFilter)
-	bucketGroup.PUT("", handleCreateBucket)
-	bucketGroup.GET("", handleListObjects, bucketFilter)
-	bucketGroup.DELETE("", handleDeleteBucket, bucketFilter)
+	bucketGroup := server.Group("/{bucketName}")
+	bucketGroup.POST("", handleBucketPost, authenticatedFilter, bucketFilter)
+	bucketGroup.PUT("", handleBucketPut, authenticatedFilter)
+	bucketGroup.GET("", handleBucketGet, authenticatedOrZipNonceFilter, bucketFilter)
+	bucketGroup.DELETE("", handleBucketDelete, authenticatedFilter, bucketFilter)
 
 	objectGroup := server.Group("/{bucketName}/{objectKey...}")
 	objectGroup.HEAD("", handleObjectHead)
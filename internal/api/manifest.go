@@ -0,0 +1,150 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/chunk"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+	"github.com/cfichtmueller/stor/internal/uc"
+	"github.com/cfichtmueller/stor/internal/util"
+)
+
+type ChunkResponse struct {
+	ID   string `json:"id"`
+	Size int64  `json:"size"`
+}
+
+type ObjectChunksResponse struct {
+	Key    string          `json:"key"`
+	ETag   string          `json:"etag"`
+	Size   int64           `json:"size"`
+	Chunks []ChunkResponse `json:"chunks"`
+}
+
+func handleGetObjectChunks(c *srv.Context) *srv.Response {
+	if r := mustAuthenticateApiKey(c); r != nil {
+		return r
+	}
+	b, r := mustGetBucket(c)
+	if r != nil {
+		return r
+	}
+	o, r := mustGetObject(c, b)
+	if r != nil {
+		return r
+	}
+
+	chunks, err := object.Chunks(c, o)
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().ETag(o.ETag).Json(ObjectChunksResponse{
+		Key:  o.Key,
+		ETag: o.ETag,
+		Size: o.Size,
+		Chunks: util.MapMany(chunks, func(ref object.ChunkRef) ChunkResponse {
+			return ChunkResponse{ID: ref.ID, Size: ref.Size}
+		}),
+	})
+}
+
+func handleUploadChunk(c *srv.Context) *srv.Response {
+	id := c.Query(queryChunk)
+	if !chunk.IsValidId(id) {
+		return responseFromError(ec.InvalidChunk)
+	}
+	d, err := c.GetRawData()
+	if err != nil {
+		if errors.Is(err, srv.ErrNoBody) {
+			return srv.Respond().BadRequest(srv.ErrorDto{
+				Code:    "request_body_missing",
+				Message: "Request body is missing",
+			})
+		}
+		return responseFromError(err)
+	}
+
+	if err := chunk.Stage(c, id, d); err != nil {
+		if errors.Is(err, chunk.ErrHashMismatch) {
+			return responseFromError(ec.BadDigest)
+		}
+		return responseFromError(err)
+	}
+
+	return srv.Respond().NoContent()
+}
+
+type CommitManifestRequest struct {
+	ContentType string   `json:"contentType"`
+	Chunks      []string `json:"chunks"`
+}
+
+func (r CommitManifestRequest) Validate() error {
+	v := srv.RequireMinLengthSlice("chunks", 1, r.Chunks, nil)
+	v = srv.RequireMaxLengthSlice("chunks", 10000, r.Chunks, v)
+	return srv.Validate(v)
+}
+
+type CommitManifestResult struct {
+	Bucket  string   `json:"bucket"`
+	Key     string   `json:"key"`
+	ETag    string   `json:"etag,omitempty"`
+	Missing []string `json:"missing,omitempty"`
+}
+
+func handleCommitManifest(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	key, r := contextGetObjectKey(c)
+	if r != nil {
+		return r
+	}
+	var req CommitManifestRequest
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	expiresAt, err := object.ParseExpiration(c.Header(headerExpiresAt), c.Header(headerTTL))
+	if err != nil {
+		return responseFromError(err)
+	}
+	tags, err := writeTags(c)
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	res, err := uc.CommitObjectManifest(c, b, uc.CommitObjectManifestCommand{
+		Key:         key,
+		ContentType: contentType,
+		Chunks:      req.Chunks,
+		ExpiresAt:   expiresAt,
+		Tags:        tags,
+	})
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	if len(res.Missing) > 0 {
+		return srv.Respond().Status(http.StatusConflict).Json(CommitManifestResult{
+			Bucket:  b.Name,
+			Key:     key,
+			Missing: res.Missing,
+		})
+	}
+
+	return srv.Respond().ETag(res.Object.ETag).Json(CommitManifestResult{
+		Bucket: b.Name,
+		Key:    key,
+		ETag:   res.Object.ETag,
+	})
+}
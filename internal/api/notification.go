@@ -0,0 +1,85 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/notification"
+)
+
+type NotificationConfiguration struct {
+	Webhooks []notification.WebhookCommand `json:"webhooks"`
+}
+
+func (r NotificationConfiguration) Validate() error {
+	return notification.ValidateWebhooks(r.Webhooks)
+}
+
+type NotificationConfigurationResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Prefix    string    `json:"prefix"`
+	Suffix    string    `json:"suffix"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newNotificationConfigurationResponse(webhooks []*notification.Webhook) NotificationConfigurationResponse {
+	res := make([]WebhookResponse, 0, len(webhooks))
+	for _, w := range webhooks {
+		res = append(res, WebhookResponse{
+			ID:        w.ID,
+			URL:       w.URL,
+			Events:    w.Events,
+			Prefix:    w.Prefix,
+			Suffix:    w.Suffix,
+			Secret:    w.Secret,
+			CreatedAt: w.CreatedAt,
+		})
+	}
+	return NotificationConfigurationResponse{Webhooks: res}
+}
+
+func handleGetNotification(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	webhooks, err := notification.List(c, b.Name)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().Json(newNotificationConfigurationResponse(webhooks))
+}
+
+func handlePutNotification(c *srv.Context) *srv.Response {
+	b, r := mustGetBucket(c)
+	if r != nil {
+		return r
+	}
+	var req NotificationConfiguration
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+
+	webhooks, err := notification.Replace(c, b.Name, req.Webhooks)
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().Json(newNotificationConfigurationResponse(webhooks))
+}
+
+func handleDeleteNotification(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	if err := notification.DeleteAll(c, b.Name); err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().NoContent()
+}
@@ -12,15 +12,17 @@ import (
 	"github.com/cfichtmueller/srv"
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
 	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
 	"github.com/cfichtmueller/stor/internal/uc"
 )
 
 type ObjectResponse struct {
-	Key         string    `json:"key"`
-	ContentType string    `json:"contentType"`
-	ETag        string    `json:"etag"`
-	Size        int64     `json:"size"`
-	CreatedAt   time.Time `json:"createdAt"`
+	Key         string                  `json:"key"`
+	ContentType string                  `json:"contentType"`
+	ETag        string                  `json:"etag"`
+	Size        int64                   `json:"size"`
+	CreatedAt   time.Time               `json:"createdAt"`
+	Metadata    *ObjectMetadataResponse `json:"metadata,omitempty"`
 }
 
 func newObjectResponse(o *object.Object) ObjectResponse {
@@ -63,17 +65,26 @@ func handleObjectHead(c *srv.Context) *srv.Response {
 	if r := c.ConditionalIfUnmodifiedSince(o.CreatedAt); r != nil {
 		return r
 	}
-	return srv.Respond().
+	return withObjectHeaders(srv.Respond().
 		ContentLength(int64(o.Size)).
 		ContentType(o.ContentType).
 		LastModified(o.CreatedAt).
-		ETag(o.ETag)
+		ETag(o.ETag).
+		Header(headerVersionId, o.CurrentVersion), o)
 }
 
 func handleObjectGet(c *srv.Context) *srv.Response {
 	query := c.Request().URL.Query()
 	if query.Has(queryArchiveId) {
 		return handleGetArchive(c)
+	} else if query.Has(queryChunks) {
+		return handleGetObjectChunks(c)
+	} else if query.Has(queryRetention) {
+		return handleGetObjectRetention(c)
+	} else if query.Has(queryLegalHold) {
+		return handleGetObjectLegalHold(c)
+	} else if query.Has(queryTagging) {
+		return handleGetObjectTagging(c)
 	}
 	return handleGetObject(c)
 }
@@ -108,11 +119,12 @@ func handleGetObject(c *srv.Context) *srv.Response {
 	if r := c.ConditionalIfUnmodifiedSince(o.CreatedAt); r != nil {
 		return r
 	}
-	return srv.Respond().
+	return withObjectHeaders(srv.Respond().
 		ContentLength(int64(o.Size)).
 		ContentType(o.ContentType).
 		LastModified(o.CreatedAt).
 		ETag(o.ETag).
+		Header(headerVersionId, o.CurrentVersion), o).
 		BodyFn(o.ContentType, func(w io.Writer) error {
 			return object.Write(c, o, w)
 		})
@@ -130,6 +142,10 @@ func handleObjectPost(c *srv.Context) *srv.Response {
 
 	if c.HasQuery(queryArchives) {
 		return handleCreateArchive(c)
+	} else if c.Query(queryArchiveId) != "" && c.HasQuery(queryRetry) {
+		return handleRetryArchive(c)
+	} else if c.Query(queryArchiveId) != "" && c.HasQuery(queryCancel) {
+		return handleCancelArchive(c)
 	} else if c.Query(queryArchiveId) != "" {
 		return handleCompleteArchive(c)
 	} else if c.HasQuery(queryNonces) {
@@ -138,6 +154,12 @@ func handleObjectPost(c *srv.Context) *srv.Response {
 		return handleCreateMultipartUpload(c)
 	} else if c.Query(queryUploadId) != "" {
 		return handleCompleteMultipartUpload(c)
+	} else if c.HasQuery(queryManifest) {
+		return handleCommitManifest(c)
+	} else if c.HasQuery(queryRename) {
+		return handleRenameObject(c)
+	} else if c.HasQuery(queryExtract) {
+		return handleExtractArchive(c)
 	}
 	return srv.Respond().MethodNotAllowed()
 }
@@ -147,6 +169,14 @@ func handleObjectPut(c *srv.Context) *srv.Response {
 		return handleAddArchiveEntries(c)
 	} else if c.Query(queryUploadId) != "" {
 		return handleUploadPart(c)
+	} else if c.HasQuery(queryChunk) {
+		return handleUploadChunk(c)
+	} else if c.HasQuery(queryRetention) {
+		return handlePutObjectRetention(c)
+	} else if c.HasQuery(queryLegalHold) {
+		return handlePutObjectLegalHold(c)
+	} else if c.HasQuery(queryTagging) {
+		return handlePutObjectTagging(c)
 	}
 	return handleCreateOrUpdateObject(c)
 }
@@ -156,6 +186,8 @@ func handleObjectDelete(c *srv.Context) *srv.Response {
 		return handleAbortArchive(c)
 	} else if c.Query(queryUploadId) != "" {
 		return handleAbortMultipartUpload(c)
+	} else if c.HasQuery(queryTagging) {
+		return handleDeleteObjectTagging(c)
 	}
 	return handleDeleteObject(c)
 }
@@ -166,7 +198,7 @@ func handleCreateOrUpdateObject(c *srv.Context) *srv.Response {
 	if r != nil {
 		return r
 	}
-	copySource := c.Header("Stor-Copy-Source")
+	copySource := c.Header(headerCopySource)
 
 	if copySource != "" {
 		return createOrUpdateObjectFromCopySource(c, b, key, copySource)
@@ -176,6 +208,18 @@ func handleCreateOrUpdateObject(c *srv.Context) *srv.Response {
 	if contentType == "" {
 		contentType = "application/octet-stream"
 	}
+	expiresAt, err := object.ParseExpiration(c.Header(headerExpiresAt), c.Header(headerTTL))
+	if err != nil {
+		return responseFromError(err)
+	}
+	retention, legalHold, err := writeObjectLock(c)
+	if err != nil {
+		return responseFromError(err)
+	}
+	tags, err := writeTags(c)
+	if err != nil {
+		return responseFromError(err)
+	}
 	d, err := c.GetRawData()
 	if err != nil {
 		if errors.Is(err, srv.ErrNoBody) {
@@ -187,71 +231,88 @@ func handleCreateOrUpdateObject(c *srv.Context) *srv.Response {
 		return responseFromError(err)
 	}
 
-	exists, err := object.Exists(c, b.Name, key)
-	if err != nil {
-		return responseFromError(err)
-	}
-
-	if exists {
-		existing, err := object.FindOne(c, b.Name, key, false)
-		if err != nil {
-			return responseFromError(err)
-		}
-		updated, err := uc.UpdateObjectWithData(c, b, existing, object.UpdateCommand{
-			ContentType: contentType,
-			Data:        d,
-		})
-		if err != nil {
-			return responseFromError(err)
-		}
-		return srv.Respond().NoContent().ETag(updated.ETag)
-	}
-
-	created, err := uc.CreateObjectFromData(c, b, object.CreateCommand{
+	o, err := uc.PutObject(c, b, uc.PutObjectCommand{
 		Key:         key,
 		ContentType: contentType,
 		Data:        d,
+		Conditions:  writeConditions(c),
+		ExpiresAt:   expiresAt,
+		Retention:   retention,
+		LegalHold:   legalHold,
+		Tags:        tags,
 	})
 	if err != nil {
 		return responseFromError(err)
 	}
-	return srv.Respond().NoContent().ETag(created.ETag)
+	return srv.Respond().NoContent().ETag(o.ETag).Header(headerVersionId, o.CurrentVersion)
 }
 
 func createOrUpdateObjectFromCopySource(c *srv.Context, b *bucket.Bucket, key, copySource string) *srv.Response {
-	src, err := object.FindOne(c, b.Name, copySource, false)
+	if err := authorizeCopySource(c, b.Name, copySource); err != nil {
+		return responseFromError(err)
+	}
+	contentType, r := copyContentType(c)
+	if r != nil {
+		return r
+	}
+	expiresAt, err := object.ParseExpiration(c.Header(headerExpiresAt), c.Header(headerTTL))
 	if err != nil {
 		return responseFromError(err)
 	}
-	exists, err := object.Exists(c, b.Name, key)
+	retention, legalHold, err := writeObjectLock(c)
 	if err != nil {
 		return responseFromError(err)
 	}
-	if exists {
-		existing, err := object.FindOne(c, b.Name, key, false)
-		if err != nil {
-			return responseFromError(err)
-		}
-		updated, err := uc.UpdateObjectFromCopy(c, b, src, existing)
-		if err != nil {
-			return responseFromError(err)
-		}
-		return srv.Respond().NoContent().ETag(updated.ETag)
+	tags, err := writeTags(c)
+	if err != nil {
+		return responseFromError(err)
 	}
-	created, err := uc.CreateObjectFromCopy(c, b, src, key)
+	o, err := uc.CopyObject(c, b, uc.CopyObjectCommand{
+		Source:      copySource,
+		Key:         key,
+		ContentType: contentType,
+		Conditions:  writeConditions(c),
+		ExpiresAt:   expiresAt,
+		Retention:   retention,
+		LegalHold:   legalHold,
+		Tags:        tags,
+	})
 	if err != nil {
 		return responseFromError(err)
 	}
-	return srv.Respond().NoContent().ETag(created.ETag)
+	return srv.Respond().NoContent().ETag(o.ETag).Header(headerVersionId, o.CurrentVersion)
+}
+
+// writeConditions returns the preconditions of a write request
+func writeConditions(c *srv.Context) object.Conditions {
+	return object.Conditions{
+		IfMatch:     c.IfMatch(),
+		IfNoneMatch: c.IfNoneMatch(),
+	}
+}
+
+// copyContentType returns the content type of a copy. An empty content type means the source's content type is kept.
+func copyContentType(c *srv.Context) (string, *srv.Response) {
+	switch c.Header(headerMetadataDirective) {
+	case "", metadataDirectiveCopy:
+		return "", nil
+	case metadataDirectiveReplace:
+		contentType := c.Header("Content-Type")
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		return contentType, nil
+	}
+	return "", responseFromError(ec.InvalidArgument)
 }
 
 func handleDeleteObject(c *srv.Context) *srv.Response {
-	o, r := objectFilter(c)
+	b := contextGetBucket(c)
+	key, r := contextGetObjectKey(c)
 	if r != nil {
 		return r
 	}
-	b := contextGetBucket(c)
-	if err := uc.DeleteObject(c, b, o); err != nil {
+	if err := uc.DeleteObject(c, b, key, writeConditions(c)); err != nil {
 		return responseFromError(err)
 	}
 
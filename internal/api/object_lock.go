@@ -0,0 +1,209 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+	"github.com/cfichtmueller/stor/internal/uc"
+)
+
+var (
+	legalHoldOn  = "ON"
+	legalHoldOff = "OFF"
+)
+
+type ObjectRetentionRequest struct {
+	Mode            string    `json:"mode"`
+	RetainUntilDate time.Time `json:"retainUntilDate"`
+}
+
+type ObjectRetentionResponse struct {
+	Mode            string     `json:"mode,omitempty"`
+	RetainUntilDate *time.Time `json:"retainUntilDate,omitempty"`
+}
+
+func newObjectRetentionResponse(o *object.Object) ObjectRetentionResponse {
+	if o.Retention == nil {
+		return ObjectRetentionResponse{}
+	}
+	return ObjectRetentionResponse{
+		Mode:            o.Retention.Mode,
+		RetainUntilDate: &o.Retention.RetainUntil,
+	}
+}
+
+type LegalHold struct {
+	Status string `json:"status"`
+}
+
+func (r LegalHold) Validate() error {
+	v := srv.RequireEnumValue("status", r.Status, []string{legalHoldOn, legalHoldOff}, nil)
+	return srv.Validate(v)
+}
+
+func newLegalHold(o *object.Object) LegalHold {
+	if o.LegalHold {
+		return LegalHold{Status: legalHoldOn}
+	}
+	return LegalHold{Status: legalHoldOff}
+}
+
+type BucketObjectLockConfiguration struct {
+	Mode string `json:"mode"`
+	Days int    `json:"days"`
+}
+
+func handleGetObjectRetention(c *srv.Context) *srv.Response {
+	o, r := mustGetLockedObject(c)
+	if r != nil {
+		return r
+	}
+	return srv.Respond().Json(newObjectRetentionResponse(o))
+}
+
+func handlePutObjectRetention(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	key, r := contextGetObjectKey(c)
+	if r != nil {
+		return r
+	}
+	var req ObjectRetentionRequest
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+	var retention *object.Retention
+	if req.Mode != "" {
+		var err error
+		retention, err = object.NewRetention(req.Mode, req.RetainUntilDate)
+		if err != nil {
+			return responseFromError(err)
+		}
+	}
+	bypass := strings.EqualFold(c.Header(headerBypassGovernanceRetention), "true")
+	if bypass {
+		// bypassing governance defeats the lock, so writing the object isn't enough
+		if err := authorize(c, apikey.ActionAdmin, b.Name, key); err != nil {
+			return responseFromError(err)
+		}
+	}
+
+	o, err := uc.SetObjectRetention(c, b, key, retention, bypass)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().Json(newObjectRetentionResponse(o))
+}
+
+func handleGetObjectLegalHold(c *srv.Context) *srv.Response {
+	o, r := mustGetLockedObject(c)
+	if r != nil {
+		return r
+	}
+	return srv.Respond().Json(newLegalHold(o))
+}
+
+func handlePutObjectLegalHold(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	key, r := contextGetObjectKey(c)
+	if r != nil {
+		return r
+	}
+	var req LegalHold
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+
+	o, err := uc.SetObjectLegalHold(c, b, key, req.Status == legalHoldOn)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().Json(newLegalHold(o))
+}
+
+// mustGetLockedObject authenticates the request and finds the object whose lock state is requested
+func mustGetLockedObject(c *srv.Context) (*object.Object, *srv.Response) {
+	if r := mustAuthenticateApiKey(c); r != nil {
+		return nil, r
+	}
+	b, r := mustGetBucket(c)
+	if r != nil {
+		return nil, r
+	}
+	return mustGetObject(c, b)
+}
+
+func handleGetBucketObjectLock(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	return srv.Respond().Json(newBucketObjectLockConfiguration(b))
+}
+
+func handlePutBucketObjectLock(c *srv.Context) *srv.Response {
+	b, r := mustGetBucket(c)
+	if r != nil {
+		return r
+	}
+	var req BucketObjectLockConfiguration
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+
+	if err := uc.SetBucketDefaultRetention(c, b, req.Mode, req.Days); err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().Json(newBucketObjectLockConfiguration(b))
+}
+
+func newBucketObjectLockConfiguration(b *bucket.Bucket) BucketObjectLockConfiguration {
+	return BucketObjectLockConfiguration{
+		Mode: b.LockMode,
+		Days: b.LockDays,
+	}
+}
+
+// writeObjectLock returns the retention and legal hold of a write request. The retention is nil if the request doesn't specify one.
+func writeObjectLock(c *srv.Context) (*object.Retention, bool, error) {
+	var retention *object.Retention
+	mode := c.Header(headerObjectLockMode)
+	retainUntil := c.Header(headerObjectLockRetainUntilDate)
+	if mode != "" || retainUntil != "" {
+		t, err := time.Parse(time.RFC3339, retainUntil)
+		if err != nil {
+			return nil, false, ec.InvalidRetention
+		}
+		retention, err = object.NewRetention(mode, t)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	switch c.Header(headerObjectLockLegalHold) {
+	case "", legalHoldOff:
+		return retention, false, nil
+	case legalHoldOn:
+		return retention, true, nil
+	}
+	return nil, false, ec.InvalidArgument
+}
+
+// withObjectHeaders adds the expiration and lock headers of o to r
+func withObjectHeaders(r *srv.Response, o *object.Object) *srv.Response {
+	if o.ExpiresAt != nil {
+		r = r.Header(headerExpiresAt, o.ExpiresAt.Format(time.RFC3339))
+	}
+	if o.Retention != nil {
+		r = r.Header(headerObjectLockMode, o.Retention.Mode).
+			Header(headerObjectLockRetainUntilDate, o.Retention.RetainUntil.Format(time.RFC3339))
+	}
+	if o.LegalHold {
+		r = r.Header(headerObjectLockLegalHold, legalHoldOn)
+	}
+	return r
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"strings"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+	"github.com/cfichtmueller/stor/internal/uc"
+)
+
+type ObjectTagging struct {
+	Tags map[string]string `json:"tags"`
+}
+
+func (t ObjectTagging) Validate() error {
+	return srv.Validate(object.ValidateTagsField("tags", t.Tags, nil))
+}
+
+func handleGetObjectTagging(c *srv.Context) *srv.Response {
+	if r := mustAuthenticateApiKey(c); r != nil {
+		return r
+	}
+	b, r := mustGetBucket(c)
+	if r != nil {
+		return r
+	}
+	o, r := mustGetObject(c, b)
+	if r != nil {
+		return r
+	}
+	tags, err := object.Tags(c, o)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().Json(ObjectTagging{Tags: tags})
+}
+
+func handlePutObjectTagging(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	key, r := contextGetObjectKey(c)
+	if r != nil {
+		return r
+	}
+	var req ObjectTagging
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+
+	if _, err := uc.SetObjectTags(c, b, key, req.Tags); err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().Json(req)
+}
+
+func handleDeleteObjectTagging(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	key, r := contextGetObjectKey(c)
+	if r != nil {
+		return r
+	}
+
+	if _, err := uc.SetObjectTags(c, b, key, nil); err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().NoContent()
+}
+
+// writeTags returns the tags of a write request
+func writeTags(c *srv.Context) (map[string]string, error) {
+	return object.ParseTags(c.Header(headerTagging))
+}
+
+// tagFilter parses the tag filters of a request. Each filter has the format key:value.
+func tagFilter(c *srv.Context) (map[string]string, error) {
+	values := c.Request().URL.Query()[queryTag]
+	if len(values) == 0 {
+		return nil, nil
+	}
+	tags := make(map[string]string, len(values))
+	for _, v := range values {
+		k, v, ok := strings.Cut(v, ":")
+		if !ok || k == "" {
+			return nil, ec.InvalidTag
+		}
+		tags[k] = v
+	}
+	if err := object.ValidateTags(tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+// permission is what a request needs to be granted by the policy of its API key
+type permission struct {
+	action string
+	// key is the key or prefix the request acts on. An empty key stands for the whole bucket.
+	key string
+	// byHandler is true if the request carries the keys it acts on in its body. The handler authorizes them
+	// with authorize.
+	byHandler bool
+}
+
+// requestPermission returns the permission a request needs. Requests that aren't known need the admin action on
+// the whole bucket.
+func requestPermission(c *srv.Context) permission {
+	q := c.Request().URL.Query()
+	if c.PathValue(paramObjectKey) != "" {
+		return objectPermission(c)
+	}
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead:
+		switch {
+		case q.Has(queryDownloadZip):
+			return permission{action: apikey.ActionRead, byHandler: true}
+		case q.Has(queryLifecycle), q.Has(queryObjectLock), q.Has(queryNotification):
+			return permission{action: apikey.ActionAdmin}
+		case q.Has(queryChanges), q.Has(queryEvents), q.Has(queryArchives), q.Has(queryMoveId), q.Has(queryJobId):
+			return permission{action: apikey.ActionList}
+		}
+		return permission{action: apikey.ActionList, key: q.Get("prefix")}
+	case http.MethodPost:
+		switch {
+		case q.Has("delete"):
+			return permission{action: apikey.ActionDelete, byHandler: true}
+		case q.Has("copy"), q.Has(queryMovePrefix):
+			return permission{action: apikey.ActionWrite, byHandler: true}
+		case q.Has(queryDeletePrefix):
+			return permission{action: apikey.ActionDelete, key: q.Get(queryDeletePrefix)}
+		case q.Has(queryCopyPrefix):
+			return permission{action: apikey.ActionRead, key: q.Get(queryCopyPrefix)}
+		case q.Has(queryDownloadZip):
+			return permission{action: apikey.ActionRead, byHandler: true}
+		}
+	case http.MethodDelete:
+		if q.Get(queryJobId) != "" {
+			return permission{action: apikey.ActionWrite}
+		}
+	}
+	return permission{action: apikey.ActionAdmin}
+}
+
+func objectPermission(c *srv.Context) permission {
+	q := c.Request().URL.Query()
+	key := c.PathValue(paramObjectKey)
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead:
+		return permission{action: apikey.ActionRead, key: key}
+	case http.MethodPost:
+		if q.Has(queryNonces) || q.Has(queryExtract) {
+			return permission{action: apikey.ActionRead, key: key}
+		} else if q.Has(queryRename) {
+			return permission{action: apikey.ActionDelete, key: key}
+		}
+	case http.MethodDelete:
+		if q.Get(queryArchiveId) == "" && q.Get(queryUploadId) == "" && !q.Has(queryTagging) {
+			return permission{action: apikey.ActionDelete, key: key}
+		}
+	}
+	return permission{action: apikey.ActionWrite, key: key}
+}
+
+// authorizeRequest checks that a policy grants the permission of a request. Requests without a bucket list
+// buckets or search objects; their handlers only return what the policy grants.
+func authorizeRequest(c *srv.Context, policy apikey.Policy) error {
+	p := requestPermission(c)
+	bucketName := c.PathValue(paramBucketName)
+	switch {
+	case bucketName == "":
+		if !policy.HasAction(apikey.ActionList) {
+			return ec.AccessDenied
+		}
+	case p.byHandler:
+		if !policy.AllowsObjects(p.action, bucketName) {
+			return ec.AccessDenied
+		}
+	case !policy.Allows(p.action, bucketName, p.key):
+		return ec.AccessDenied
+	}
+	return nil
+}
+
+// authorize checks that the API key of a request is granted an action on a key or prefix. Handlers use it for
+// keys that are part of the request body.
+func authorize(c *srv.Context, action, bucketName, key string) error {
+	k, ok := contextGetApiKey(c)
+	if !ok || !k.Policy.Allows(action, bucketName, key) {
+		return ec.AccessDenied
+	}
+	return nil
+}
+
+// authorizeCopySource checks that the API key of a request may read a copy source
+func authorizeCopySource(c *srv.Context, bucketName, source string) error {
+	s, err := object.ParseCopySource(source, bucketName)
+	if err != nil {
+		return err
+	}
+	return authorize(c, apikey.ActionRead, s.Bucket, s.Key)
+}
+
+// contextAllows returns true if the API key of a request is granted an action on a key. It is used to filter
+// listings across buckets.
+func contextAllows(c *srv.Context, action, bucketName, key string) bool {
+	k, ok := contextGetApiKey(c)
+	return ok && k.Policy.Allows(action, bucketName, key)
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/domain/job"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+	"github.com/cfichtmueller/stor/internal/uc"
+)
+
+type CopyPrefixRequest struct {
+	// Bucket is the bucket the objects are copied into. Objects are copied within the bucket if empty.
+	Bucket      string `json:"bucket"`
+	Destination string `json:"destination"`
+}
+
+// DryRunResponse is the result of a prefix operation with dry-run
+type DryRunResponse struct {
+	Prefix  string `json:"prefix"`
+	Objects int64  `json:"objects"`
+	Size    int64  `json:"size"`
+}
+
+type JobResponse struct {
+	ID              string     `json:"id"`
+	Type            string     `json:"type"`
+	State           string     `json:"state"`
+	Done            int64      `json:"done"`
+	Total           int64      `json:"total"`
+	Attempts        int        `json:"attempts"`
+	Error           string     `json:"error,omitempty"`
+	CancelRequested bool       `json:"cancelRequested,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	FinishedAt      *time.Time `json:"finishedAt,omitempty"`
+}
+
+func newJobResponse(j *job.Job) JobResponse {
+	return JobResponse{
+		ID:              j.ID,
+		Type:            j.Type,
+		State:           j.State,
+		Done:            j.Done,
+		Total:           j.Total,
+		Attempts:        j.Attempts,
+		Error:           j.Error,
+		CancelRequested: j.CancelRequested,
+		CreatedAt:       j.CreatedAt,
+		UpdatedAt:       j.UpdatedAt,
+		FinishedAt:      j.FinishedAt,
+	}
+}
+
+func newDryRunResponse(prefix string, s *object.Stats) DryRunResponse {
+	return DryRunResponse{
+		Prefix:  prefix,
+		Objects: s.ObjectCount,
+		Size:    s.TotalSize,
+	}
+}
+
+func handleDeletePrefix(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	cmd := uc.DeletePrefixCommand{
+		Bucket: b.Name,
+		Prefix: c.Query(queryDeletePrefix),
+	}
+
+	if c.Request().URL.Query().Has(queryDryRun) {
+		s, err := uc.PreviewDeletePrefix(c, cmd)
+		if err != nil {
+			return responseFromError(err)
+		}
+		return srv.Respond().Json(newDryRunResponse(cmd.Prefix, s))
+	}
+
+	j, err := uc.DeletePrefix(c, cmd)
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().Status(http.StatusAccepted).Json(newJobResponse(j))
+}
+
+func handleCopyPrefix(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	var req CopyPrefixRequest
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+	destinationBucket := req.Bucket
+	if destinationBucket == "" {
+		destinationBucket = b.Name
+	}
+	if err := authorize(c, apikey.ActionWrite, destinationBucket, req.Destination); err != nil {
+		return responseFromError(err)
+	}
+	cmd := uc.CopyPrefixCommand{
+		Bucket:            b.Name,
+		Prefix:            c.Query(queryCopyPrefix),
+		DestinationBucket: req.Bucket,
+		Destination:       req.Destination,
+	}
+
+	if c.Request().URL.Query().Has(queryDryRun) {
+		s, err := uc.PreviewCopyPrefix(c, cmd)
+		if err != nil {
+			return responseFromError(err)
+		}
+		return srv.Respond().Json(newDryRunResponse(cmd.Prefix, s))
+	}
+
+	j, err := uc.CopyPrefix(c, cmd)
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().Status(http.StatusAccepted).Json(newJobResponse(j))
+}
+
+func handleGetJob(c *srv.Context) *srv.Response {
+	j, err := findBucketJob(c)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().Json(newJobResponse(j))
+}
+
+func handleCancelJob(c *srv.Context) *srv.Response {
+	j, err := findBucketJob(c)
+	if err != nil {
+		return responseFromError(err)
+	}
+	if err := job.Cancel(c, j); err != nil {
+		return responseFromError(err)
+	}
+	j, err = job.FindOne(c, j.ID)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().Status(http.StatusAccepted).Json(newJobResponse(j))
+}
+
+// findBucketJob finds the job of the request. Jobs of other buckets are not found.
+func findBucketJob(c *srv.Context) (*job.Job, error) {
+	b := contextGetBucket(c)
+	id := c.Query(queryJobId)
+	if id == "" {
+		return nil, ec.InvalidArgument
+	}
+	j, err := job.FindOne(c, id)
+	if err != nil {
+		return nil, err
+	}
+	if j.Bucket != b.Name {
+		return nil, ec.NoSuchJob
+	}
+	return j, nil
+}
@@ -5,10 +5,38 @@
 package api
 
 var (
-	queryArchiveId  = "archive-id"
-	queryArchives   = "archives"
-	queryPartNumber = "part-number"
-	queryNonces     = "nonces"
-	queryUploadId   = "upload-id"
-	queryUploads    = "uploads"
+	queryArchiveId         = "archive-id"
+	queryArchives          = "archives"
+	queryCancel            = "cancel"
+	queryChanges           = "changes"
+	queryChunk             = "chunk"
+	queryChunks            = "chunks"
+	queryContinuationToken = "continuation-token"
+	queryCopyPrefix        = "copy-prefix"
+	queryCursor            = "cursor"
+	queryDeletePrefix      = "delete-prefix"
+	queryDownloadZip       = "download-zip"
+	queryDryRun            = "dry-run"
+	queryEvents            = "events"
+	queryExtract           = "extract"
+	queryInclude           = "include"
+	queryJobId             = "job-id"
+	queryLegalHold         = "legal-hold"
+	queryLifecycle         = "lifecycle"
+	queryManifest          = "manifest"
+	queryMoveId            = "move-id"
+	queryMovePrefix        = "move-prefix"
+	queryPartNumber        = "part-number"
+	queryNonces            = "nonces"
+	queryNotification      = "notification"
+	queryObjectLock        = "object-lock"
+	queryRename            = "rename"
+	queryResults           = "results"
+	queryRetention         = "retention"
+	queryRetry             = "retry"
+	querySearch            = "search"
+	queryTag               = "tag"
+	queryTagging           = "tagging"
+	queryUploadId          = "upload-id"
+	queryUploads           = "uploads"
 )
@@ -0,0 +1,125 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/domain/move"
+	"github.com/cfichtmueller/stor/internal/ec"
+	"github.com/cfichtmueller/stor/internal/uc"
+)
+
+type RenameObjectRequest struct {
+	Key string `json:"key"`
+}
+
+func (r RenameObjectRequest) Validate() error {
+	v := srv.RequireNotEmpty("key", r.Key, nil)
+	return srv.Validate(v)
+}
+
+type RenameObjectResult struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	ETag   string `json:"etag"`
+}
+
+func handleRenameObject(c *srv.Context) *srv.Response {
+	o, r := objectFilter(c)
+	if r != nil {
+		return r
+	}
+	b := contextGetBucket(c)
+	var req RenameObjectRequest
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+	if err := authorize(c, apikey.ActionWrite, b.Name, req.Key); err != nil {
+		return responseFromError(err)
+	}
+
+	if err := uc.RenameObject(c, b, o, req.Key); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().Json(RenameObjectResult{
+		Bucket: b.Name,
+		Key:    o.Key,
+		ETag:   o.ETag,
+	})
+}
+
+type MovePrefixRequest struct {
+	Prefix      string `json:"prefix"`
+	Destination string `json:"destination"`
+}
+
+type MoveResponse struct {
+	ID          string    `json:"id"`
+	Prefix      string    `json:"prefix"`
+	Destination string    `json:"destination"`
+	State       string    `json:"state"`
+	Total       int64     `json:"total"`
+	Moved       int64     `json:"moved"`
+	Failed      int64     `json:"failed"`
+	Job         string    `json:"job"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func newMoveResponse(m *move.Move) MoveResponse {
+	return MoveResponse{
+		ID:          m.ID,
+		Prefix:      m.Prefix,
+		Destination: m.Destination,
+		State:       m.State,
+		Total:       m.Total,
+		Moved:       m.Moved,
+		Failed:      m.Failed,
+		Job:         m.Job,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func handleMovePrefix(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	var req MovePrefixRequest
+	if r := c.BindJSON(&req); r != nil {
+		return r
+	}
+	if err := authorize(c, apikey.ActionDelete, b.Name, req.Prefix); err != nil {
+		return responseFromError(err)
+	}
+	if err := authorize(c, apikey.ActionWrite, b.Name, req.Destination); err != nil {
+		return responseFromError(err)
+	}
+
+	m, err := move.Create(c, move.CreateCommand{
+		Bucket:      b.Name,
+		Prefix:      req.Prefix,
+		Destination: req.Destination,
+	})
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().Status(http.StatusAccepted).Json(newMoveResponse(m))
+}
+
+func handleGetMove(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	id := c.Query(queryMoveId)
+	if id == "" {
+		return responseFromError(ec.InvalidArgument)
+	}
+	m, err := move.FindOne(c, b.Name, id)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return srv.Respond().Json(newMoveResponse(m))
+}
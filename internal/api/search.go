@@ -0,0 +1,104 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+type SearchObjectsResponse struct {
+	IsTruncated           bool                   `json:"isTruncated"`
+	Results               []SearchResultResponse `json:"results"`
+	MaxKeys               int                    `json:"maxKeys"`
+	KeyCount              int                    `json:"keyCount"`
+	NextContinuationToken string                 `json:"nextContinuationToken,omitempty"`
+}
+
+type SearchResultResponse struct {
+	Bucket string `json:"bucket"`
+	ObjectResponse
+}
+
+// searchToken is the cursor of a search request. Clients treat it as an opaque string.
+type searchToken struct {
+	Query  string `json:"q"`
+	Bucket string `json:"b"`
+	Key    string `json:"k"`
+}
+
+func (t searchToken) encode() string {
+	d, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(d)
+}
+
+// decodeSearchToken decodes a token and checks that it belongs to a search with the same query
+func decodeSearchToken(s, query string) (*object.SearchCursor, error) {
+	d, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ec.InvalidContinuationToken
+	}
+	var t searchToken
+	if err := json.Unmarshal(d, &t); err != nil {
+		return nil, ec.InvalidContinuationToken
+	}
+	if t.Query != query {
+		return nil, ec.InvalidContinuationToken
+	}
+	return &object.SearchCursor{Bucket: t.Bucket, Key: t.Key}, nil
+}
+
+func handleSearchObjects(c *srv.Context) *srv.Response {
+	query := c.Query(querySearch)
+	maxKeys, r := c.IntQueryOrDefault("max-keys", 1000)
+	if r != nil {
+		return r
+	}
+	if maxKeys > 1000 {
+		maxKeys = 1000
+	}
+	if maxKeys < 0 {
+		maxKeys = 0
+	}
+	var after *object.SearchCursor
+	if token := c.Query(queryContinuationToken); token != "" {
+		var err error
+		after, err = decodeSearchToken(token, query)
+		if err != nil {
+			return responseFromError(err)
+		}
+	}
+
+	// one more object than requested is fetched to find out whether the results are truncated
+	objects, err := object.Search(c, query, nil, after, maxKeys+1)
+	if err != nil {
+		return responseFromError(err)
+	}
+	res := SearchObjectsResponse{
+		Results: make([]SearchResultResponse, 0, len(objects)),
+		MaxKeys: maxKeys,
+	}
+	if len(objects) > maxKeys {
+		objects = objects[:maxKeys]
+		res.IsTruncated = true
+	}
+	if res.IsTruncated && len(objects) > 0 {
+		last := objects[len(objects)-1]
+		res.NextContinuationToken = searchToken{Query: query, Bucket: last.Bucket, Key: last.Key}.encode()
+	}
+	for _, o := range objects {
+		// objects the API key may not list are left out, the continuation token still moves past them
+		if contextAllows(c, apikey.ActionList, o.Bucket, o.Key) {
+			res.Results = append(res.Results, SearchResultResponse{Bucket: o.Bucket, ObjectResponse: newObjectResponse(o)})
+		}
+	}
+	res.KeyCount = len(res.Results)
+	return srv.Respond().Json(res)
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package bus
+
+import "log/slog"
+
+// Listener receives the events of a topic on a channel
+type Listener[T any] struct {
+	C   <-chan T
+	sub *Subscription
+}
+
+// Listen subscribes a channel with a buffer of size to a topic. Publishing never waits for the listener:
+// events are dropped while the buffer is full. The listener must be closed when it is no longer needed.
+func Listen[T any](t Topic[T], size int) *Listener[T] {
+	c := make(chan T, size)
+	s := &Subscription{
+		topic: t.name,
+		listen: func(e any) {
+			select {
+			case c <- e.(T):
+			default:
+				slog.Warn("dropped event of slow listener", "topic", t.name)
+			}
+		},
+	}
+	mu.Lock()
+	subscribers[t.name] = append(subscribers[t.name], s)
+	mu.Unlock()
+	return &Listener[T]{C: c, sub: s}
+}
+
+// Close unsubscribes the listener from its topic
+func (l *Listener[T]) Close() {
+	l.sub.Unsubscribe()
+}
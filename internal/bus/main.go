@@ -2,70 +2,233 @@
 // Use of this source code is governed by a MIT style
 // license that can be found in the LICENSE file.
 
+// Package bus delivers events from publishers to subscribers. Subscribers handle events asynchronously on
+// their own workers and failed handlers are retried with backoff. Events of durable topics are stored in
+// the database until every subscriber has handled them, so that they are handled after a restart.
 package bus
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/util"
 )
 
-type Handler func(event any)
-type HandlerC func(c context.Context, event any)
-type HandlerE func(event any) error
-type HandlerCE func(c context.Context, event any) error
+var (
+	// RetryDelay is the delay before the first retry of a failed handler. It doubles with every attempt.
+	RetryDelay = time.Second
+	// MaxRetryDelay is the longest delay between two attempts
+	MaxRetryDelay = 5 * time.Minute
+	// MaxAttempts is the number of attempts after which an event is given up
+	MaxAttempts = 10
+	// queueSize is the number of events of a topic that are buffered for a subscriber
+	queueSize = 1024
 
-var subscribers map[string][]HandlerCE
+	mu          sync.RWMutex
+	subscribers = make(map[string][]*Subscription)
+	// configured is true once durable subscribers can be started
+	configured bool
+)
 
-func init() {
-	subscribers = make(map[string][]HandlerCE)
+// Topic is a named stream of events of type T
+type Topic[T any] struct {
+	name    string
+	durable bool
 }
 
-func Subscribe(topic string, handler Handler) {
-	SubscribeCE(topic, func(c context.Context, e any) error {
-		handler(e)
-		return nil
-	})
+// NewTopic creates a topic whose events are kept in memory. Events that haven't been handled are lost on shutdown.
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
 }
 
-func SubscribeE(topic string, handler HandlerE) {
-	SubscribeCE(topic, func(c context.Context, e any) error {
-		return handler(e)
-	})
+// NewDurableTopic creates a topic whose events are stored in the database. T must be JSON serializable.
+func NewDurableTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name, durable: true}
 }
 
-func SubscribeCE(topic string, handler HandlerCE) {
-	_, exists := subscribers[topic]
-	if !exists {
-		subscribers[topic] = make([]HandlerCE, 0, 1)
-	}
-	subscribers[topic] = append(subscribers[topic], handler)
+func (t Topic[T]) Name() string {
+	return t.name
 }
 
-func Publish(topic string, event any) {
-	if err := PublishE(topic, event); err != nil {
-		slog.Error("unable to publish event", "topic", topic, "error", err)
+type Handler[T any] func(ctx context.Context, event T) error
+
+type Option func(s *Subscription)
+
+// Workers sets the number of events a subscriber handles at the same time. Events are handled in order
+// of publication if there is only one worker, which is the default.
+func Workers(n int) Option {
+	return func(s *Subscription) {
+		s.workers = max(n, 1)
 	}
 }
 
-func PublishC(c context.Context, topic string, event any) {
-	if err := PublishCE(c, topic, event); err != nil {
-		slog.Error("unable to publish event", "topic", topic, "error", err)
+// Subscription is a subscriber of a topic
+type Subscription struct {
+	topic string
+	// name identifies the stored events of the subscriber. It must be unique within a durable topic
+	// and must not change between restarts.
+	name    string
+	durable bool
+	workers int
+	handle  func(ctx context.Context, event any) error
+	decode  func(payload []byte) (any, error)
+	// listen is called in the goroutine of the publisher. It is set for listeners only.
+	listen   func(event any)
+	queue    chan any
+	wake     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Subscribe adds a subscriber to a topic. Events published before a subscriber exists aren't delivered to it.
+func Subscribe[T any](t Topic[T], name string, handler Handler[T], opts ...Option) *Subscription {
+	s := &Subscription{
+		topic:   t.name,
+		name:    name,
+		durable: t.durable,
+		workers: 1,
+		handle: func(ctx context.Context, e any) error {
+			return handler(ctx, e.(T))
+		},
+		decode: func(payload []byte) (any, error) {
+			var e T
+			err := json.Unmarshal(payload, &e)
+			return e, err
+		},
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	if !s.durable {
+		s.queue = make(chan any, queueSize)
+	}
+
+	mu.Lock()
+	if s.durable && slices.ContainsFunc(subscribers[t.name], func(o *Subscription) bool { return o.name == name }) {
+		mu.Unlock()
+		panic(fmt.Sprintf("bus: duplicate subscriber %s of topic %s", name, t.name))
+	}
+	subscribers[t.name] = append(subscribers[t.name], s)
+	start := configured || !s.durable
+	mu.Unlock()
+
+	if start {
+		s.start()
+	}
+	return s
 }
 
-func PublishE(topic string, event any) error {
-	return PublishCE(context.Background(), topic, event)
+// Unsubscribe removes the subscriber from its topic. Events that are handled at the moment are finished.
+// Stored events of a durable subscriber are kept for the next time it subscribes.
+func (s *Subscription) Unsubscribe() {
+	mu.Lock()
+	subscribers[s.topic] = slices.DeleteFunc(subscribers[s.topic], func(o *Subscription) bool {
+		return o == s
+	})
+	mu.Unlock()
+	s.stopOnce.Do(func() {
+		if s.done != nil {
+			close(s.done)
+		}
+	})
 }
 
-func PublishCE(c context.Context, topic string, event any) error {
-	subs, ok := subscribers[topic]
-	if !ok {
-		return nil
+// Publish publishes an event. Errors are logged.
+func Publish[T any](ctx context.Context, t Topic[T], event T) {
+	if err := PublishE(ctx, t, event); err != nil {
+		slog.Error("unable to publish event", "topic", t.name, "error", err)
 	}
-	for _, sub := range subs {
-		if err := sub(c, event); err != nil {
-			return err
+}
+
+// PublishE publishes an event. It returns once the event is queued for every subscriber. Publishing waits
+// for subscribers of in-memory topics with a full queue, but never for listeners.
+func PublishE[T any](ctx context.Context, t Topic[T], event T) error {
+	mu.RLock()
+	subs := slices.Clone(subscribers[t.name])
+	mu.RUnlock()
+
+	var payload []byte
+	for _, s := range subs {
+		switch {
+		case s.listen != nil:
+			s.listen(event)
+		case s.durable:
+			if payload == nil {
+				var err error
+				if payload, err = json.Marshal(event); err != nil {
+					return fmt.Errorf("unable to encode event: %w", err)
+				}
+			}
+			// a cancelled publisher must not lose the event of a change it has already made
+			if err := enqueue(context.WithoutCancel(ctx), s, payload); err != nil {
+				return err
+			}
+		default:
+			select {
+			case s.queue <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
 	}
 	return nil
 }
+
+func (s *Subscription) start() {
+	if s.durable {
+		go s.dispatch()
+		return
+	}
+	for range s.workers {
+		go s.work()
+	}
+}
+
+// work handles the events of an in-memory topic
+func (s *Subscription) work() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case e := <-s.queue:
+			s.retry(e)
+		}
+	}
+}
+
+// retry handles an event of an in-memory topic until it succeeds or has been attempted too often
+func (s *Subscription) retry(e any) {
+	for attempts := 1; ; attempts++ {
+		err := s.call(e)
+		if err == nil {
+			return
+		}
+		if attempts >= MaxAttempts {
+			slog.Error("event handler failed permanently", "topic", s.topic, "subscriber", s.name, "error", err)
+			return
+		}
+		slog.Warn("event handler failed", "topic", s.topic, "subscriber", s.name, "attempts", attempts, "error", err)
+		select {
+		case <-time.After(util.Backoff(RetryDelay, MaxRetryDelay, attempts)):
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// call runs the handler. Panics are returned as errors so that they are retried like failures.
+func (s *Subscription) call(e any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return s.handle(context.Background(), e)
+}
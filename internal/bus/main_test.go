@@ -0,0 +1,227 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package bus
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/config"
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+)
+
+type testEvent struct {
+	Value string
+}
+
+var configureOnce sync.Once
+
+func configure() {
+	configureOnce.Do(func() {
+		config.DataDir = os.TempDir()
+		PollInterval = 10 * time.Millisecond
+		RetryDelay = 10 * time.Millisecond
+		MaxAttempts = 3
+		db.Configure()
+		Configure()
+	})
+}
+
+func topicName() string {
+	return "test." + domain.RandomId()
+}
+
+// receive waits for an event on c
+func receive[T any](t *testing.T, c <-chan T) T {
+	t.Helper()
+	select {
+	case e := <-c:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected an event")
+	}
+	var zero T
+	return zero
+}
+
+// expectNone fails if an event arrives on c within a short time
+func expectNone[T any](t *testing.T, c <-chan T) {
+	t.Helper()
+	select {
+	case e := <-c:
+		t.Fatalf("expected no event, got %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestTypedDelivery(t *testing.T) {
+	configure()
+	ctx := context.Background()
+	events := NewTopic[testEvent](topicName())
+	counts := NewTopic[int](topicName())
+
+	received := make(chan testEvent, 4)
+	s := Subscribe(events, "test", func(ctx context.Context, e testEvent) error {
+		received <- e
+		return nil
+	})
+	defer s.Unsubscribe()
+
+	Publish(ctx, counts, 42)
+	Publish(ctx, events, testEvent{Value: "a"})
+	Publish(ctx, events, testEvent{Value: "b"})
+
+	if e := receive(t, received); e.Value != "a" {
+		t.Errorf("expected event a, got %+v", e)
+	}
+	if e := receive(t, received); e.Value != "b" {
+		t.Errorf("expected event b, got %+v", e)
+	}
+	expectNone(t, received)
+}
+
+func TestRetry(t *testing.T) {
+	configure()
+	ctx := context.Background()
+	topic := NewTopic[testEvent](topicName())
+
+	var attempts atomic.Int32
+	handled := make(chan testEvent, 1)
+	s := Subscribe(topic, "test", func(ctx context.Context, e testEvent) error {
+		if attempts.Add(1) < 2 {
+			return errors.New("failed")
+		}
+		handled <- e
+		return nil
+	})
+	defer s.Unsubscribe()
+
+	Publish(ctx, topic, testEvent{Value: "a"})
+	receive(t, handled)
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("expected 2 attempts, got %d", n)
+	}
+
+	var failures atomic.Int32
+	done := make(chan struct{}, MaxAttempts+1)
+	failing := Subscribe(topic, "failing", func(ctx context.Context, e testEvent) error {
+		failures.Add(1)
+		done <- struct{}{}
+		panic("failed")
+	})
+	defer failing.Unsubscribe()
+
+	Publish(ctx, topic, testEvent{Value: "b"})
+	for range MaxAttempts {
+		receive(t, done)
+	}
+	expectNone(t, done)
+	if n := failures.Load(); int(n) != MaxAttempts {
+		t.Errorf("expected %d attempts of a failing handler, got %d", MaxAttempts, n)
+	}
+}
+
+func TestDurableRetry(t *testing.T) {
+	configure()
+	ctx := context.Background()
+	topic := NewDurableTopic[testEvent](topicName())
+
+	var attempts atomic.Int32
+	handled := make(chan testEvent, 1)
+	s := Subscribe(topic, "test", func(ctx context.Context, e testEvent) error {
+		if attempts.Add(1) < 2 {
+			return errors.New("failed")
+		}
+		handled <- e
+		return nil
+	})
+	defer s.Unsubscribe()
+
+	if err := PublishE(ctx, topic, testEvent{Value: "a"}); err != nil {
+		t.Fatalf("unable to publish event: %v", err)
+	}
+	if e := receive(t, handled); e.Value != "a" {
+		t.Errorf("expected event a, got %+v", e)
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("expected 2 attempts, got %d", n)
+	}
+}
+
+func TestDurableRedelivery(t *testing.T) {
+	configure()
+	ctx := context.Background()
+	topic := NewDurableTopic[testEvent](topicName())
+
+	// the first subscriber is stopped while it handles the event, like a process that crashes
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	var startOnce sync.Once
+	first := Subscribe(topic, "test", func(ctx context.Context, e testEvent) error {
+		startOnce.Do(func() { close(started) })
+		<-release
+		return errors.New("stopped")
+	})
+	if err := PublishE(ctx, topic, testEvent{Value: "a"}); err != nil {
+		t.Fatalf("unable to publish event: %v", err)
+	}
+	receive(t, started)
+	if err := PublishE(ctx, topic, testEvent{Value: "b"}); err != nil {
+		t.Fatalf("unable to publish event: %v", err)
+	}
+	// events that are stored for an unsubscribed subscriber are kept
+	first.Unsubscribe()
+
+	// a restart releases the claim of the interrupted event
+	if _, err := releaseClaimsStmt.Exec(); err != nil {
+		t.Fatalf("unable to release claimed events: %v", err)
+	}
+	handled := make(chan testEvent, 4)
+	second := Subscribe(topic, "test", func(ctx context.Context, e testEvent) error {
+		handled <- e
+		return nil
+	})
+	defer second.Unsubscribe()
+
+	if e := receive(t, handled); e.Value != "a" {
+		t.Errorf("expected the interrupted event a, got %+v", e)
+	}
+	if e := receive(t, handled); e.Value != "b" {
+		t.Errorf("expected event b, got %+v", e)
+	}
+	expectNone(t, handled)
+}
+
+func TestListen(t *testing.T) {
+	configure()
+	ctx := context.Background()
+	topic := NewTopic[testEvent](topicName())
+
+	l := Listen(topic, 1)
+	published := make(chan struct{})
+	go func() {
+		// publishing must not wait for a listener with a full buffer
+		Publish(ctx, topic, testEvent{Value: "a"})
+		Publish(ctx, topic, testEvent{Value: "b"})
+		close(published)
+	}()
+	receive(t, published)
+
+	if e := receive(t, l.C); e.Value != "a" {
+		t.Errorf("expected event a, got %+v", e)
+	}
+	expectNone(t, l.C)
+
+	l.Close()
+	Publish(ctx, topic, testEvent{Value: "c"})
+	expectNone(t, l.C)
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package bus
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/util"
+)
+
+// PollInterval is the time after which durable subscribers look for events that are due for a retry
+var PollInterval = time.Second
+
+var (
+	// Stores an event for a subscriber. Input: topic, subscriber, payload, now
+	insertEventStmt *sql.Stmt
+	// Claims the due events of a subscriber. Input: topic, subscriber, now, limit
+	claimEventsStmt *sql.Stmt
+	// Schedules the next attempt of an event. Input: dead, attempts, next attempt, last error, id
+	releaseEventStmt *sql.Stmt
+	// Deletes a handled event. Input: id
+	deleteEventStmt *sql.Stmt
+	// Releases all claimed events. Input: none
+	releaseClaimsStmt *sql.Stmt
+	// Releases a claimed event without counting an attempt. Input: id
+	releaseClaimStmt *sql.Stmt
+
+	errNotConfigured = errors.New("event bus is not configured")
+)
+
+type storedEvent struct {
+	id       int64
+	payload  []byte
+	attempts int
+}
+
+// Configure prepares the event store and starts the durable subscribers
+func Configure() {
+	insertEventStmt = db.Prepare("INSERT INTO bus_events (topic, subscriber, payload, attempts, claimed, dead, next_attempt_at, last_error, created_at) VALUES ($1, $2, $3, 0, false, false, $4, '', $4)")
+	claimEventsStmt = db.Prepare("UPDATE bus_events SET claimed = true WHERE id IN (SELECT id FROM bus_events WHERE topic = $1 AND subscriber = $2 AND dead = false AND claimed = false AND next_attempt_at <= $3 ORDER BY id LIMIT $4) RETURNING id, payload, attempts")
+	releaseEventStmt = db.Prepare("UPDATE bus_events SET claimed = false, dead = $1, attempts = $2, next_attempt_at = $3, last_error = $4 WHERE id = $5")
+	deleteEventStmt = db.Prepare("DELETE FROM bus_events WHERE id = $1")
+	releaseClaimsStmt = db.Prepare("UPDATE bus_events SET claimed = false WHERE claimed = true")
+	releaseClaimStmt = db.Prepare("UPDATE bus_events SET claimed = false WHERE id = $1")
+
+	// events claimed before a restart have been interrupted and are handled again
+	if _, err := releaseClaimsStmt.Exec(); err != nil {
+		log.Fatalf("unable to release claimed events: %v", err)
+	}
+
+	mu.Lock()
+	configured = true
+	durable := make([]*Subscription, 0)
+	for _, subs := range subscribers {
+		for _, s := range subs {
+			if s.durable {
+				durable = append(durable, s)
+			}
+		}
+	}
+	mu.Unlock()
+	for _, s := range durable {
+		s.start()
+	}
+}
+
+func enqueue(ctx context.Context, s *Subscription, payload []byte) error {
+	if insertEventStmt == nil {
+		return errNotConfigured
+	}
+	if _, err := insertEventStmt.ExecContext(ctx, s.topic, s.name, string(payload), domain.TimeNow()); err != nil {
+		return fmt.Errorf("unable to store event: %w", err)
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// dispatch claims the stored events of a durable subscriber and hands them to its workers
+func (s *Subscription) dispatch() {
+	events := make(chan *storedEvent)
+	var wg sync.WaitGroup
+	for range s.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range events {
+				s.attempt(e)
+			}
+		}()
+	}
+	defer func() {
+		close(events)
+		wg.Wait()
+	}()
+
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			claimed, err := s.claim()
+			if err != nil {
+				slog.Error("unable to claim events", "topic", s.topic, "subscriber", s.name, "error", err)
+				break
+			}
+			for i, e := range claimed {
+				select {
+				case events <- e:
+				case <-s.done:
+					// events that haven't been handed to a worker are left for the next subscriber
+					s.release(claimed[i:])
+					return
+				}
+			}
+			if len(claimed) < s.workers {
+				break
+			}
+		}
+		select {
+		case <-s.done:
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Subscription) claim() ([]*storedEvent, error) {
+	rows, err := claimEventsStmt.Query(s.topic, s.name, domain.TimeNow(), s.workers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := make([]*storedEvent, 0, s.workers)
+	for rows.Next() {
+		var e storedEvent
+		var payload string
+		if err := rows.Scan(&e.id, &payload, &e.attempts); err != nil {
+			return nil, err
+		}
+		e.payload = []byte(payload)
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
+
+// release releases the claims of events that haven't been handled
+func (s *Subscription) release(events []*storedEvent) {
+	for _, e := range events {
+		if _, err := releaseClaimStmt.Exec(e.id); err != nil {
+			slog.Error("unable to release event", "topic", s.topic, "subscriber", s.name, "error", err)
+		}
+	}
+}
+
+// attempt handles a stored event. Handled events are deleted, failed ones are retried with backoff until
+// they are given up. Given up events are kept in the store for inspection.
+func (s *Subscription) attempt(e *storedEvent) {
+	event, err := s.decode(e.payload)
+	attempts := e.attempts + 1
+	if err == nil {
+		err = s.call(event)
+	} else {
+		// an event that can't be decoded won't succeed later
+		attempts = MaxAttempts
+	}
+	if err == nil {
+		if _, err := deleteEventStmt.Exec(e.id); err != nil {
+			slog.Error("unable to delete handled event", "topic", s.topic, "subscriber", s.name, "error", err)
+		}
+		return
+	}
+	dead := attempts >= MaxAttempts
+	if dead {
+		slog.Error("event handler failed permanently", "topic", s.topic, "subscriber", s.name, "event", e.id, "error", err)
+	} else {
+		slog.Warn("event handler failed", "topic", s.topic, "subscriber", s.name, "event", e.id, "attempts", attempts, "error", err)
+	}
+	if _, err := releaseEventStmt.Exec(dead, attempts, domain.TimeNow().Add(util.Backoff(RetryDelay, MaxRetryDelay, attempts)), err.Error(), e.id); err != nil {
+		slog.Error("unable to release event", "topic", s.topic, "subscriber", s.name, "error", err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/cfichtmueller/stor/internal/config"
 	"github.com/cfichtmueller/stor/internal/console"
 	"github.com/cfichtmueller/stor/internal/shell"
+	"github.com/cfichtmueller/stor/internal/uc"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
 )
@@ -25,6 +26,8 @@ var (
 var serveCmd = &cobra.Command{
 	Use: "serve",
 	Run: func(cmd *cobra.Command, args []string) {
+		// subscribers are registered before the domain workers start publishing events
+		uc.Configure()
 		shell.Configure()
 		apiEngine := api.Configure()
 		consoleEngine := console.Configure()
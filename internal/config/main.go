@@ -5,8 +5,11 @@
 package config
 
 import (
+	"log"
 	"os"
 	"path"
+	"strconv"
+	"time"
 )
 
 var (
@@ -16,6 +19,12 @@ var (
 	ConsoleHost  string
 	ConsolePort  string
 	TrustProxies bool
+	// ChangesRetention is the time for which changes of objects are kept in the change journal
+	ChangesRetention time.Duration
+	// ArchiveWorkers is the number of archives that are written at the same time
+	ArchiveWorkers int
+	// ArchiveRetention is the time for which aborted, cancelled and failed archives are kept
+	ArchiveRetention time.Duration
 )
 
 func init() {
@@ -25,6 +34,9 @@ func init() {
 	ConsoleHost = os.Getenv("CONSOLE_HOST")
 	ConsolePort = getEnv("CONSOLE_PORT", "8001")
 	TrustProxies = getEnv("TRUST_PROXIES", "false") == "true"
+	ChangesRetention = getDuration("CHANGES_RETENTION", 7*24*time.Hour)
+	ArchiveWorkers = getInt("ARCHIVE_WORKERS", 2)
+	ArchiveRetention = getDuration("ARCHIVE_RETENTION", 7*24*time.Hour)
 }
 
 func Mkdir(name string) error {
@@ -43,3 +55,27 @@ func getEnv(key, fallback string) string {
 	}
 	return v
 }
+
+func getInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		log.Fatalf("invalid number %s for %s", v, key)
+	}
+	return n
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("invalid duration %s for %s: %v", v, key, err)
+	}
+	return d
+}
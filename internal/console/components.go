@@ -67,6 +67,25 @@ func handleRenderEmptyBucketDialog(c *srv.Context) (e.Node, error) {
 	return ui.EmptyBucketDialog(b), nil
 }
 
+func handleRenderCreateLifecycleRuleDialog(c *srv.Context) (e.Node, error) {
+	b := contextGetBucket(c)
+	return ui.CreateLifecycleRuleDialog(b), nil
+}
+
+func handleRenderCreateWebhookDialog(c *srv.Context) (e.Node, error) {
+	b := contextGetBucket(c)
+	return ui.CreateWebhookDialog(b), nil
+}
+
+//
+// Object
+//
+
+func handleRenderRenameObjectDialog(c *srv.Context) (e.Node, error) {
+	o := contextGetObject(c)
+	return ui.RenameObjectDialog(o), nil
+}
+
 //
 // Dashboard
 //
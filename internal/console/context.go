@@ -7,7 +7,9 @@ package console
 import (
 	"github.com/cfichtmueller/srv"
 	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/object"
 	"github.com/cfichtmueller/stor/internal/domain/session"
 )
 
@@ -19,6 +21,22 @@ func contextGetBucket(c *srv.Context) *bucket.Bucket {
 	return c.MustGet("bucket").(*bucket.Bucket)
 }
 
+func contextSetObject(c *srv.Context, o *object.Object) {
+	c.Set("object", o)
+}
+
+func contextGetObject(c *srv.Context) *object.Object {
+	return c.MustGet("object").(*object.Object)
+}
+
+func contextSetArchive(c *srv.Context, a *archive.Archive) {
+	c.Set("archive", a)
+}
+
+func contextMustGetArchive(c *srv.Context) *archive.Archive {
+	return c.MustGet("archive").(*archive.Archive)
+}
+
 func contextGetApiKey(c *srv.Context) *apikey.ApiKey {
 	return c.MustGet("apiKey").(*apikey.ApiKey)
 }
@@ -10,9 +10,12 @@ import (
 
 	"github.com/cfichtmueller/srv"
 	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/object"
 	"github.com/cfichtmueller/stor/internal/domain/session"
 	"github.com/cfichtmueller/stor/internal/domain/user"
+	"github.com/cfichtmueller/stor/internal/ec"
 	"github.com/cfichtmueller/stor/internal/ui"
 )
 
@@ -46,6 +49,34 @@ func withBucketFromQuery(c *srv.Context, next srv.Handler) *srv.Response {
 	return next(c)
 }
 
+func withObjectFromQuery(c *srv.Context, next srv.Handler) *srv.Response {
+	v, ok := c.Get("bucket")
+	if !ok {
+		return responseFromError(ec.NoSuchBucket)
+	}
+	b := v.(*bucket.Bucket)
+	o, err := object.FindOne(c, b.Name, c.Query("key"), false)
+	if err != nil {
+		return responseFromError(err)
+	}
+	contextSetObject(c, o)
+	return next(c)
+}
+
+func withArchiveFromQuery(c *srv.Context, next srv.Handler) *srv.Response {
+	v, ok := c.Get("bucket")
+	if !ok {
+		return responseFromError(ec.NoSuchBucket)
+	}
+	b := v.(*bucket.Bucket)
+	a, err := archive.FindOne(c, b.Name, c.Query("key"), c.Query("archive"))
+	if err != nil {
+		return responseFromError(err)
+	}
+	contextSetArchive(c, a)
+	return next(c)
+}
+
 func apiKeyFilter(c *srv.Context, next srv.Handler) *srv.Response {
 	keyId := c.Query("key")
 	if keyId == "" {
@@ -52,23 +52,41 @@ func Configure() *srv.Server {
 	componentsGroup.GET("/buckets-table", renderNode(handleRenderBucketsTable))
 	componentsGroup.GET("/create-api-key-dialog", renderNodeFn(ui.CreateApiKeyDialog))
 	componentsGroup.GET("/create-bucket-dialog", renderNodeFn(ui.CreateBucketDialog))
+	componentsGroup.GET("/create-lifecycle-rule-dialog", renderNode(handleRenderCreateLifecycleRuleDialog), withBucketFromQuery)
+	componentsGroup.GET("/create-webhook-dialog", renderNode(handleRenderCreateWebhookDialog), withBucketFromQuery)
 	componentsGroup.GET("/delete-bucket-dialog", renderNode(handleRenderDeleteBucketDialog), withBucketFromQuery)
 	componentsGroup.GET("/empty-bucket-dialog", renderNode(handleRenderEmptyBucketDialog), withBucketFromQuery)
 	componentsGroup.GET("/dashboard-metrics", renderNode(handleRenderDashboardMetrics))
+	componentsGroup.GET("/rename-object-dialog", renderNode(handleRenderRenameObjectDialog), withBucketFromQuery, withObjectFromQuery)
 	// /c/objects-table
 
 	// r is for rpc
 	r := console.Group("/r", authenticatedFilter, requireHxRequest)
 	r.POST("/api-key", handleRpcCreateApiKey)
 	r.DELETE("/api-key", handleRpcDeleteApiKey, apiKeyFilter)
+	r.PUT("/api-key-policy", handleRpcUpdateApiKeyPolicy, apiKeyFilter)
+	r.POST("/archive", handleRpcCreateArchive)
+	r.DELETE("/archive", handleRpcAbortArchive, withBucketFromQuery, withArchiveFromQuery)
 	r.POST("/bucket", handleRpcCreateBucket)
+	r.POST("/cancel-archive", handleRpcCancelArchive, withBucketFromQuery, withArchiveFromQuery)
+	r.POST("/cancel-job", handleRpcCancelJob)
 	r.DELETE("/bucket", handleRpcDeleteBucket, withBucketFromQuery)
 	r.POST("/change-password", handleRpcChangePassword)
 	r.POST("/logout-session", handleRpcLogoutSession)
 	r.POST("/empty-bucket", handleRpcEmptyBucket, withBucketFromQuery)
+	r.POST("/lifecycle-rule", handleRpcCreateLifecycleRule, withBucketFromQuery)
+	r.DELETE("/lifecycle-rule", handleRpcDeleteLifecycleRule, withBucketFromQuery)
+	r.POST("/rename-object", handleRpcRenameObject, withBucketFromQuery, withObjectFromQuery)
+	r.POST("/retry-archive", handleRpcRetryArchive, withBucketFromQuery, withArchiveFromQuery)
+	r.POST("/retry-job", handleRpcRetryJob)
+	r.POST("/webhook", handleRpcCreateWebhook, withBucketFromQuery)
+	r.DELETE("/webhook", handleRpcDeleteWebhook, withBucketFromQuery)
+	r.POST("/webhook-delivery", handleRpcRedeliverWebhookDelivery, withBucketFromQuery)
+	r.DELETE("/webhook-delivery", handleRpcDiscardWebhookDelivery, withBucketFromQuery)
 
 	console.GET("/open", handleRpcOpenObject, authenticatedFilter)
 	console.GET("/download", handleRpcDownloadObject, authenticatedFilter)
+	console.POST("/download-zip", handleRpcDownloadZip, authenticatedFilter)
 
 	// DELETE /r/bucket
 	// POST /r/invite-user
@@ -80,12 +98,15 @@ func Configure() *srv.Server {
 	uGroup.GET("", handleDashboardPage)
 
 	uGroup.GET("/buckets", handleBucketsPage)
+	uGroup.GET("/search", handleSearchPage)
+	uGroup.GET("/jobs", handleJobsPage)
 
 	uBucketGroup := uGroup.Group("/buckets/{bucketName}", bucketFilter)
 	uBucketGroup.GET("", handleBucketPage)
 	uBucketGroup.GET("/objects", handleBucketObjectsPage)
 	uBucketGroup.GET("/object", handleObjectPage)
 	uBucketGroup.GET("/properties", handleBucketPropertiesPage)
+	uBucketGroup.GET("/archives", handleBucketArchivesPage)
 	uBucketGroup.GET("/settings", handleBucketSettingsPage)
 
 	uAdminGroup := uGroup.Group("/admin")
@@ -5,14 +5,21 @@
 package console
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"github.com/cfichtmueller/srv"
 	"github.com/cfichtmueller/stor/internal/config"
 	"github.com/cfichtmueller/stor/internal/disk"
+	"github.com/cfichtmueller/stor/internal/domain"
 	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
 	"github.com/cfichtmueller/stor/internal/domain/chunk"
+	"github.com/cfichtmueller/stor/internal/domain/job"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+	"github.com/cfichtmueller/stor/internal/domain/notification"
 	"github.com/cfichtmueller/stor/internal/domain/object"
 	"github.com/cfichtmueller/stor/internal/domain/user"
 	"github.com/cfichtmueller/stor/internal/ec"
@@ -57,12 +64,38 @@ func handleBucketPage(c *srv.Context) *srv.Response {
 
 func handleBucketObjectsPage(c *srv.Context) *srv.Response {
 	b := contextGetBucket(c)
+	q := ui.ObjectsQuery{
+		Prefix:         c.Query("prefix"),
+		SortBy:         c.Query("sort-by"),
+		Order:          c.Query("order"),
+		ContentType:    c.Query("content-type"),
+		ModifiedWithin: c.Query("modified-within"),
+	}
+	var objects []ui.ObjectData
+	var err error
+	if q.Filtered() {
+		objects, err = listFilteredObjects(c, b, q)
+	} else {
+		objects, err = listFolder(c, b, q.Prefix)
+	}
+	if err != nil {
+		return responseFromError(err)
+	}
+	return nodeResponseWithShell(c, ui.BucketObjectsPage(ui.BucketObjectsPageData{
+		Bucket:  b,
+		Prefix:  q.Prefix,
+		Query:   q,
+		Objects: objects,
+	}))
+}
+
+// listFolder lists the objects and folders directly under prefix
+func listFolder(ctx context.Context, b *bucket.Bucket, prefix string) ([]ui.ObjectData, error) {
 	delimiter := "/"
-	prefix := c.Query("prefix")
 	prefixLen := len(prefix)
-	r, err := uc.ObjectPrefixSearch(c, b, delimiter, prefix, "", 1000)
+	r, err := uc.ObjectPrefixSearch(ctx, b, delimiter, object.Filter{Prefix: prefix}, "", 1000)
 	if err != nil {
-		return responseFromError(err)
+		return nil, err
 	}
 	bucketLinks := ui.NewBucketLinks(b.Name)
 	objects := make([]ui.ObjectData, 0, len(r.CommonPrefixes)+len(r.Objects)+1)
@@ -80,16 +113,55 @@ func handleBucketObjectsPage(c *srv.Context) *srv.Response {
 	}
 	for _, o := range r.Objects {
 		objects = append(objects, ui.ObjectData{
-			Key:  o.Key[prefixLen:],
-			Size: o.Size,
-			Href: bucketLinks.Object(o.Key),
+			Key:       o.Key[prefixLen:],
+			ObjectKey: o.Key,
+			Size:      o.Size,
+			CreatedAt: o.CreatedAt,
+			Href:      bucketLinks.Object(o.Key),
+			Locked:    o.Locked(),
 		})
 	}
-	return nodeResponseWithShell(c, ui.BucketObjectsPage(ui.BucketObjectsPageData{
-		Bucket:  b,
-		Prefix:  prefix,
-		Objects: objects,
-	}))
+	return objects, nil
+}
+
+// listFilteredObjects lists all objects under the prefix of q that match its filters in the order of q
+func listFilteredObjects(ctx context.Context, b *bucket.Bucket, q ui.ObjectsQuery) ([]ui.ObjectData, error) {
+	f := object.Filter{
+		Prefix:      q.Prefix,
+		ContentType: q.ContentType,
+	}
+	if q.ModifiedWithin != "" {
+		d, err := time.ParseDuration(q.ModifiedWithin)
+		if err != nil {
+			return nil, ec.InvalidArgument
+		}
+		t := domain.TimeNow().Add(-d)
+		f.CreatedAfter = &t
+	}
+	s := object.Sort{Field: object.SortKey, Descending: q.Order == "desc"}
+	switch q.SortBy {
+	case "last-modified":
+		s.Field = object.SortCreatedAt
+	case "size":
+		s.Field = object.SortSize
+	}
+	res, err := object.ListSorted(ctx, b.Name, f, s, nil, 1000)
+	if err != nil {
+		return nil, err
+	}
+	bucketLinks := ui.NewBucketLinks(b.Name)
+	objects := make([]ui.ObjectData, 0, len(res))
+	for _, o := range res {
+		objects = append(objects, ui.ObjectData{
+			Key:       o.Key[len(q.Prefix):],
+			ObjectKey: o.Key,
+			Size:      o.Size,
+			CreatedAt: o.CreatedAt,
+			Href:      bucketLinks.Object(o.Key),
+			Locked:    o.Locked(),
+		})
+	}
+	return objects, nil
 }
 
 func handleBucketPropertiesPage(c *srv.Context) *srv.Response {
@@ -97,9 +169,37 @@ func handleBucketPropertiesPage(c *srv.Context) *srv.Response {
 	return nodeResponseWithShell(c, ui.BucketPropertiesPage(b))
 }
 
+// bucketArchivesPageSize is the number of archives that are listed on the archives page
+const bucketArchivesPageSize = 50
+
+func handleBucketArchivesPage(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	archives, err := archive.List(c, b.Name, c.Query("cursor"), bucketArchivesPageSize)
+	if err != nil {
+		return responseFromError(err)
+	}
+	d := ui.BucketArchivesPageData{Bucket: b, Archives: archives}
+	if len(archives) == bucketArchivesPageSize {
+		d.Cursor = archives[len(archives)-1].ID
+	}
+	return nodeResponseWithShell(c, ui.BucketArchivesPage(d))
+}
+
 func handleBucketSettingsPage(c *srv.Context) *srv.Response {
 	b := contextGetBucket(c)
-	return nodeResponseWithShell(c, ui.BucketSettingsPage(b))
+	rules, err := lifecycle.List(c, b.Name)
+	if err != nil {
+		return responseFromError(err)
+	}
+	webhooks, err := notification.List(c, b.Name)
+	if err != nil {
+		return responseFromError(err)
+	}
+	deadLetters, err := notification.DeadLetters(c, b.Name)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return nodeResponseWithShell(c, ui.BucketSettingsPage(b, rules, webhooks, deadLetters))
 }
 
 func handleObjectPage(c *srv.Context) *srv.Response {
@@ -112,7 +212,11 @@ func handleObjectPage(c *srv.Context) *srv.Response {
 	if err != nil {
 		return responseFromError(err)
 	}
-	return nodeResponseWithShell(c, ui.ObjectPropertiesPage(b, o))
+	tags, err := object.Tags(c, o)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return nodeResponseWithShell(c, ui.ObjectPropertiesPage(b, o, tags))
 }
 
 func handleUsersPage(c *srv.Context) *srv.Response {
@@ -135,3 +239,53 @@ func handleApiKeysPage(c *srv.Context) *srv.Response {
 		Keys: keys,
 	}))
 }
+
+const searchPageSize = 50
+
+func handleSearchPage(c *srv.Context) *srv.Response {
+	d := ui.SearchPageData{Query: c.Query("q")}
+	if d.Query == "" {
+		return nodeResponseWithShell(c, ui.SearchPage(d))
+	}
+	var after *object.SearchCursor
+	if c.Query("after-key") != "" {
+		after = &object.SearchCursor{Bucket: c.Query("after-bucket"), Key: c.Query("after-key")}
+	}
+	results, err := object.Search(c, d.Query, nil, after, searchPageSize+1)
+	if errors.Is(err, ec.InvalidSearchQuery) {
+		d.Error = ec.InvalidSearchQuery.Message
+		return nodeResponseWithShell(c, ui.SearchPage(d))
+	}
+	if err != nil {
+		return responseFromError(err)
+	}
+	if len(results) > searchPageSize {
+		results = results[:searchPageSize]
+		last := results[len(results)-1]
+		d.NextLink = ui.SearchPageLink(d.Query, last.Bucket, last.Key)
+	}
+	d.Results = results
+	return nodeResponseWithShell(c, ui.SearchPage(d))
+}
+
+const jobsPageSize = 100
+
+func handleJobsPage(c *srv.Context) *srv.Response {
+	running, err := job.List(c, job.StateRunning, jobsPageSize)
+	if err != nil {
+		return responseFromError(err)
+	}
+	queued, err := job.List(c, job.StateQueued, jobsPageSize)
+	if err != nil {
+		return responseFromError(err)
+	}
+	failed, err := job.List(c, job.StateFailed, jobsPageSize)
+	if err != nil {
+		return responseFromError(err)
+	}
+	return nodeResponseWithShell(c, ui.JobsPage(ui.JobsPageData{
+		Running: running,
+		Queued:  queued,
+		Failed:  failed,
+	}))
+}
@@ -7,11 +7,15 @@ package console
 import (
 	"fmt"
 	"io"
+	"mime"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/cfichtmueller/srv"
 	"github.com/cfichtmueller/stor/internal/domain/apikey"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
 	"github.com/cfichtmueller/stor/internal/domain/object"
 	"github.com/cfichtmueller/stor/internal/uc"
 	"github.com/cfichtmueller/stor/internal/ui"
@@ -46,6 +50,42 @@ func handleRpcCreateApiKey(c *srv.Context) *srv.Response {
 		HxReswap("outerHTML")
 }
 
+func handleRpcUpdateApiKeyPolicy(c *srv.Context) *srv.Response {
+	key := contextGetApiKey(c)
+	var buckets, prefixes string
+	if err := bindFormData(c, "buckets", &buckets, "prefixes", &prefixes); err != nil {
+		return responseFromError(err)
+	}
+
+	if err := apikey.UpdatePolicy(c, key, apikey.Policy{
+		Actions:  c.Request().Form["actions"],
+		Buckets:  formLines(buckets),
+		Prefixes: formLines(prefixes),
+	}); err != nil {
+		return srv.Respond().
+			HxTrigger(hxTrigger(hxTriggerModel{
+				Toast: newToast("Error", "Failed to update API key: %v", err),
+			}))
+	}
+
+	return srv.Respond().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Event: "apiKeysUpdated",
+			Toast: newToast("Success", "API key updated"),
+		}))
+}
+
+// formLines returns the non-empty lines of a textarea
+func formLines(s string) []string {
+	lines := make([]string, 0)
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
 func handleRpcDeleteApiKey(c *srv.Context) *srv.Response {
 	key := contextGetApiKey(c)
 
@@ -122,3 +162,23 @@ func handleRpcDownloadObject(c *srv.Context) *srv.Response {
 			return object.Write(c, o, w)
 		})
 }
+
+func handleRpcDownloadZip(c *srv.Context) *srv.Response {
+	values := c.FormValues()
+	b, err := bucket.FindOne(c, values.Get("bucket"))
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	d, err := archive.NewDownload(c, b.Name, archive.DownloadCommand{Keys: values["key"]})
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().
+		ContentLength(d.Size()).
+		Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": d.Name})).
+		BodyFn("application/zip", func(w io.Writer) error {
+			return d.Write(c, w)
+		})
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+	"github.com/cfichtmueller/stor/internal/ui"
+)
+
+// handleRpcCreateArchive creates an archive of the objects that are selected on the objects page
+func handleRpcCreateArchive(c *srv.Context) *srv.Response {
+	values := c.FormValues()
+	b, err := bucket.FindOne(c, values.Get("bucket"))
+	if err != nil {
+		return responseFromError(err)
+	}
+	key := values.Get("archive-key")
+	if err := object.ValidateKey(key); err != nil {
+		return responseFromError(err)
+	}
+	t, ok := archive.DetectType(key, "")
+	if !ok {
+		return responseFromError(ec.InvalidArgument)
+	}
+	keys := values["key"]
+	if len(keys) == 0 {
+		return responseFromError(ec.InvalidArgument)
+	}
+	entries := make([]archive.Entry, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, archive.Entry{Key: k, Name: k})
+	}
+
+	id, err := archive.Create(c, archive.CreateCommand{
+		Bucket:    b.Name,
+		Key:       key,
+		Type:      t,
+		CreatedBy: contextMustGetPrincipal(c),
+	})
+	if err != nil {
+		return responseFromError(err)
+	}
+	arch, err := archive.FindOne(c, b.Name, key, id)
+	if err != nil {
+		return responseFromError(err)
+	}
+	if err := archive.AddEntries(c, arch, entries); err != nil {
+		return responseFromError(err)
+	}
+	if err := archive.Complete(c, arch); err != nil {
+		return responseFromError(err)
+	}
+
+	return hxRedirect(c, ui.NewBucketLinks(b.Name).Archives)
+}
+
+func handleRpcCancelArchive(c *srv.Context) *srv.Response {
+	arch := contextMustGetArchive(c)
+	if err := archive.Cancel(c, arch); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().
+		HxRefresh().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Archive cancelled"),
+		}))
+}
+
+func handleRpcRetryArchive(c *srv.Context) *srv.Response {
+	arch := contextMustGetArchive(c)
+	if err := archive.Retry(c, arch); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().
+		HxRefresh().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Archive queued"),
+		}))
+}
+
+func handleRpcAbortArchive(c *srv.Context) *srv.Response {
+	arch := contextMustGetArchive(c)
+	if err := archive.Abort(c, arch); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().
+		HxRefresh().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Archive aborted"),
+		}))
+}
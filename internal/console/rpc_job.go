@@ -0,0 +1,44 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/job"
+)
+
+func handleRpcCancelJob(c *srv.Context) *srv.Response {
+	j, err := job.FindOne(c, c.Query("job"))
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	if err := job.Cancel(c, j); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().
+		HxRefresh().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Job cancelled"),
+		}))
+}
+
+func handleRpcRetryJob(c *srv.Context) *srv.Response {
+	j, err := job.FindOne(c, c.Query("job"))
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	if err := job.Retry(c, j); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().
+		HxRefresh().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Job queued"),
+		}))
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"strconv"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+)
+
+func handleRpcCreateLifecycleRule(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	var prefix, tags, expirationDays, keepVersions, abortIncompleteDays string
+	if err := bindFormData(c,
+		"prefix", &prefix,
+		"tags", &tags,
+		"expirationDays", &expirationDays,
+		"keepVersions", &keepVersions,
+		"abortIncompleteDays", &abortIncompleteDays,
+	); err != nil {
+		return responseFromError(err)
+	}
+
+	ruleTags, err := object.ParseTags(tags)
+	if err == nil {
+		_, err = lifecycle.Add(c, b.Name, lifecycle.RuleCommand{
+			Prefix:              prefix,
+			Tags:                ruleTags,
+			ExpirationDays:      parseNumber(expirationDays),
+			KeepVersions:        parseNumber(keepVersions),
+			AbortIncompleteDays: parseNumber(abortIncompleteDays),
+		})
+	}
+	if err != nil {
+		return srv.Respond().
+			HxTrigger(hxTrigger(hxTriggerModel{
+				Toast: newToast("Error", "Failed to add lifecycle rule: %v", err),
+			}))
+	}
+
+	return srv.Respond().
+		HxRefresh().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Lifecycle rule added"),
+		}))
+}
+
+func handleRpcDeleteLifecycleRule(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	r, err := lifecycle.FindOne(c, b.Name, c.Query("rule"))
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	if err := lifecycle.Delete(c, b.Name, r.ID); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().
+		HxRefresh().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Lifecycle rule deleted"),
+		}))
+}
+
+// parseNumber parses a numeric form value. Empty values are treated as 0, invalid values as -1
+// so that they are rejected by the rule validation.
+func parseNumber(s string) int {
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return -1
+	}
+	return n
+}
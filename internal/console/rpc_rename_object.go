@@ -0,0 +1,33 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/uc"
+	"github.com/cfichtmueller/stor/internal/ui"
+)
+
+func handleRpcRenameObject(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	o := contextGetObject(c)
+	var key string
+	if err := bindFormData(c, "key", &key); err != nil {
+		return responseFromError(err)
+	}
+
+	if err := uc.RenameObject(c, b, o, key); err != nil {
+		return srv.Respond().
+			HxTrigger(hxTrigger(hxTriggerModel{
+				Toast: newToast("Error", "Failed to rename object: %v", err),
+			}))
+	}
+
+	return srv.Respond().
+		HxRedirect(ui.NewBucketLinks(b.Name).Object(o.Key)).
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Object renamed"),
+		}))
+}
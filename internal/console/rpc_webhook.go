@@ -0,0 +1,94 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package console
+
+import (
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/notification"
+)
+
+func handleRpcCreateWebhook(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	var url, prefix, suffix string
+	if err := bindFormData(c,
+		"url", &url,
+		"prefix", &prefix,
+		"suffix", &suffix,
+	); err != nil {
+		return responseFromError(err)
+	}
+
+	if _, err := notification.Add(c, b.Name, notification.WebhookCommand{
+		URL:    url,
+		Events: c.Request().Form["events"],
+		Prefix: prefix,
+		Suffix: suffix,
+	}); err != nil {
+		return srv.Respond().
+			HxTrigger(hxTrigger(hxTriggerModel{
+				Toast: newToast("Error", "Failed to add webhook: %v", err),
+			}))
+	}
+
+	return srv.Respond().
+		HxRefresh().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Webhook added"),
+		}))
+}
+
+func handleRpcDeleteWebhook(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	w, err := notification.FindOne(c, b.Name, c.Query("webhook"))
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	if err := notification.Delete(c, b.Name, w.ID); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().
+		HxRefresh().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Webhook deleted"),
+		}))
+}
+
+func handleRpcRedeliverWebhookDelivery(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	d, err := notification.FindDelivery(c, b.Name, c.Query("delivery"))
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	if err := notification.Redeliver(c, d); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().
+		HxRefresh().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Delivery scheduled"),
+		}))
+}
+
+func handleRpcDiscardWebhookDelivery(c *srv.Context) *srv.Response {
+	b := contextGetBucket(c)
+	d, err := notification.FindDelivery(c, b.Name, c.Query("delivery"))
+	if err != nil {
+		return responseFromError(err)
+	}
+
+	if err := notification.Discard(c, d); err != nil {
+		return responseFromError(err)
+	}
+
+	return srv.Respond().
+		HxRefresh().
+		HxTrigger(hxTrigger(hxTriggerModel{
+			Toast: newToast("Success", "Delivery discarded"),
+		}))
+}
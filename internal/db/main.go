@@ -5,6 +5,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -68,7 +69,32 @@ func Query(query string, args ...any) (*sql.Rows, error) {
 	return db.Query(query, args...)
 }
 
+func QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return db.QueryRowContext(ctx, query, args...)
+}
+
+func QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return db.QueryContext(ctx, query, args...)
+}
+
+// Tx runs f in a transaction. The transaction is committed if f succeeds and rolled back otherwise.
+func Tx(ctx context.Context, f func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	if err := f(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
 func openDb() (*sql.DB, error) {
-	dbUrl := "file:" + path.Join(config.DataDir, "db.s3db?mode=rwc")
+	// transactions take the write lock immediately so that they can't deadlock when a reader becomes a writer
+	dbUrl := "file:" + path.Join(config.DataDir, "db.s3db?mode=rwc&_txlock=immediate")
 	return sql.Open("sqlite3", dbUrl)
 }
@@ -236,6 +236,272 @@ func runMigrations() {
 		}
 		return nil
 	})
+
+	// delta uploads setup
+	m("20261019_add_chunk_staged_at", `ALTER TABLE chunks ADD COLUMN staged_at DATETIME`)
+	m("20261019_add_chunk_staged_index", `CREATE INDEX idx_chunks_rc_staged ON chunks (rc, staged_at)`)
+
+	// prefix moves setup
+	m("20261019_create_moves_table", `CREATE TABLE moves(
+		id CHAR(32) PRIMARY KEY,
+		bucket CHAR(64) NOT NULL,
+		prefix TEXT NOT NULL,
+		destination TEXT NOT NULL,
+		state CHAR(64) NOT NULL,
+		total INT NOT NULL,
+		moved INT NOT NULL,
+		failed INT NOT NULL,
+		created_at DATETIME NOT NULL
+	)`)
+
+	// lifecycle setup
+	m("20261019_add_archive_created_at", `ALTER TABLE archives ADD COLUMN created_at DATETIME`)
+	m("20261019_add_object_created_at_index", `CREATE INDEX idx_objects_bucket_key_created ON objects (bucket, key, created_at)`)
+	m("20261019_create_lifecycle_rules_table", `CREATE TABLE lifecycle_rules(
+		id CHAR(32) PRIMARY KEY,
+		bucket CHAR(64) NOT NULL,
+		prefix TEXT NOT NULL,
+		expiration_days INT NOT NULL,
+		keep_versions INT NOT NULL,
+		abort_incomplete_days INT NOT NULL,
+		created_at DATETIME NOT NULL
+	)`)
+	m("20261019_create_lifecycle_rules_index", `CREATE INDEX idx_lifecycle_rules_bucket ON lifecycle_rules (bucket)`)
+
+	// object expiration setup
+	m("20261019_add_object_expires_at", `ALTER TABLE objects ADD COLUMN expires_at DATETIME`)
+	m("20261019_add_object_expires_at_index", `CREATE INDEX idx_objects_expires_at ON objects (expires_at) WHERE expires_at IS NOT NULL`)
+
+	// object lock setup
+	m("20261019_add_object_retention_mode", `ALTER TABLE objects ADD COLUMN retention_mode TEXT NOT NULL DEFAULT ''`)
+	m("20261019_add_object_retain_until", `ALTER TABLE objects ADD COLUMN retain_until DATETIME`)
+	m("20261019_add_object_legal_hold", `ALTER TABLE objects ADD COLUMN legal_hold INTEGER NOT NULL DEFAULT 0`)
+	m("20261019_add_bucket_lock_mode", `ALTER TABLE buckets ADD COLUMN lock_mode TEXT NOT NULL DEFAULT ''`)
+	m("20261019_add_bucket_lock_days", `ALTER TABLE buckets ADD COLUMN lock_days INT NOT NULL DEFAULT 0`)
+
+	// object tagging setup
+	m("20261019_create_object_tags_table", `CREATE TABLE object_tags(
+		object CHAR(32) NOT NULL,
+		key TEXT NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY (object, key)
+	)`)
+	m("20261019_create_object_tags_index", `CREATE INDEX idx_object_tags_key_value ON object_tags (key, value, object)`)
+	m("20261019_add_lifecycle_rule_tags", `ALTER TABLE lifecycle_rules ADD COLUMN tags TEXT NOT NULL DEFAULT '{}'`)
+
+	// delimiter listing setup
+	m("20261019_add_object_listing_index", `CREATE INDEX idx_objects_bucket_deleted_key ON objects (bucket, is_deleted, key)`)
+
+	// sorted listing setup
+	m("20261019_add_object_created_at_listing_index", `CREATE INDEX idx_objects_bucket_deleted_created_key ON objects (bucket, is_deleted, created_at, key)`)
+	m("20261019_add_object_size_listing_index", `CREATE INDEX idx_objects_bucket_deleted_size_key ON objects (bucket, is_deleted, size, key)`)
+	m("20261019_add_object_content_type_index", `CREATE INDEX idx_objects_bucket_deleted_content_type ON objects (bucket, is_deleted, content_type)`)
+
+	// key search setup
+	m("20261019_create_object_key_trigrams_table", `CREATE TABLE object_key_trigrams(
+		trigram TEXT NOT NULL,
+		object CHAR(32) NOT NULL,
+		PRIMARY KEY (trigram, object)
+	) WITHOUT ROWID`)
+	m("20261019_create_object_key_trigrams_index", `CREATE INDEX idx_object_key_trigrams_object ON object_key_trigrams (object)`)
+	mf("20261019_index_object_keys", func() error {
+		find := Prepare("SELECT id, key FROM objects WHERE id > $1 ORDER BY id LIMIT 1000")
+		insert := Prepare("INSERT OR IGNORE INTO object_key_trigrams (trigram, object) VALUES ($1, $2)")
+		start := ""
+		for {
+			rows, err := find.Query(start)
+			if err != nil {
+				return err
+			}
+			keys := make(map[string]string)
+			for rows.Next() {
+				var id, key string
+				if err := rows.Scan(&id, &key); err != nil {
+					return err
+				}
+				keys[id] = key
+				start = id
+			}
+			if len(keys) == 0 {
+				break
+			}
+			// a transaction per batch avoids a sync per row
+			tx, err := db.Begin()
+			if err != nil {
+				return err
+			}
+			txInsert := tx.Stmt(insert)
+			for id, key := range keys {
+				for _, g := range domain.Trigrams(key) {
+					if _, err := txInsert.Exec(g, id); err != nil {
+						_ = tx.Rollback()
+						return fmt.Errorf("unable to index key of object %s: %w", id, err)
+					}
+				}
+			}
+			if err := tx.Commit(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// change journal setup
+	m("20261019_create_object_changes_table", `CREATE TABLE object_changes(
+		seq INTEGER PRIMARY KEY AUTOINCREMENT,
+		bucket TEXT NOT NULL,
+		key TEXT NOT NULL,
+		type TEXT NOT NULL,
+		version CHAR(32) NOT NULL,
+		etag CHAR(64) NOT NULL,
+		size INT NOT NULL,
+		created_at DATETIME NOT NULL
+	)`)
+	m("20261019_create_object_changes_bucket_index", `CREATE INDEX idx_object_changes_bucket_seq ON object_changes (bucket, seq)`)
+	m("20261019_create_object_changes_created_at_index", `CREATE INDEX idx_object_changes_created_at ON object_changes (created_at)`)
+
+	// webhook setup
+	m("20261019_create_webhooks_table", `CREATE TABLE webhooks(
+		id CHAR(32) PRIMARY KEY,
+		bucket TEXT NOT NULL,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		suffix TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	)`)
+	m("20261019_create_webhooks_bucket_index", `CREATE INDEX idx_webhooks_bucket ON webhooks (bucket)`)
+	m("20261019_create_webhook_deliveries_table", `CREATE TABLE webhook_deliveries(
+		id CHAR(32) PRIMARY KEY,
+		webhook CHAR(32) NOT NULL,
+		bucket TEXT NOT NULL,
+		event TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		state TEXT NOT NULL,
+		attempts INT NOT NULL,
+		next_attempt_at DATETIME NOT NULL,
+		last_error TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`)
+	m("20261019_create_webhook_deliveries_due_index", `CREATE INDEX idx_webhook_deliveries_state_next_attempt ON webhook_deliveries (state, next_attempt_at)`)
+	m("20261019_create_webhook_deliveries_bucket_index", `CREATE INDEX idx_webhook_deliveries_bucket_state ON webhook_deliveries (bucket, state)`)
+	m("20261019_create_webhook_deliveries_webhook_index", `CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (webhook)`)
+
+	// event bus setup
+	m("20261019_create_bus_events_table", `CREATE TABLE bus_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		topic TEXT NOT NULL,
+		subscriber TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		attempts INT NOT NULL,
+		claimed BOOLEAN NOT NULL,
+		dead BOOLEAN NOT NULL,
+		next_attempt_at DATETIME NOT NULL,
+		last_error TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	)`)
+	m("20261019_create_bus_events_due_index", `CREATE INDEX idx_bus_events_due ON bus_events (topic, subscriber, dead, claimed, next_attempt_at)`)
+
+	// jobs setup
+	m("20261019_create_jobs_table", `CREATE TABLE jobs (
+		id CHAR(32) PRIMARY KEY,
+		type TEXT NOT NULL,
+		bucket TEXT NOT NULL,
+		dedupe_key TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		checkpoint TEXT NOT NULL,
+		state TEXT NOT NULL,
+		attempts INT NOT NULL,
+		max_attempts INT NOT NULL,
+		done INT NOT NULL,
+		total INT NOT NULL,
+		error TEXT NOT NULL,
+		cancel_requested BOOLEAN NOT NULL,
+		run_after DATETIME NOT NULL,
+		lease_until DATETIME,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		finished_at DATETIME
+	)`)
+	m("20261019_create_jobs_due_index", `CREATE INDEX idx_jobs_type_state_run_after ON jobs (type, state, run_after)`)
+	m("20261019_create_jobs_dedupe_index", `CREATE INDEX idx_jobs_type_dedupe_key ON jobs (type, dedupe_key)`)
+	m("20261019_create_jobs_state_index", `CREATE INDEX idx_jobs_state_created_at ON jobs (state, created_at)`)
+
+	// archive sources setup
+	m("20261019_add_archive_source", `ALTER TABLE archives ADD COLUMN source TEXT NOT NULL DEFAULT ''`)
+
+	// archive extraction setup
+	m("20261019_create_extract_results_table", `CREATE TABLE extract_results (
+		job CHAR(32) NOT NULL,
+		seq INT NOT NULL,
+		name TEXT NOT NULL,
+		key TEXT NOT NULL,
+		result CHAR(16) NOT NULL,
+		size INT NOT NULL,
+		error TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (job, seq)
+	)`)
+	m("20261019_create_extract_results_created_at_index", `CREATE INDEX idx_extract_results_created_at ON extract_results (created_at)`)
+
+	// zip download setup
+	m("20261019_add_nonce_payload", `ALTER TABLE nonces ADD COLUMN payload TEXT NOT NULL DEFAULT ''`)
+
+	// archive progress setup
+	m("20261019_add_archive_job", `ALTER TABLE archives ADD COLUMN job TEXT NOT NULL DEFAULT ''`)
+	m("20261019_add_archive_entries_done", `ALTER TABLE archives ADD COLUMN entries_done INT NOT NULL DEFAULT 0`)
+	m("20261019_add_archive_entries_total", `ALTER TABLE archives ADD COLUMN entries_total INT NOT NULL DEFAULT 0`)
+	m("20261019_add_archive_entries_skipped", `ALTER TABLE archives ADD COLUMN entries_skipped INT NOT NULL DEFAULT 0`)
+	m("20261019_add_archive_bytes_written", `ALTER TABLE archives ADD COLUMN bytes_written INT NOT NULL DEFAULT 0`)
+	m("20261019_add_archive_failure_reason", `ALTER TABLE archives ADD COLUMN failure_reason TEXT NOT NULL DEFAULT ''`)
+	m("20261019_add_archive_entry_skip_missing", `ALTER TABLE archive_entries ADD COLUMN skip_missing INTEGER NOT NULL DEFAULT 0`)
+
+	// archive history setup
+	m("20261019_add_archive_created_by", `ALTER TABLE archives ADD COLUMN created_by TEXT NOT NULL DEFAULT ''`)
+	m("20261019_add_archive_finished_at", `ALTER TABLE archives ADD COLUMN finished_at DATETIME`)
+	m("20261019_create_archives_bucket_index", `CREATE INDEX idx_archives_bucket_created_at ON archives (bucket, created_at)`)
+
+	// api key policy setup
+	m("20261019_add_api_key_policy", `ALTER TABLE api_keys ADD COLUMN policy TEXT NOT NULL DEFAULT ''`)
+	m("20261019_grant_existing_api_keys", `UPDATE api_keys SET policy = '{"actions":["read","list","write","delete","admin"],"buckets":["*"]}'`)
+
+	// live key uniqueness setup
+	// older live objects with the same key are deleted unless they are locked. Locked objects can't be deleted,
+	// so they are moved to a key of their own.
+	mf("20261019_rename_locked_duplicate_live_objects", func() error {
+		_, err := db.Exec(`UPDATE objects SET key = key || '.duplicate-' || id WHERE id IN (
+			SELECT id FROM (
+				SELECT id, legal_hold, retain_until, ROW_NUMBER() OVER (PARTITION BY bucket, key ORDER BY created_at DESC, id DESC) AS n FROM objects WHERE is_deleted = false
+			) WHERE n > 1 AND (legal_hold != 0 OR retain_until > $1)
+		)`, time.Now().UTC())
+		return err
+	})
+	mf("20261019_delete_duplicate_live_objects", func() error {
+		_, err := db.Exec(`UPDATE objects SET is_deleted = true WHERE id IN (
+			SELECT id FROM (
+				SELECT id, legal_hold, retain_until, ROW_NUMBER() OVER (PARTITION BY bucket, key ORDER BY created_at DESC, id DESC) AS n FROM objects WHERE is_deleted = false
+			) WHERE n > 1 AND legal_hold = 0 AND (retain_until IS NULL OR retain_until <= $1)
+		)`, time.Now().UTC())
+		return err
+	})
+	m("20261019_add_object_live_key_index", `CREATE UNIQUE INDEX idx_objects_bucket_key_live ON objects (bucket, key) WHERE is_deleted = false`)
+
+	// move jobs setup
+	m("20261019_add_move_job", `ALTER TABLE moves ADD COLUMN job CHAR(32) NOT NULL DEFAULT ''`)
+
+	// archive created_at backfill
+	mf("20261019_backfill_archive_created_at", func() error {
+		// archives without a finish time fall back to the creation time of their job. Archives without
+		// either are dated to the epoch, so that they are treated as the oldest archives.
+		_, err := db.Exec(`UPDATE archives SET created_at = COALESCE(
+			finished_at,
+			(SELECT jobs.created_at FROM jobs WHERE jobs.id = archives.job),
+			$1
+		) WHERE created_at IS NULL`, time.Unix(0, 0).UTC())
+		return err
+	})
 }
 
 func m(id, statement string) {
@@ -7,6 +7,7 @@ package apikey
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/cfichtmueller/stor/internal/db"
 	"github.com/cfichtmueller/stor/internal/domain"
 	"github.com/cfichtmueller/stor/internal/ec"
+	"github.com/cfichtmueller/stor/internal/util"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -25,6 +27,7 @@ type ApiKey struct {
 	CreatedAt   time.Time
 	CreatedBy   string
 	ExpiresAt   time.Time
+	Policy      Policy
 }
 
 func (k *ApiKey) KeyMatches(key string) bool {
@@ -37,27 +40,45 @@ func (k *ApiKey) KeyMatches(key string) bool {
 type CreateCommand struct {
 	Description string
 	TTL         time.Duration
+	// Policy grants the key's permissions. A key without a policy is denied everything.
+	Policy Policy
 }
 
 var (
-	createStmt *sql.Stmt
-	listStmt   *sql.Stmt
-	findStmt   *sql.Stmt
-	getStmt    *sql.Stmt
-	updateStmt *sql.Stmt
-	deleteStmt *sql.Stmt
+	createStmt       *sql.Stmt
+	listStmt         *sql.Stmt
+	findStmt         *sql.Stmt
+	getStmt          *sql.Stmt
+	updateStmt       *sql.Stmt
+	updatePolicyStmt *sql.Stmt
+	deleteStmt       *sql.Stmt
 )
 
+var (
+	authCache = util.NewCache()
+	authTTL   = time.Minute
+)
+
+const apiKeyFields = "id, prefix, hash, description, created_at, created_by, expires_at, policy"
+
 func Configure() {
-	createStmt = db.Prepare("INSERT INTO api_keys (id, prefix, hash, description, created_at, created_by, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7)")
-	listStmt = db.Prepare("SELECT * FROM api_keys ORDER BY created_at")
-	findStmt = db.Prepare("SELECT * FROM api_keys WHERE prefix = $1 LIMIT 1")
-	getStmt = db.Prepare("SELECT * FROM api_keys WHERE id = $1 LIMIT 1")
+	createStmt = db.Prepare("INSERT INTO api_keys (" + apiKeyFields + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)")
+	listStmt = db.Prepare("SELECT " + apiKeyFields + " FROM api_keys ORDER BY created_at")
+	findStmt = db.Prepare("SELECT " + apiKeyFields + " FROM api_keys WHERE prefix = $1 LIMIT 1")
+	getStmt = db.Prepare("SELECT " + apiKeyFields + " FROM api_keys WHERE id = $1 LIMIT 1")
 	updateStmt = db.Prepare("UPDATE api_keys SET expires_at = $1 WHERE id = $2")
+	updatePolicyStmt = db.Prepare("UPDATE api_keys SET policy = $1 WHERE id = $2")
 	deleteStmt = db.Prepare("DELETE FROM api_keys WHERE id = $1")
 }
 
 func Create(ctx context.Context, principal string, cmd CreateCommand) (*ApiKey, string, error) {
+	if err := cmd.Policy.Validate(); err != nil {
+		return nil, "", err
+	}
+	policy, err := json.Marshal(cmd.Policy)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to encode policy: %w", err)
+	}
 	now := domain.TimeNow()
 	expiry := now.Add(cmd.TTL)
 	prefix := domain.RandomId()
@@ -76,6 +97,7 @@ func Create(ctx context.Context, principal string, cmd CreateCommand) (*ApiKey,
 		CreatedAt:   now,
 		CreatedBy:   principal,
 		ExpiresAt:   expiry,
+		Policy:      cmd.Policy,
 	}
 
 	if _, err := createStmt.ExecContext(
@@ -87,6 +109,7 @@ func Create(ctx context.Context, principal string, cmd CreateCommand) (*ApiKey,
 		k.CreatedAt,
 		k.CreatedBy,
 		k.ExpiresAt,
+		string(policy),
 	); err != nil {
 		return nil, "", fmt.Errorf("unable to save API key: %w", err)
 	}
@@ -101,39 +124,31 @@ func List(ctx context.Context) ([]*ApiKey, error) {
 	}
 	keys := make([]*ApiKey, 0)
 	for rows.Next() {
-		var k ApiKey
-		if err := rows.Scan(
-			&k.ID,
-			&k.Prefix,
-			&k.hash,
-			&k.Description,
-			&k.CreatedAt,
-			&k.CreatedBy,
-			&k.ExpiresAt,
-		); err != nil {
+		k, err := scanKey(rows)
+		if err != nil {
 			return nil, fmt.Errorf("unable to decode api key: %w", err)
 		}
-		keys = append(keys, &k)
+		keys = append(keys, k)
 	}
 	return keys, nil
 }
 
+// Authenticate finds the api key of a token. Authenticated keys are cached until they are changed or deleted.
 func Authenticate(ctx context.Context, key string) (*ApiKey, error) {
 	if len(key) != 64 {
 		return nil, ec.InvalidCredentials
 	}
+	if v, ok := authCache.Get(key); ok {
+		k := v.(*ApiKey)
+		if k.ExpiresAt.Before(domain.TimeNow()) {
+			return nil, ec.InvalidCredentials
+		}
+		return k, nil
+	}
 	prefix := key[:10]
 
-	var k ApiKey
-	if err := findStmt.QueryRowContext(ctx, prefix).Scan(
-		&k.ID,
-		&k.Prefix,
-		&k.hash,
-		&k.Description,
-		&k.CreatedAt,
-		&k.CreatedBy,
-		&k.ExpiresAt,
-	); err != nil {
+	k, err := scanKey(findStmt.QueryRowContext(ctx, prefix))
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ec.InvalidCredentials
 		}
@@ -148,33 +163,44 @@ func Authenticate(ctx context.Context, key string) (*ApiKey, error) {
 		return nil, ec.InvalidCredentials
 	}
 
-	return &k, nil
+	authCache.SetTTL(key, k, authTTL)
+	return k, nil
 }
 
 func Get(ctx context.Context, id string) (*ApiKey, error) {
-	var k ApiKey
-	if err := getStmt.QueryRowContext(ctx, id).Scan(
-		&k.ID,
-		&k.Prefix,
-		&k.hash,
-		&k.Description,
-		&k.CreatedAt,
-		&k.CreatedBy,
-		&k.ExpiresAt,
-	); err != nil {
+	k, err := scanKey(getStmt.QueryRowContext(ctx, id))
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ec.NoSuchApiKey
 		}
 		return nil, fmt.Errorf("unable to find api key: %v", err)
 	}
 
-	return &k, nil
+	return k, nil
 }
 
 func Update(ctx context.Context, key *ApiKey) error {
 	if _, err := updateStmt.ExecContext(ctx, key.ExpiresAt, key.ID); err != nil {
 		return fmt.Errorf("unable to update api key: %v", err)
 	}
+	uncache(key.ID)
+	return nil
+}
+
+// UpdatePolicy replaces the policy of a key.
+func UpdatePolicy(ctx context.Context, key *ApiKey, policy Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	d, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("unable to encode policy: %w", err)
+	}
+	if _, err := updatePolicyStmt.ExecContext(ctx, string(d), key.ID); err != nil {
+		return fmt.Errorf("unable to update api key: %w", err)
+	}
+	key.Policy = policy
+	uncache(key.ID)
 	return nil
 }
 
@@ -182,5 +208,40 @@ func Delete(ctx context.Context, id string) error {
 	if _, err := deleteStmt.ExecContext(ctx, id); err != nil {
 		return fmt.Errorf("unable to delete api key: %v", err)
 	}
+	uncache(id)
 	return nil
 }
+
+// uncache removes the authenticated tokens of a key from the cache
+func uncache(id string) {
+	authCache.DeleteFunc(func(_ string, v any) bool {
+		return v.(*ApiKey).ID == id
+	})
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanKey(row scanner) (*ApiKey, error) {
+	var k ApiKey
+	var policy string
+	if err := row.Scan(
+		&k.ID,
+		&k.Prefix,
+		&k.hash,
+		&k.Description,
+		&k.CreatedAt,
+		&k.CreatedBy,
+		&k.ExpiresAt,
+		&policy,
+	); err != nil {
+		return nil, err
+	}
+	if policy != "" {
+		if err := json.Unmarshal([]byte(policy), &k.Policy); err != nil {
+			return nil, fmt.Errorf("unable to decode policy: %w", err)
+		}
+	}
+	return &k, nil
+}
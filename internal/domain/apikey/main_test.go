@@ -0,0 +1,50 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apikey
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/config"
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+func TestAuthenticateCache(t *testing.T) {
+	config.DataDir = os.TempDir()
+	db.Configure()
+	Configure()
+	ctx := context.Background()
+
+	k, token, err := Create(ctx, "test", CreateCommand{TTL: time.Hour, Policy: FullAccess()})
+	if err != nil {
+		t.Fatalf("unable to create api key: %v", err)
+	}
+	if _, err := Authenticate(ctx, token); err != nil {
+		t.Fatalf("unable to authenticate: %v", err)
+	}
+
+	readOnly := Policy{Actions: []string{ActionRead}, Buckets: []string{"*"}}
+	if err := UpdatePolicy(ctx, k, readOnly); err != nil {
+		t.Fatalf("unable to update policy: %v", err)
+	}
+	a, err := Authenticate(ctx, token)
+	if err != nil {
+		t.Fatalf("unable to authenticate: %v", err)
+	}
+	if a.Policy.HasAction(ActionWrite) {
+		t.Errorf("expected the updated policy after authentication, got %+v", a.Policy)
+	}
+
+	if err := Delete(ctx, k.ID); err != nil {
+		t.Fatalf("unable to delete api key: %v", err)
+	}
+	if _, err := Authenticate(ctx, token); err != ec.InvalidCredentials {
+		t.Errorf("expected a deleted key to fail with InvalidCredentials, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apikey
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/cfichtmueller/srv"
+)
+
+const (
+	// ActionRead allows to read objects, their metadata and to create nonces and downloads of them
+	ActionRead = "read"
+	// ActionList allows to list buckets, objects, archives, changes and jobs
+	ActionList = "list"
+	// ActionWrite allows to create and change objects and archives
+	ActionWrite = "write"
+	// ActionDelete allows to delete objects
+	ActionDelete = "delete"
+	// ActionAdmin allows to create and delete buckets and to change their configuration
+	ActionAdmin = "admin"
+)
+
+var Actions = []string{ActionRead, ActionList, ActionWrite, ActionDelete, ActionAdmin}
+
+// Policy grants an API key actions on buckets and objects. Everything that isn't granted is denied.
+type Policy struct {
+	Actions []string `json:"actions"`
+	// Buckets are the names of the buckets the actions are granted on. A name may contain * and ? wildcards.
+	Buckets []string `json:"buckets"`
+	// Prefixes restrict the actions to objects with keys that start with one of the prefixes. The actions are
+	// granted on all objects and on the buckets themselves if empty.
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// FullAccess is the policy of API keys that existed before policies were introduced
+func FullAccess() Policy {
+	return Policy{
+		Actions: slices.Clone(Actions),
+		Buckets: []string{"*"},
+	}
+}
+
+func (p Policy) Validate() error {
+	v := srv.RequireMaxLengthSlice("buckets", 100, p.Buckets, nil)
+	v = srv.RequireMaxLengthSlice("prefixes", 100, p.Prefixes, v)
+	for i, a := range p.Actions {
+		v = srv.Require(fmt.Sprintf("actions[%d]", i), srv.ValidationCodeInvalid, "action is not supported", slices.Contains(Actions, a), v)
+	}
+	for i, b := range p.Buckets {
+		_, err := path.Match(b, "")
+		v = srv.Require(fmt.Sprintf("buckets[%d]", i), srv.ValidationCodeInvalid, "bucket must be a name or a pattern", b != "" && err == nil, v)
+	}
+	for i, prefix := range p.Prefixes {
+		v = srv.RequireNotEmptyIndexed("prefixes[%d]", i, prefix, v)
+		v = srv.Require(fmt.Sprintf("prefixes[%d]", i), srv.ValidationCodeInvalid, "prefix must not be longer than 1024 characters", len(prefix) <= 1024, v)
+	}
+	return srv.Validate(v)
+}
+
+// Allows returns true if the policy grants an action on a key or prefix in a bucket. An empty key stands for the
+// whole bucket and is only granted if the policy isn't restricted to prefixes.
+func (p Policy) Allows(action, bucket, key string) bool {
+	if !p.AllowsObjects(action, bucket) {
+		return false
+	}
+	return len(p.Prefixes) == 0 || slices.ContainsFunc(p.Prefixes, func(prefix string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// AllowsObjects returns true if the policy grants an action on some objects of a bucket
+func (p Policy) AllowsObjects(action, bucket string) bool {
+	return p.HasAction(action) && p.AllowsBucket(bucket)
+}
+
+// AllowsBucket returns true if the policy grants actions on a bucket
+func (p Policy) AllowsBucket(bucket string) bool {
+	return slices.ContainsFunc(p.Buckets, func(pattern string) bool {
+		ok, _ := path.Match(pattern, bucket)
+		return ok
+	})
+}
+
+// HasAction returns true if the policy grants an action on any bucket
+func (p Policy) HasAction(action string) bool {
+	return slices.Contains(p.Actions, action)
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package apikey
+
+import "testing"
+
+func TestPolicyAllows(t *testing.T) {
+	scoped := Policy{
+		Actions:  []string{ActionRead, ActionList},
+		Buckets:  []string{"photos-*", "docs"},
+		Prefixes: []string{"public/", "shared/"},
+	}
+	tests := []struct {
+		policy   Policy
+		action   string
+		bucket   string
+		key      string
+		expected bool
+	}{
+		{policy: FullAccess(), action: ActionAdmin, bucket: "any", expected: true},
+		{policy: FullAccess(), action: ActionDelete, bucket: "any", key: "a.txt", expected: true},
+		{policy: Policy{}, action: ActionRead, bucket: "any", key: "a.txt"},
+		{policy: scoped, action: ActionRead, bucket: "photos-2026", key: "public/a.jpg", expected: true},
+		{policy: scoped, action: ActionList, bucket: "docs", key: "shared/", expected: true},
+		{policy: scoped, action: ActionWrite, bucket: "docs", key: "shared/a.txt"},
+		{policy: scoped, action: ActionRead, bucket: "photos", key: "public/a.jpg"},
+		{policy: scoped, action: ActionRead, bucket: "documents", key: "public/a.txt"},
+		{policy: scoped, action: ActionRead, bucket: "docs", key: "private/a.txt"},
+		{policy: scoped, action: ActionList, bucket: "docs", key: ""},
+		{policy: scoped, action: ActionList, bucket: "docs", key: "shared"},
+	}
+	for _, test := range tests {
+		if actual := test.policy.Allows(test.action, test.bucket, test.key); actual != test.expected {
+			t.Errorf("%+v %s %s %s: expected %v, got %v", test.policy, test.action, test.bucket, test.key, test.expected, actual)
+		}
+	}
+
+	if !scoped.AllowsObjects(ActionRead, "docs") {
+		t.Errorf("expected a prefix scoped policy to allow reading some objects of a bucket")
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		policy Policy
+		valid  bool
+	}{
+		{policy: FullAccess(), valid: true},
+		{policy: Policy{}, valid: true},
+		{policy: Policy{Actions: []string{"execute"}}},
+		{policy: Policy{Buckets: []string{""}}},
+		{policy: Policy{Buckets: []string{"photos-["}}},
+		{policy: Policy{Prefixes: []string{""}}},
+	}
+	for _, test := range tests {
+		if err := test.policy.Validate(); (err == nil) != test.valid {
+			t.Errorf("%+v: expected valid to be %v, got %v", test.policy, test.valid, err)
+		}
+	}
+}
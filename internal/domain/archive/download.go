@@ -0,0 +1,155 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+// MaxDownloadEntries is the largest number of objects a download may contain
+var MaxDownloadEntries = 10_000
+
+// DownloadCommand selects the objects of a download by their keys or by a prefix
+type DownloadCommand struct {
+	Keys []string `json:"keys,omitempty"`
+	// Prefix selects all objects under the prefix if there are no keys
+	Prefix string `json:"prefix,omitempty"`
+	// StripPrefix removes the prefix from the entry names
+	StripPrefix bool `json:"stripPrefix,omitempty"`
+}
+
+func (c DownloadCommand) Validate() error {
+	v := srv.RequireMaxLength("prefix", 1024, c.Prefix, nil)
+	v = srv.Require("keys", srv.ValidationCodeInvalid, "keys or a prefix are required", len(c.Keys) > 0 || c.Prefix != "", v)
+	v = srv.Require("prefix", srv.ValidationCodeInvalid, "prefix must not be combined with keys", len(c.Keys) == 0 || c.Prefix == "", v)
+	v = srv.RequireMaxLengthSlice("keys", MaxDownloadEntries, c.Keys, v)
+	for i, key := range c.Keys {
+		v = srv.RequireNotEmptyIndexed("keys[%d]", i, key, v)
+	}
+	return srv.Validate(v)
+}
+
+// Download is a zip archive of objects that is streamed without being stored
+type Download struct {
+	// Name is the file name of the archive
+	Name    string
+	entries []entry
+}
+
+// NewDownload looks up the objects of a download. Returns ec.NoSuchKey if one of the keys doesn't exist and
+// ec.TooManyKeys if the download would contain more than MaxDownloadEntries objects.
+func NewDownload(ctx context.Context, bucketName string, cmd DownloadCommand) (*Download, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	d := &Download{Name: bucketName + ".zip"}
+	if len(cmd.Keys) > 0 {
+		entries, err := findDownloadEntries(ctx, bucketName, cmd.Keys)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+		return d, nil
+	}
+
+	if name := path.Base(strings.TrimSuffix(cmd.Prefix, "/")); name != "." && name != "/" {
+		d.Name = name + ".zip"
+	}
+	s := Source{Prefix: cmd.Prefix, StripPrefix: cmd.StripPrefix}
+	cursor := ""
+	for {
+		objects, err := object.ListFiltered(ctx, bucketName, s.filter(), cursor, entriesBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(objects) == 0 {
+			return d, nil
+		}
+		for _, o := range objects {
+			cursor = o.Key
+			if name := s.name(o.Key); name != "" {
+				d.entries = append(d.entries, entry{name: name, object: o})
+			}
+		}
+		if len(d.entries) > MaxDownloadEntries {
+			return nil, ec.TooManyKeys
+		}
+	}
+}
+
+// findDownloadEntries returns the objects of keys in the order of the keys. Duplicate keys are archived once.
+func findDownloadEntries(ctx context.Context, bucketName string, keys []string) ([]entry, error) {
+	unique := make([]string, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	found := make(map[string]*object.Object, len(unique))
+	for i := 0; i < len(unique); i += entriesBatchSize {
+		objects, err := object.FindMany(ctx, bucketName, unique[i:min(i+entriesBatchSize, len(unique))], false)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objects {
+			found[o.Key] = o
+		}
+	}
+	entries := make([]entry, 0, len(unique))
+	for _, key := range unique {
+		o, ok := found[key]
+		if !ok {
+			return nil, ec.NoSuchKey
+		}
+		entries = append(entries, entry{name: key, object: o})
+	}
+	return entries, nil
+}
+
+// Files returns the number of objects in the download
+func (d *Download) Files() int {
+	return len(d.entries)
+}
+
+// Size returns the length of the zip archive
+func (d *Download) Size() int64 {
+	return StreamSize(d.headers())
+}
+
+func (d *Download) headers() []Header {
+	headers := make([]Header, 0, len(d.entries))
+	for _, e := range d.entries {
+		headers = append(headers, Header{Name: e.name, Size: e.object.Size, ModTime: e.object.CreatedAt})
+	}
+	return headers
+}
+
+// Write streams the zip archive to w. It writes exactly Size bytes unless an object changes in the meantime.
+func (d *Download) Write(ctx context.Context, w io.Writer) error {
+	zw := NewStreamWriter(w)
+	for i, h := range d.headers() {
+		ew, err := zw.Create(h)
+		if err != nil {
+			return fmt.Errorf("unable to create archive entry: %w", err)
+		}
+		if err := object.Write(ctx, d.entries[i].object, ew); err != nil {
+			return fmt.Errorf("unable to write object: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("unable to close archive writer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+)
+
+const (
+	// OverwriteFail stops an extraction when a file would replace an object
+	OverwriteFail = "fail"
+	// OverwriteReplace replaces existing objects with the files of the archive
+	OverwriteReplace = "replace"
+	// OverwriteSkip keeps existing objects
+	OverwriteSkip = "skip"
+
+	ResultCreated  = "created"
+	ResultReplaced = "replaced"
+	ResultSkipped  = "skipped"
+	ResultFailed   = "failed"
+)
+
+var (
+	// MaxExtractEntries is the largest number of files an extracted archive may contain
+	MaxExtractEntries = 100_000
+	// MaxExtractEntrySize is the largest size of a file that is extracted
+	MaxExtractEntrySize int64 = 5 << 30
+	// MaxExtractSize is the largest total size of the files of an extracted archive
+	MaxExtractSize int64 = 50 << 30
+
+	ErrUnsafeName       = errors.New("entry name is not a relative path within the target prefix")
+	ErrTooManyEntries   = errors.New("archive contains too many files")
+	ErrEntryTooLarge    = errors.New("archive contains a file that is too large")
+	ErrExtractTooLarge  = errors.New("files of the archive are too large")
+	resultFields        = "seq, name, key, result, size, error"
+	insertResultStmt    *sql.Stmt
+	listResultsStmt     *sql.Stmt
+	pruneResultsStmt    *sql.Stmt
+	summarizeResultStmt *sql.Stmt
+)
+
+func configureExtraction() {
+	insertResultStmt = db.Prepare("INSERT OR REPLACE INTO extract_results (job, " + resultFields + ", created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)")
+	listResultsStmt = db.Prepare("SELECT " + resultFields + " FROM extract_results WHERE job = $1 AND seq > $2 ORDER BY seq LIMIT $3")
+	pruneResultsStmt = db.Prepare("DELETE FROM extract_results WHERE created_at < $1")
+	summarizeResultStmt = db.Prepare("SELECT result, COUNT(*), TOTAL(size) FROM extract_results WHERE job = $1 GROUP BY result")
+}
+
+// Result is the outcome of the extraction of a file
+type Result struct {
+	// Seq is the position of the file in the archive starting at 1
+	Seq  int
+	Name string
+	// Key is the key of the object the file has been extracted to. Empty if the name of the file is unsafe.
+	Key    string
+	Result string
+	Size   int64
+	Error  string
+}
+
+// Summary counts the files of an extraction by result
+type Summary struct {
+	Files map[string]int64
+	// Bytes is the size of the files that have been extracted
+	Bytes int64
+}
+
+// EntryKey returns the key of the object a file of an archive is extracted to. Returns ErrUnsafeName if the name
+// is absolute or leaves the prefix.
+func EntryKey(prefix, name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || (len(name) > 1 && name[1] == ':') {
+		return "", ErrUnsafeName
+	}
+	parts := make([]string, 0)
+	for _, p := range strings.Split(name, "/") {
+		switch p {
+		case "", ".":
+			continue
+		case "..":
+			return "", ErrUnsafeName
+		}
+		parts = append(parts, p)
+	}
+	if len(parts) == 0 {
+		return "", ErrUnsafeName
+	}
+	key := prefix + strings.Join(parts, "/")
+	if err := object.ValidateKey(key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// ExtractEntry is a file of an archive that is extracted
+type ExtractEntry struct {
+	Header
+	Seq int
+	// Key is the key of the object the file is extracted to
+	Key string
+	// KeyError is the reason why the file has no key
+	KeyError error
+	// Content fails with ErrEntryTooLarge or ErrExtractTooLarge once a limit is exceeded
+	Content io.Reader
+}
+
+// Extractor reads the files of an archive within MaxExtractEntries, MaxExtractEntrySize and MaxExtractSize
+type Extractor struct {
+	r      Reader
+	prefix string
+	seq    int
+	size   int64
+}
+
+func NewExtractor(r Reader, prefix string) *Extractor {
+	return &Extractor{r: r, prefix: prefix}
+}
+
+// Next returns the next file of the archive. Returns io.EOF after the last file and ErrTooManyEntries if
+// the archive contains more than MaxExtractEntries files.
+func (x *Extractor) Next() (*ExtractEntry, error) {
+	h, r, err := x.r.Next()
+	if err != nil {
+		return nil, err
+	}
+	x.seq++
+	if x.seq > MaxExtractEntries {
+		return nil, ErrTooManyEntries
+	}
+	if h.Size > MaxExtractEntrySize {
+		return nil, ErrEntryTooLarge
+	}
+	e := &ExtractEntry{
+		Header:  *h,
+		Seq:     x.seq,
+		Content: &limitedReader{x: x, r: r, remaining: MaxExtractEntrySize},
+	}
+	e.Key, e.KeyError = EntryKey(x.prefix, h.Name)
+	return e, nil
+}
+
+// Resume continues an extraction that has already extracted size bytes, so that MaxExtractSize applies
+// across attempts
+func (x *Extractor) Resume(size int64) {
+	x.size = size
+}
+
+// Size returns the number of bytes that have been extracted
+func (x *Extractor) Size() int64 {
+	return x.size
+}
+
+// limitedReader enforces the size limits of an extraction on the actual content instead of the declared sizes
+type limitedReader struct {
+	x         *Extractor
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	l.x.size += int64(n)
+	if l.remaining < 0 {
+		return n, ErrEntryTooLarge
+	}
+	if l.x.size > MaxExtractSize {
+		return n, ErrExtractTooLarge
+	}
+	return n, err
+}
+
+// RecordResult stores the result of the extraction of a file by a job
+func RecordResult(ctx context.Context, jobId string, r Result) error {
+	if _, err := insertResultStmt.ExecContext(ctx, jobId, r.Seq, r.Name, r.Key, r.Result, r.Size, r.Error, domain.TimeNow()); err != nil {
+		return fmt.Errorf("unable to insert extract result: %w", err)
+	}
+	return nil
+}
+
+// Results lists the results of an extraction job after the file with seq after
+func Results(ctx context.Context, jobId string, after, limit int) ([]*Result, error) {
+	rows, err := listResultsStmt.QueryContext(ctx, jobId, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query extract results: %w", err)
+	}
+	defer rows.Close()
+	results := make([]*Result, 0)
+	for rows.Next() {
+		var r Result
+		if err := rows.Scan(&r.Seq, &r.Name, &r.Key, &r.Result, &r.Size, &r.Error); err != nil {
+			return nil, fmt.Errorf("unable to decode extract result: %w", err)
+		}
+		results = append(results, &r)
+	}
+	return results, nil
+}
+
+// Summarize counts the results of an extraction job
+func Summarize(ctx context.Context, jobId string) (*Summary, error) {
+	rows, err := summarizeResultStmt.QueryContext(ctx, jobId)
+	if err != nil {
+		return nil, fmt.Errorf("unable to summarize extract results: %w", err)
+	}
+	defer rows.Close()
+	s := &Summary{Files: make(map[string]int64)}
+	for rows.Next() {
+		var result string
+		var count int64
+		var size float64
+		if err := rows.Scan(&result, &count, &size); err != nil {
+			return nil, fmt.Errorf("unable to decode extract result summary: %w", err)
+		}
+		s.Files[result] = count
+		if result == ResultCreated || result == ResultReplaced {
+			s.Bytes += int64(size)
+		}
+	}
+	return s, nil
+}
+
+// PruneResults deletes the results that have been recorded before t
+func PruneResults(ctx context.Context, t time.Time) error {
+	if _, err := pruneResultsStmt.ExecContext(ctx, t); err != nil {
+		return fmt.Errorf("unable to prune extract results: %w", err)
+	}
+	return nil
+}
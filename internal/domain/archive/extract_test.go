@@ -0,0 +1,146 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEntryKey(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		name     string
+		expected string
+		err      error
+	}{
+		{prefix: "out/", name: "a.txt", expected: "out/a.txt"},
+		{prefix: "out/", name: "docs/./b.txt", expected: "out/docs/b.txt"},
+		{prefix: "out/", name: "docs//c.txt", expected: "out/docs/c.txt"},
+		{prefix: "out/", name: "docs\\d.txt", expected: "out/docs/d.txt"},
+		{prefix: "", name: "e.txt", expected: "e.txt"},
+		{prefix: "out/", name: "../evil.txt", err: ErrUnsafeName},
+		{prefix: "out/", name: "docs/../../evil.txt", err: ErrUnsafeName},
+		{prefix: "out/", name: "..\\evil.txt", err: ErrUnsafeName},
+		{prefix: "out/", name: "/etc/passwd", err: ErrUnsafeName},
+		{prefix: "out/", name: "C:\\evil.txt", err: ErrUnsafeName},
+		{prefix: "out/", name: "./", err: ErrUnsafeName},
+	}
+	for _, test := range tests {
+		actual, err := EntryKey(test.prefix, test.name)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("%s%s: expected error %v, got %s %v", test.prefix, test.name, test.err, actual, err)
+			}
+			continue
+		}
+		if err != nil || actual != test.expected {
+			t.Errorf("%s%s: expected key %s, got %s %v", test.prefix, test.name, test.expected, actual, err)
+		}
+	}
+
+	if _, err := EntryKey("out/", strings.Repeat("a", 1024)); err == nil {
+		t.Errorf("expected keys longer than 1024 characters to be rejected")
+	}
+}
+
+func TestDetectType(t *testing.T) {
+	tests := []struct {
+		key         string
+		contentType string
+		expected    string
+	}{
+		{key: "a.zip", expected: TypeZip},
+		{key: "a.TAR.GZ", expected: TypeTarGz},
+		{key: "a.tgz", expected: TypeTarGz},
+		{key: "a.tar.zst", expected: TypeTarZst},
+		{key: "a.tar", expected: TypeTar},
+		{key: "a", contentType: "application/zip", expected: TypeZip},
+		{key: "a", contentType: "application/x-tar", expected: TypeTar},
+		{key: "a.txt", contentType: "text/plain", expected: ""},
+	}
+	for _, test := range tests {
+		actual, ok := DetectType(test.key, test.contentType)
+		if actual != test.expected || ok != (test.expected != "") {
+			t.Errorf("%s %s: expected type %q, got %q", test.key, test.contentType, test.expected, actual)
+		}
+	}
+}
+
+func TestExtractorLimits(t *testing.T) {
+	defer func(entries int, entrySize, size int64) {
+		MaxExtractEntries, MaxExtractEntrySize, MaxExtractSize = entries, entrySize, size
+	}(MaxExtractEntries, MaxExtractEntrySize, MaxExtractSize)
+
+	data := writeTestZip(t, map[string]string{"a.txt": "0123456789", "b.txt": "0123456789", "c.txt": "0123456789"})
+	tests := []struct {
+		entries   int
+		entrySize int64
+		size      int64
+		err       error
+	}{
+		{entries: 3, entrySize: 10, size: 30},
+		{entries: 2, entrySize: 10, size: 30, err: ErrTooManyEntries},
+		{entries: 3, entrySize: 9, size: 30, err: ErrEntryTooLarge},
+		{entries: 3, entrySize: 10, size: 25, err: ErrExtractTooLarge},
+	}
+	for _, test := range tests {
+		MaxExtractEntries, MaxExtractEntrySize, MaxExtractSize = test.entries, test.entrySize, test.size
+		err := extractAll(t, data)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%+v: expected error %v, got %v", test, test.err, err)
+		}
+	}
+}
+
+func writeTestZip(t *testing.T, files map[string]string) []byte {
+	f, _ := FindFormat(TypeZip)
+	var buf bytes.Buffer
+	w, err := f.NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("unable to create zip writer: %v", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		ew, err := w.Create(Header{Name: name, Size: int64(len(files[name]))})
+		if err != nil {
+			t.Fatalf("unable to create zip entry: %v", err)
+		}
+		if _, err := io.WriteString(ew, files[name]); err != nil {
+			t.Fatalf("unable to write zip entry: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unable to close zip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// extractAll reads all files of a zip archive and returns the first error
+func extractAll(t *testing.T, data []byte) error {
+	f, _ := FindFormat(TypeZip)
+	r, err := f.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to read zip: %v", err)
+	}
+	defer r.Close()
+	x := NewExtractor(r, "out/")
+	for {
+		e, err := x.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(io.Discard, e.Content); err != nil {
+			return err
+		}
+	}
+}
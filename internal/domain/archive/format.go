@@ -0,0 +1,301 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	TypeTar    = "tar"
+	TypeTarGz  = "tar.gz"
+	TypeTarZst = "tar.zst"
+	TypeZip    = "zip"
+)
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]Format{
+		TypeTar:    tarFormat{},
+		TypeTarGz:  tarFormat{compress: gzipCompressor, decompress: gzipDecompressor, contentType: "application/gzip"},
+		TypeTarZst: tarFormat{compress: zstdCompressor, decompress: zstdDecompressor, contentType: "application/zstd"},
+		TypeZip:    zipFormat{},
+	}
+	// extensions maps the file extensions of archives to their types. Longer extensions come first.
+	extensions = []struct {
+		extension   string
+		archiveType string
+	}{
+		{".tar.gz", TypeTarGz},
+		{".tar.zst", TypeTarZst},
+		{".tgz", TypeTarGz},
+		{".tzst", TypeTarZst},
+		{".tar", TypeTar},
+		{".zip", TypeZip},
+	}
+)
+
+// Format is an archive file format
+type Format interface {
+	// ContentType is the content type of the archive objects
+	ContentType() string
+	NewWriter(w io.Writer) (Writer, error)
+	NewReader(r io.ReaderAt, size int64) (Reader, error)
+}
+
+// Writer writes the entries of an archive
+type Writer interface {
+	// Create adds an entry to the archive. The content of the entry is written to the returned writer
+	// before the next entry is created and must be exactly h.Size bytes long.
+	Create(h Header) (io.Writer, error)
+	// Close finishes the archive. It doesn't close the underlying writer.
+	Close() error
+}
+
+// Reader reads the entries of an archive
+type Reader interface {
+	// Next returns the next file of the archive and a reader of its content. Directories, links and other
+	// special entries are skipped. Returns io.EOF after the last file.
+	Next() (*Header, io.Reader, error)
+	Close() error
+}
+
+// Header describes an entry of an archive
+type Header struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// RegisterFormat makes a format available as an archive type
+func RegisterFormat(archiveType string, f Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[archiveType] = f
+}
+
+// FindFormat returns the format of an archive type. Returns false if the type is not supported.
+func FindFormat(archiveType string) (Format, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	f, ok := formats[archiveType]
+	return f, ok
+}
+
+// DetectType returns the archive type of an object from its key or content type.
+// Returns false if the object is not an archive.
+func DetectType(key, contentType string) (string, bool) {
+	lower := strings.ToLower(key)
+	for _, e := range extensions {
+		if strings.HasSuffix(lower, e.extension) {
+			return e.archiveType, true
+		}
+	}
+	switch contentType {
+	case "application/zip", "application/x-zip-compressed":
+		return TypeZip, true
+	case "application/x-tar":
+		return TypeTar, true
+	}
+	return "", false
+}
+
+type zipFormat struct{}
+
+func (zipFormat) ContentType() string {
+	return "application/zip"
+}
+
+func (zipFormat) NewWriter(w io.Writer) (Writer, error) {
+	return &zipWriter{w: zip.NewWriter(w)}, nil
+}
+
+type zipWriter struct {
+	w *zip.Writer
+}
+
+func (z *zipWriter) Create(h Header) (io.Writer, error) {
+	return z.w.CreateHeader(&zip.FileHeader{
+		Name:     h.Name,
+		Method:   zip.Deflate,
+		Modified: h.ModTime,
+	})
+}
+
+func (z *zipWriter) Close() error {
+	return z.w.Close()
+}
+
+func (zipFormat) NewReader(r io.ReaderAt, size int64) (Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return &zipReader{files: zr.File}, nil
+}
+
+type zipReader struct {
+	files   []*zip.File
+	current io.ReadCloser
+}
+
+func (z *zipReader) Next() (*Header, io.Reader, error) {
+	if err := z.Close(); err != nil {
+		return nil, nil, err
+	}
+	for len(z.files) > 0 {
+		f := z.files[0]
+		z.files = z.files[1:]
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		z.current = r
+		return &Header{Name: f.Name, Size: int64(f.UncompressedSize64), ModTime: f.Modified}, r, nil
+	}
+	return nil, nil, io.EOF
+}
+
+func (z *zipReader) Close() error {
+	if z.current == nil {
+		return nil
+	}
+	err := z.current.Close()
+	z.current = nil
+	return err
+}
+
+// compressor wraps the output of a tar archive
+type compressor func(w io.Writer) (io.WriteCloser, error)
+
+// decompressor wraps the input of a tar archive
+type decompressor func(r io.Reader) (io.ReadCloser, error)
+
+func gzipCompressor(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func zstdCompressor(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
+
+func gzipDecompressor(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func zstdDecompressor(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
+type tarFormat struct {
+	// compress and decompress are nil for uncompressed archives
+	compress    compressor
+	decompress  decompressor
+	contentType string
+}
+
+func (f tarFormat) ContentType() string {
+	if f.contentType == "" {
+		return "application/x-tar"
+	}
+	return f.contentType
+}
+
+func (f tarFormat) NewWriter(w io.Writer) (Writer, error) {
+	t := &tarWriter{}
+	if f.compress != nil {
+		c, err := f.compress(w)
+		if err != nil {
+			return nil, err
+		}
+		t.c = c
+		w = c
+	}
+	t.w = tar.NewWriter(w)
+	return t, nil
+}
+
+type tarWriter struct {
+	w *tar.Writer
+	c io.WriteCloser
+}
+
+func (t *tarWriter) Create(h Header) (io.Writer, error) {
+	if err := t.w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     h.Name,
+		Size:     h.Size,
+		Mode:     0644,
+		ModTime:  h.ModTime,
+	}); err != nil {
+		return nil, err
+	}
+	return t.w, nil
+}
+
+func (t *tarWriter) Close() error {
+	if err := t.w.Close(); err != nil {
+		return err
+	}
+	if t.c != nil {
+		return t.c.Close()
+	}
+	return nil
+}
+
+func (f tarFormat) NewReader(r io.ReaderAt, size int64) (Reader, error) {
+	t := &tarReader{}
+	var in io.Reader = io.NewSectionReader(r, 0, size)
+	if f.decompress != nil {
+		d, err := f.decompress(in)
+		if err != nil {
+			return nil, err
+		}
+		t.d = d
+		in = d
+	}
+	t.r = tar.NewReader(in)
+	return t, nil
+}
+
+type tarReader struct {
+	r *tar.Reader
+	d io.ReadCloser
+}
+
+func (t *tarReader) Next() (*Header, io.Reader, error) {
+	for {
+		h, err := t.r.Next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if !h.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		return &Header{Name: h.Name, Size: h.Size, ModTime: h.ModTime}, t.r, nil
+	}
+}
+
+func (t *tarReader) Close() error {
+	if t.d != nil {
+		return t.d.Close()
+	}
+	return nil
+}
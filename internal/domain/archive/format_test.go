@@ -0,0 +1,142 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type formatTestEntry struct {
+	Header
+	Content string
+}
+
+func TestFormats(t *testing.T) {
+	modTime := time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+	entries := []formatTestEntry{
+		{Header: Header{Name: "docs/readme.txt", ModTime: modTime}, Content: "hello world"},
+		{Header: Header{Name: "empty.txt", ModTime: modTime.Add(-time.Hour)}, Content: ""},
+	}
+	tests := map[string]struct {
+		contentType string
+		read        func(t *testing.T, data []byte) []formatTestEntry
+	}{
+		TypeTar:    {"application/x-tar", readTar},
+		TypeTarGz:  {"application/gzip", readTarGz},
+		TypeTarZst: {"application/zstd", readTarZst},
+		TypeZip:    {"application/zip", readZip},
+	}
+
+	for archiveType, test := range tests {
+		f, ok := FindFormat(archiveType)
+		if !ok {
+			t.Fatalf("expected format %s to be supported", archiveType)
+		}
+		if f.ContentType() != test.contentType {
+			t.Errorf("expected content type of %s to be %s, got %s", archiveType, test.contentType, f.ContentType())
+		}
+		var buf bytes.Buffer
+		w, err := f.NewWriter(&buf)
+		if err != nil {
+			t.Fatalf("unable to create %s writer: %v", archiveType, err)
+		}
+		for _, e := range entries {
+			e.Size = int64(len(e.Content))
+			ew, err := w.Create(e.Header)
+			if err != nil {
+				t.Fatalf("unable to create %s entry: %v", archiveType, err)
+			}
+			if _, err := io.WriteString(ew, e.Content); err != nil {
+				t.Fatalf("unable to write %s entry: %v", archiveType, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("unable to close %s writer: %v", archiveType, err)
+		}
+
+		actual := test.read(t, buf.Bytes())
+		if len(actual) != len(entries) {
+			t.Fatalf("expected %d entries in %s, got %d", len(entries), archiveType, len(actual))
+		}
+		for i, e := range entries {
+			a := actual[i]
+			if a.Name != e.Name || a.Content != e.Content || !a.ModTime.Equal(e.ModTime) {
+				t.Errorf("expected entry %d of %s to be %s %q %v, got %s %q %v", i, archiveType, e.Name, e.Content, e.ModTime, a.Name, a.Content, a.ModTime)
+			}
+		}
+	}
+
+	if _, ok := FindFormat("rar"); ok {
+		t.Errorf("expected rar not to be supported")
+	}
+}
+
+func readTar(t *testing.T, data []byte) []formatTestEntry {
+	return readTarFrom(t, bytes.NewReader(data))
+}
+
+func readTarGz(t *testing.T, data []byte) []formatTestEntry {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unable to read gzip: %v", err)
+	}
+	return readTarFrom(t, r)
+}
+
+func readTarZst(t *testing.T, data []byte) []formatTestEntry {
+	r, err := zstd.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unable to read zstd: %v", err)
+	}
+	defer r.Close()
+	return readTarFrom(t, r)
+}
+
+func readTarFrom(t *testing.T, r io.Reader) []formatTestEntry {
+	tr := tar.NewReader(r)
+	entries := make([]formatTestEntry, 0)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatalf("unable to read tar: %v", err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("unable to read tar entry: %v", err)
+		}
+		entries = append(entries, formatTestEntry{Header: Header{Name: h.Name, Size: h.Size, ModTime: h.ModTime}, Content: string(content)})
+	}
+}
+
+func readZip(t *testing.T, data []byte) []formatTestEntry {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to read zip: %v", err)
+	}
+	entries := make([]formatTestEntry, 0)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("unable to open zip entry: %v", err)
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("unable to read zip entry: %v", err)
+		}
+		entries = append(entries, formatTestEntry{Header: Header{Name: f.Name, ModTime: f.Modified}, Content: string(content)})
+	}
+	return entries
+}
@@ -5,9 +5,9 @@
 package archive
 
 import (
-	"archive/zip"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -15,35 +15,68 @@ import (
 	"time"
 
 	"github.com/cfichtmueller/stor/internal/bus"
+	"github.com/cfichtmueller/stor/internal/config"
 	"github.com/cfichtmueller/stor/internal/db"
 	"github.com/cfichtmueller/stor/internal/domain"
 	"github.com/cfichtmueller/stor/internal/domain/chunk"
+	"github.com/cfichtmueller/stor/internal/domain/job"
 	"github.com/cfichtmueller/stor/internal/domain/object"
 	"github.com/cfichtmueller/stor/internal/ec"
 )
 
 var (
-	//EventCompleted indicates that an archive has been completed. Data is a CompletedEvent
-	EventCompleted       = "archive.completed"
+	// EventCompleted indicates that an archive has been completed
+	EventCompleted       = bus.NewDurableTopic[CompletedEvent]("archive.completed")
 	StatePending         = "pending"
 	StateProcessing      = "processing"
 	StateComplete        = "complete"
 	StateFailed          = "failed"
-	TypeZip              = "zip"
-	archiveFields        = "id, bucket, key, type, state"
+	StateCancelled       = "cancelled"
+	StateAborted         = "aborted"
+	archiveFields        = "id, bucket, key, type, state, source, job, entries_done, entries_total, entries_skipped, bytes_written, failure_reason, created_by, created_at, finished_at"
 	createStmt           *sql.Stmt
 	findOneStmt          *sql.Stmt
 	findOneWithStateStmt *sql.Stmt
 	existsStmt           *sql.Stmt
 	updateStmt           *sql.Stmt
-	deleteStmt           *sql.Stmt
 	insertEntryStmt      *sql.Stmt
 	findEntriesStmt      *sql.Stmt
+	countEntriesStmt     *sql.Stmt
 	deleteEntriesStmt    *sql.Stmt
+	// Finishes an archive if it is in an expected state. Input: state, finished at, id, expected state
+	transitionStmt *sql.Stmt
+	// Aborts an archive that isn't processing. Input: aborted state, finished at, id, pending state, failed state, cancelled state
+	abortStmt *sql.Stmt
+	// Counts the entries that are added to an archive. Input: entries, id
+	addEntriesTotalStmt *sql.Stmt
+	// Lists the archives of a bucket from the newest. Input: bucket, id of the archive to start after or empty, limit
+	listStmt *sql.Stmt
+	// Deletes the entries of archives that finished before a point in time in one of three states. Input: time, states
+	pruneEntriesStmt *sql.Stmt
+	// Deletes archives that finished before a point in time in one of three states. Input: time, states
+	pruneStmt *sql.Stmt
+	// Stores the job that writes an archive. Input: job, id
+	setJobStmt *sql.Stmt
+	// Stores the progress of an archive. Input: entries done, entries total, entries skipped, bytes written, id
+	progressStmt *sql.Stmt
+	// Fails a processing archive. Input: state, failure reason, finished at, id, expected state
+	failStmt *sql.Stmt
+	// Queues a failed or cancelled archive again. Input: processing state, id, failed state, cancelled state
+	retryStmt *sql.Stmt
+	// Finds the archives in a state. Input: state
+	findWithStateStmt *sql.Stmt
+	// Finds pending archives with keys in a range that have been created before a point in time.
+	// Input: bucket, state, created before, key from, key to or '' for no upper bound
+	findStalePendingStmt *sql.Stmt
 	completeMutex        sync.Mutex
-	finishFlag           = true
+	finishJob            job.Type[finishPayload]
 )
 
+// finishPayload is the payload of the job that writes an archive
+type finishPayload struct {
+	ArchiveId string
+}
+
 type CompletedEvent struct {
 	Bucket    string
 	Key       string
@@ -51,16 +84,32 @@ type CompletedEvent struct {
 }
 
 func Configure() {
-	createStmt = db.Prepare("INSERT INTO archives (id, bucket, key, type, state, is_deleted) VALUES ($1, $2, $3, $4, $5, false)")
+	createStmt = db.Prepare("INSERT INTO archives (id, bucket, key, type, state, is_deleted, created_at, source, created_by) VALUES ($1, $2, $3, $4, $5, false, $6, $7, $8)")
 	findOneStmt = db.Prepare("SELECT " + archiveFields + " FROM archives WHERE id = $1 AND is_deleted = $2")
 	findOneWithStateStmt = db.Prepare("SELECT " + archiveFields + " FROM archives WHERE state = $1 AND is_deleted = false LIMIT 1")
 	existsStmt = db.Prepare("SELECT COUNT(*) FROM archives WHERE id = $1 AND bucket = $2 AND key = $3 AND is_deleted = $4")
 	updateStmt = db.Prepare("UPDATE archives SET state = $1, is_deleted = $2 WHERE id = $3")
-	deleteStmt = db.Prepare("DELETE FROM archives WHERE id = $1")
-	insertEntryStmt = db.Prepare("INSERT INTO archive_entries (id, archive, key, name) VALUES ($1, $2, $3, $4)")
-	findEntriesStmt = db.Prepare("SELECT key, name FROM archive_entries WHERE archive = $1 AND name > $2 LIMIT $3")
+	insertEntryStmt = db.Prepare("INSERT INTO archive_entries (id, archive, key, name, skip_missing) VALUES ($1, $2, $3, $4, $5)")
+	findEntriesStmt = db.Prepare("SELECT key, name, skip_missing FROM archive_entries WHERE archive = $1 AND name > $2 ORDER BY name LIMIT $3")
+	countEntriesStmt = db.Prepare("SELECT COUNT(*) FROM archive_entries WHERE archive = $1")
 	deleteEntriesStmt = db.Prepare("DELETE FROM archive_entries WHERE archive = $1")
-
+	findWithStateStmt = db.Prepare("SELECT id FROM archives WHERE state = $1 AND is_deleted = false")
+	findStalePendingStmt = db.Prepare("SELECT id FROM archives WHERE bucket = $1 AND state = $2 AND created_at < $3 AND key >= $4 AND ($5 = '' OR key < $5) AND is_deleted = false LIMIT 1000")
+	transitionStmt = db.Prepare("UPDATE archives SET state = $1, finished_at = $2 WHERE id = $3 AND state = $4")
+	abortStmt = db.Prepare("UPDATE archives SET state = $1, finished_at = $2 WHERE id = $3 AND state IN ($4, $5, $6)")
+	addEntriesTotalStmt = db.Prepare("UPDATE archives SET entries_total = entries_total + $1 WHERE id = $2")
+	listStmt = db.Prepare("SELECT " + archiveFields + " FROM archives WHERE bucket = $1 AND is_deleted = false AND ($2 = '' OR (created_at, id) < (SELECT created_at, id FROM archives WHERE id = $2)) ORDER BY created_at DESC, id DESC LIMIT $3")
+	pruneEntriesStmt = db.Prepare("DELETE FROM archive_entries WHERE archive IN (SELECT id FROM archives WHERE COALESCE(finished_at, created_at) < $1 AND state IN ($2, $3, $4))")
+	pruneStmt = db.Prepare("DELETE FROM archives WHERE COALESCE(finished_at, created_at) < $1 AND state IN ($2, $3, $4)")
+	setJobStmt = db.Prepare("UPDATE archives SET job = $1 WHERE id = $2")
+	progressStmt = db.Prepare("UPDATE archives SET entries_done = $1, entries_total = $2, entries_skipped = $3, bytes_written = $4 WHERE id = $5")
+	failStmt = db.Prepare("UPDATE archives SET state = $1, failure_reason = $2, finished_at = $3 WHERE id = $4 AND state = $5")
+	retryStmt = db.Prepare("UPDATE archives SET state = $1, failure_reason = '', entries_done = 0, entries_skipped = 0, bytes_written = 0, finished_at = NULL WHERE id = $2 AND state IN ($3, $4)")
+
+	configureExtraction()
+
+	finishJob = job.Register("archive.finish", finish, job.Concurrency(config.ArchiveWorkers))
+	go queueProcessing()
 	go worker()
 }
 
@@ -70,25 +119,79 @@ type Archive struct {
 	Key    string
 	Type   string
 	State  string
+	// Source selects the objects of the archive. The archive consists of explicit entries if nil.
+	Source *Source
+	// Job is the id of the job that writes the archive. Empty until the archive is completed.
+	Job string
+	// EntriesDone is the number of entries that have been written or skipped
+	EntriesDone  int64
+	EntriesTotal int64
+	// EntriesSkipped is the number of entries whose objects were missing
+	EntriesSkipped int64
+	// BytesWritten is the size of the archive that has been written so far
+	BytesWritten int64
+	// FailureReason explains why a failed archive couldn't be written
+	FailureReason string
+	// CreatedBy is the principal that created the archive
+	CreatedBy string
+	CreatedAt time.Time
+	// FinishedAt is the time the archive has been completed, aborted, cancelled or has failed
+	FinishedAt *time.Time
 }
 
 type CreateCommand struct {
 	Bucket string
 	Key    string
 	Type   string
+	// Source selects the objects of the archive. Entries are added to the archive before it is completed if nil.
+	Source *Source
+	// CreatedBy is the principal that creates the archive
+	CreatedBy string
 }
 
+// Create creates an archive. An archive with a source is processed right away, other archives are pending
+// until they are completed.
 func Create(ctx context.Context, cmd CreateCommand) (string, error) {
-	if cmd.Type != TypeZip {
+	if _, ok := FindFormat(cmd.Type); !ok {
 		return "", ec.InvalidArgument
 	}
 	id := domain.RandomId()
-	if _, err := createStmt.ExecContext(ctx, id, cmd.Bucket, cmd.Key, cmd.Type, StatePending); err != nil {
+	state := StatePending
+	source := ""
+	if cmd.Source != nil {
+		if err := cmd.Source.Validate(); err != nil {
+			return "", err
+		}
+		b, err := json.Marshal(cmd.Source)
+		if err != nil {
+			return "", fmt.Errorf("unable to encode archive source: %w", err)
+		}
+		state = StateProcessing
+		source = string(b)
+	}
+	if _, err := createStmt.ExecContext(ctx, id, cmd.Bucket, cmd.Key, cmd.Type, state, domain.TimeNow(), source, cmd.CreatedBy); err != nil {
 		return "", fmt.Errorf("unable to create archive record: %w", err)
 	}
+	if cmd.Source != nil {
+		if err := enqueue(ctx, cmd.Bucket, id); err != nil {
+			return "", err
+		}
+	}
 	return id, nil
 }
 
+// enqueue queues the job that writes an archive
+func enqueue(ctx context.Context, bucket, id string) error {
+	j, err := job.Enqueue(ctx, finishJob, bucket, id, finishPayload{ArchiveId: id})
+	if err != nil {
+		return err
+	}
+	if _, err := setJobStmt.ExecContext(ctx, j.ID, id); err != nil {
+		return fmt.Errorf("unable to update archive record: %w", err)
+	}
+	return nil
+}
+
 func Exists(ctx context.Context, bucket, key, id string) (bool, error) {
 	var count int
 	if err := existsStmt.QueryRowContext(ctx, id, bucket, key, false).Scan(&count); err != nil {
@@ -111,19 +214,49 @@ func FindOne(ctx context.Context, bucket, key, id string) (*Archive, error) {
 	return arch, nil
 }
 
+// List lists the archives of a bucket from the newest to the oldest, starting after the archive with the id
+// startAfter if it isn't empty
+func List(ctx context.Context, bucket, startAfter string, limit int) ([]*Archive, error) {
+	rows, err := listStmt.QueryContext(ctx, bucket, startAfter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list archives: %w", err)
+	}
+	defer rows.Close()
+	archives := make([]*Archive, 0)
+	for rows.Next() {
+		a, err := scanRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan archive row: %w", err)
+		}
+		archives = append(archives, a)
+	}
+	return archives, nil
+}
+
 type Entry struct {
 	Key  string `json:"key"`
 	Name string `json:"name"`
+	// SkipMissing leaves the entry out if its object doesn't exist instead of failing the archive
+	SkipMissing bool `json:"skipMissing,omitempty"`
 }
 
+// AddEntries adds entries to a pending archive. Returns ec.ArchiveNotPending if the archive has been completed.
 func AddEntries(ctx context.Context, a *Archive, entries []Entry) error {
-	//TODO: should be bulk insert
-	for _, e := range entries {
-		if _, err := insertEntryStmt.ExecContext(ctx, domain.RandomId(), a.ID, e.Key, e.Name); err != nil {
-			return fmt.Errorf("unable to insert entry record: %w", err)
-		}
+	if a.State != StatePending {
+		return ec.ArchiveNotPending
 	}
-	return nil
+	return db.Tx(ctx, func(tx *sql.Tx) error {
+		stmt := tx.StmtContext(ctx, insertEntryStmt)
+		for _, e := range entries {
+			if _, err := stmt.ExecContext(ctx, domain.RandomId(), a.ID, e.Key, e.Name, e.SkipMissing); err != nil {
+				return fmt.Errorf("unable to insert entry record: %w", err)
+			}
+		}
+		if _, err := tx.StmtContext(ctx, addEntriesTotalStmt).ExecContext(ctx, len(entries), a.ID); err != nil {
+			return fmt.Errorf("unable to update archive record: %w", err)
+		}
+		return nil
+	})
 }
 
 type CompleteResult struct {
@@ -143,120 +276,302 @@ func Complete(ctx context.Context, a *Archive) error {
 	if _, err := updateStmt.ExecContext(ctx, StateProcessing, false, a.ID); err != nil {
 		return fmt.Errorf("unable to update archive record: %w", err)
 	}
-	finishFlag = true
-
-	return nil
+	return enqueue(ctx, a.Bucket, a.ID)
 }
 
+// Abort stops an archive that is pending, has failed or has been cancelled. Returns ec.ArchiveNotAbortable
+// otherwise.
 func Abort(ctx context.Context, a *Archive) error {
-	if a.State != StatePending && a.State != StateFailed {
+	if a.State != StatePending && a.State != StateFailed && a.State != StateCancelled {
 		return ec.ArchiveNotAbortable
 	}
-	return delete(ctx, a.ID)
+	return abort(ctx, a.ID)
 }
 
-func delete(ctx context.Context, id string) error {
-	if _, err := deleteStmt.ExecContext(ctx, id); err != nil {
-		return fmt.Errorf("unable to delete archive record: %w", err)
+// Cancel stops writing a processing archive. Returns ec.ArchiveNotCancellable if the archive isn't processing.
+func Cancel(ctx context.Context, a *Archive) error {
+	res, err := transitionStmt.ExecContext(ctx, StateCancelled, domain.TimeNow(), a.ID, StateProcessing)
+	if err != nil {
+		return fmt.Errorf("unable to update archive record: %w", err)
 	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return ec.ArchiveNotCancellable
+	}
+	if a.Job == "" {
+		return nil
+	}
+	j, err := job.FindOne(ctx, a.Job)
+	if err != nil {
+		if errors.Is(err, ec.NoSuchJob) {
+			return nil
+		}
+		return err
+	}
+	if err := job.Cancel(ctx, j); err != nil && !errors.Is(err, ec.JobNotCancellable) {
+		return err
+	}
+	return nil
+}
 
+// Retry writes a failed or cancelled archive again. Returns ec.ArchiveNotRetryable if the archive hasn't
+// failed or been cancelled.
+func Retry(ctx context.Context, a *Archive) error {
+	res, err := retryStmt.ExecContext(ctx, StateProcessing, a.ID, StateFailed, StateCancelled)
+	if err != nil {
+		return fmt.Errorf("unable to update archive record: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return ec.ArchiveNotRetryable
+	}
+	return enqueue(ctx, a.Bucket, a.ID)
+}
+
+// AbortPending aborts all pending archives of a bucket whose keys start with prefix and that have been created
+// before t. Returns the number of aborted archives.
+func AbortPending(ctx context.Context, bucket, prefix string, t time.Time) (int, error) {
+	rows, err := findStalePendingStmt.QueryContext(ctx, bucket, StatePending, t, prefix, object.PrefixEnd(prefix))
+	if err != nil {
+		return 0, fmt.Errorf("unable to find pending archives: %w", err)
+	}
+	defer rows.Close()
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return 0, fmt.Errorf("unable to scan archive row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("unable to find pending archives: %w", err)
+	}
+	for i, id := range ids {
+		if err := abort(ctx, id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// abort marks an archive as aborted and deletes its entries. The archive is deleted after config.ArchiveRetention.
+func abort(ctx context.Context, id string) error {
+	if _, err := abortStmt.ExecContext(ctx, StateAborted, domain.TimeNow(), id, StatePending, StateFailed, StateCancelled); err != nil {
+		return fmt.Errorf("unable to update archive record: %w", err)
+	}
 	if _, err := deleteEntriesStmt.ExecContext(ctx, id); err != nil {
 		return fmt.Errorf("unable to delete archive entries: %w", err)
 	}
 	return nil
 }
 
+// Prune deletes the archives that have been aborted, cancelled or have failed before t
+func Prune(ctx context.Context, t time.Time) (int64, error) {
+	var n int64
+	err := db.Tx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.StmtContext(ctx, pruneEntriesStmt).ExecContext(ctx, t, StateAborted, StateFailed, StateCancelled); err != nil {
+			return fmt.Errorf("unable to delete archive entries: %w", err)
+		}
+		res, err := tx.StmtContext(ctx, pruneStmt).ExecContext(ctx, t, StateAborted, StateFailed, StateCancelled)
+		if err != nil {
+			return fmt.Errorf("unable to delete archives: %w", err)
+		}
+		n, err = res.RowsAffected()
+		return err
+	})
+	return n, err
+}
+
 func worker() {
-	ticker := time.NewTicker(time.Second)
+	ticker := time.NewTicker(time.Hour)
 	for {
 		<-ticker.C
-		finishArchives()
+		n, err := Prune(context.Background(), domain.TimeNow().Add(-config.ArchiveRetention))
+		if err != nil {
+			slog.Error("unable to prune archives", "error", err)
+			continue
+		}
+		if n > 0 {
+			slog.Info("pruned archives", "archives", n)
+		}
 	}
 }
 
-func finishArchives() {
-	if !finishFlag {
+// queueProcessing queues the archives that have been completed before they were written by jobs
+func queueProcessing() {
+	ctx := context.Background()
+	rows, err := findWithStateStmt.QueryContext(ctx, StateProcessing)
+	if err != nil {
+		slog.Error("unable to find processing archives", "error", err)
 		return
 	}
-	ctx := context.Background()
-	a, err := scanRow(findOneWithStateStmt.QueryRowContext(ctx, StateProcessing))
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			slog.Error("unable to scan archive row", "error", err)
+			return
+		}
+		ids = append(ids, id)
+	}
+	for _, id := range ids {
+		a, err := scanRow(findOneStmt.QueryRowContext(ctx, id, false))
+		if err != nil {
+			slog.Error("unable to find archive", "archive", id, "error", err)
+			continue
+		}
+		if err := enqueue(ctx, a.Bucket, a.ID); err != nil {
+			slog.Error("unable to queue archive", "archive", id, "error", err)
+		}
+	}
+}
+
+// finish writes an archive. The archive fails when the last attempt fails or the failure is permanent.
+// It is cancelled if its job is cancelled.
+func finish(ctx context.Context, r *job.Run, p finishPayload) error {
+	a, err := scanRow(findOneStmt.QueryRowContext(ctx, p.ArchiveId, false))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			finishFlag = false
-			return
+			// the archive has been aborted
+			return nil
 		}
-		slog.Error("unable to query pending archives", "error", err)
-		return
+		return fmt.Errorf("unable to find archive: %w", err)
+	}
+	if a.State != StateProcessing {
+		return nil
+	}
+	err = finishArchive(ctx, r, a)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		cancelled(context.WithoutCancel(ctx), r, a.ID)
+		return err
+	}
+	if r.LastAttempt() || job.IsPermanent(err) {
+		failArchive(ctx, a.ID, err)
 	}
+	return err
+}
 
-	if err := finishArchive(ctx, a); err != nil {
-		slog.Error("unable to finish archive", "archive", a.ID, "error", err)
-		failArchive(ctx, a.ID)
+// cancelled marks an archive as cancelled if its job has been cancelled
+func cancelled(ctx context.Context, r *job.Run, id string) {
+	j, err := job.FindOne(ctx, r.Job().ID)
+	if err != nil {
+		slog.Error("unable to find archive job", "archive", id, "error", err)
 		return
 	}
-
-	finishFlag = true
+	if !j.CancelRequested {
+		return
+	}
+	if _, err := transitionStmt.ExecContext(ctx, StateCancelled, domain.TimeNow(), id, StateProcessing); err != nil {
+		slog.Error("unable to cancel archive", "archive", id, "error", err)
+	}
 }
 
-func finishArchive(ctx context.Context, arch *Archive) error {
+func finishArchive(ctx context.Context, r *job.Run, arch *Archive) error {
+	format, ok := FindFormat(arch.Type)
+	if !ok {
+		return job.Permanent(fmt.Errorf("unsupported archive type %q", arch.Type))
+	}
 	s := NewStats()
+	total, err := countEntries(ctx, arch)
+	if err != nil {
+		return err
+	}
+	if _, err := progressStmt.ExecContext(ctx, 0, total, 0, 0, arch.ID); err != nil {
+		return fmt.Errorf("unable to update archive progress: %w", err)
+	}
 	chunkWriter, err := chunk.NewWriter()
 	if err != nil {
 		return err
 	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = chunkWriter.Abort()
+		}
+	}()
 
-	zipWriter := zip.NewWriter(chunkWriter)
+	archiveWriter, err := format.NewWriter(chunkWriter)
+	if err != nil {
+		return fmt.Errorf("unable to create archive writer: %w", err)
+	}
 
-	startAfter := ""
+	var skipped int64
+	cursor := ""
 	for {
-		rows, err := findEntriesStmt.QueryContext(ctx, arch.ID, startAfter, 1000)
-		if err != nil {
-			return fmt.Errorf("unable to list archive entries: %w", err)
+		if err := ctx.Err(); err != nil {
+			return err
 		}
-		entries := make([]Entry, 0)
-		for rows.Next() {
-			var entry Entry
-			if err := rows.Scan(&entry.Key, &entry.Name); err != nil {
-				return fmt.Errorf("unable to scan entry row: %w", err)
+		var entries []entry
+		entries, cursor, err = nextEntries(ctx, arch, cursor)
+		if err != nil {
+			if errors.Is(err, errMissingEntry) {
+				return job.Permanent(err)
 			}
-			entries = append(entries, entry)
+			return err
 		}
 		if len(entries) == 0 {
 			break
 		}
 
 		for _, e := range entries {
-			o, err := object.FindOne(ctx, arch.Bucket, e.Key, false)
-			if err != nil {
-				if errors.Is(err, ec.NoSuchKey) {
-					failArchive(ctx, arch.ID)
-					return nil
-				}
-				return err
+			if e.object == nil {
+				skipped++
+				continue
 			}
-
-			writer, err := zipWriter.Create(e.Name)
+			writer, err := archiveWriter.Create(Header{
+				Name:    e.name,
+				Size:    e.object.Size,
+				ModTime: e.object.CreatedAt,
+			})
 			if err != nil {
-				return fmt.Errorf("unable to create zip entry: %w", err)
+				return fmt.Errorf("unable to create archive entry: %w", err)
 			}
-			if err := object.Write(ctx, o, writer); err != nil {
+			if err := object.Write(ctx, e.object, writer); err != nil {
 				return fmt.Errorf("unable to write object: %w", err)
 			}
-			startAfter = e.Name
-			s.AddBytes(o.Size)
+			s.AddBytes(e.object.Size)
 		}
 		s.AddFiles(int64(len(entries)))
+		if _, err := progressStmt.ExecContext(ctx, s.Files(), total, skipped, chunkWriter.Size(), arch.ID); err != nil {
+			return fmt.Errorf("unable to update archive progress: %w", err)
+		}
+		if err := r.Progress(ctx, s.Files(), total); err != nil {
+			return err
+		}
+	}
+
+	if err := archiveWriter.Close(); err != nil {
+		return fmt.Errorf("unable to close archive writer: %w", err)
+	}
+	if err := chunkWriter.Close(); err != nil {
+		return fmt.Errorf("unable to close chunk writer: %w", err)
+	}
+	if _, err := progressStmt.ExecContext(ctx, s.Files(), total, skipped, chunkWriter.Size(), arch.ID); err != nil {
+		return fmt.Errorf("unable to update archive progress: %w", err)
 	}
 
-	if err := zipWriter.Close(); err != nil {
-		return fmt.Errorf("unable to close zip writer: %w", err)
+	// the archive may have been cancelled while it was written
+	current, err := scanRow(findOneStmt.QueryRowContext(ctx, arch.ID, false))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("unable to find archive: %w", err)
+	}
+	if current.State != StateProcessing {
+		return nil
 	}
 
 	chunkId, err := chunkWriter.Commit(ctx)
 	if err != nil {
 		return err
 	}
+	committed = true
 
+	unlock := object.LockKey(arch.Bucket, arch.Key)
+	defer unlock()
 	existing, err := object.FindOne(ctx, arch.Bucket, arch.Key, false)
 	if err != nil && !errors.Is(err, ec.NoSuchKey) {
 		return err
@@ -269,19 +584,22 @@ func finishArchive(ctx context.Context, arch *Archive) error {
 
 	if _, err := object.CreateWithChunk(ctx, arch.Bucket, chunkId, object.CreateCommand{
 		Key:         arch.Key,
-		ContentType: "application/zip",
+		ContentType: format.ContentType(),
 		Size:        chunkWriter.Size(),
 	}); err != nil {
 		return err
 	}
 
-	if err := delete(ctx, arch.ID); err != nil {
-		fmt.Errorf("unable to delete archive: %w", err)
+	if _, err := transitionStmt.ExecContext(ctx, StateComplete, domain.TimeNow(), arch.ID, StateProcessing); err != nil {
+		slog.Error("unable to complete archive", "archive", arch.ID, "error", err)
+	}
+	if _, err := deleteEntriesStmt.ExecContext(ctx, arch.ID); err != nil {
+		slog.Error("unable to delete archive entries", "archive", arch.ID, "error", err)
 	}
 
 	slog.Info("finished archive", "archive", arch.ID, "summary", s.Summary())
 
-	bus.Publish(EventCompleted, CompletedEvent{
+	bus.Publish(ctx, EventCompleted, CompletedEvent{
 		Bucket:    arch.Bucket,
 		Key:       arch.Key,
 		ArchiveId: arch.ID,
@@ -290,17 +608,33 @@ func finishArchive(ctx context.Context, arch *Archive) error {
 	return nil
 }
 
-func failArchive(ctx context.Context, id string) {
-	if _, err := updateStmt.ExecContext(ctx, StateFailed, false, id); err != nil {
-		slog.Error("unable to fail archive", "archive", id, "error", err)
+// failArchive fails a processing archive with the reason of err
+func failArchive(ctx context.Context, id string, err error) {
+	if _, ferr := failStmt.ExecContext(ctx, StateFailed, err.Error(), domain.TimeNow(), id, StateProcessing); ferr != nil {
+		slog.Error("unable to fail archive", "archive", id, "error", ferr)
 	}
 }
 
-func scanRow(row *sql.Row) (*Archive, error) {
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRow(row scanner) (*Archive, error) {
 	a := Archive{}
-	if err := row.Scan(&a.ID, &a.Bucket, &a.Key, &a.Type, &a.State); err != nil {
+	var source string
+	var createdAt, finishedAt sql.NullTime
+	if err := row.Scan(&a.ID, &a.Bucket, &a.Key, &a.Type, &a.State, &source, &a.Job, &a.EntriesDone, &a.EntriesTotal, &a.EntriesSkipped, &a.BytesWritten, &a.FailureReason, &a.CreatedBy, &createdAt, &finishedAt); err != nil {
 		return nil, err
 	}
+	a.CreatedAt = createdAt.Time
+	if finishedAt.Valid {
+		a.FinishedAt = &finishedAt.Time
+	}
+	if source != "" {
+		if err := json.Unmarshal([]byte(source), &a.Source); err != nil {
+			return nil, fmt.Errorf("unable to decode archive source: %w", err)
+		}
+	}
 
 	return &a, nil
 }
@@ -0,0 +1,146 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+// entriesBatchSize is the number of entries that are looked up at once while an archive is written
+const entriesBatchSize = 1000
+
+// errMissingEntry indicates that the object of an entry doesn't exist
+var errMissingEntry = errors.New("object of archive entry doesn't exist")
+
+// Source selects the objects of an archive from its bucket instead of explicit entries
+type Source struct {
+	Prefix string `json:"prefix,omitempty"`
+	// Delimiter and Depth restrict the archive to objects with less than Depth delimiters after the prefix.
+	// Depth 1 archives the objects directly under the prefix. All objects under the prefix are archived if Depth is 0.
+	Delimiter string `json:"delimiter,omitempty"`
+	Depth     int    `json:"depth,omitempty"`
+	// Tags contains the tags an object must have
+	Tags map[string]string `json:"tags,omitempty"`
+	// ModifiedSince restricts the archive to objects that have been modified after a point in time if not nil
+	ModifiedSince *time.Time `json:"modifiedSince,omitempty"`
+	// StripPrefix removes the prefix from the entry names
+	StripPrefix bool `json:"stripPrefix,omitempty"`
+}
+
+func (s Source) Validate() error {
+	v := srv.RequireMaxLength("prefix", 1024, s.Prefix, nil)
+	v = srv.Require("depth", srv.ValidationCodeInvalid, "depth must not be negative", s.Depth >= 0, v)
+	v = srv.Require("delimiter", srv.ValidationCodeInvalid, "delimiter is required with a depth", s.Depth == 0 || s.Delimiter != "", v)
+	v = object.ValidateTagsField("tags", s.Tags, v)
+	return srv.Validate(v)
+}
+
+func (s Source) filter() object.Filter {
+	return object.Filter{
+		Prefix:       s.Prefix,
+		Tags:         s.Tags,
+		CreatedAfter: s.ModifiedSince,
+		Delimiter:    s.Delimiter,
+		Depth:        s.Depth,
+	}
+}
+
+// name returns the entry name of an object
+func (s Source) name(key string) string {
+	if s.StripPrefix {
+		return strings.TrimPrefix(key, s.Prefix)
+	}
+	return key
+}
+
+// entry is an object that is written to an archive
+type entry struct {
+	name string
+	// object is nil if the object is missing and the entry is skipped
+	object *object.Object
+}
+
+// countEntries counts the entries of an archive
+func countEntries(ctx context.Context, arch *Archive) (int64, error) {
+	if arch.Source != nil {
+		n, err := object.CountFiltered(ctx, arch.Bucket, arch.Source.filter(), "")
+		return int64(n), err
+	}
+	var total int64
+	if err := countEntriesStmt.QueryRowContext(ctx, arch.ID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("unable to count archive entries: %w", err)
+	}
+	return total, nil
+}
+
+// nextEntries returns the entries of an archive after cursor and the cursor of the following entries.
+// Returns errMissingEntry if an explicit entry refers to an object that doesn't exist, unless the entry
+// skips missing objects.
+func nextEntries(ctx context.Context, arch *Archive, cursor string) ([]entry, string, error) {
+	if arch.Source != nil {
+		return nextSourceEntries(ctx, arch, cursor)
+	}
+	rows, err := findEntriesStmt.QueryContext(ctx, arch.ID, cursor, entriesBatchSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to list archive entries: %w", err)
+	}
+	defer rows.Close()
+	explicit := make([]Entry, 0)
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.Key, &e.Name, &e.SkipMissing); err != nil {
+			return nil, "", fmt.Errorf("unable to scan entry row: %w", err)
+		}
+		explicit = append(explicit, e)
+	}
+	entries := make([]entry, 0, len(explicit))
+	for _, e := range explicit {
+		o, err := object.FindOne(ctx, arch.Bucket, e.Key, false)
+		if err != nil {
+			if !errors.Is(err, ec.NoSuchKey) {
+				return nil, "", err
+			}
+			if !e.SkipMissing {
+				return nil, "", fmt.Errorf("%w: %s", errMissingEntry, e.Key)
+			}
+		}
+		entries = append(entries, entry{name: e.Name, object: o})
+		cursor = e.Name
+	}
+	return entries, cursor, nil
+}
+
+func nextSourceEntries(ctx context.Context, arch *Archive, cursor string) ([]entry, string, error) {
+	for {
+		objects, err := object.ListFiltered(ctx, arch.Bucket, arch.Source.filter(), cursor, entriesBatchSize)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(objects) == 0 {
+			return nil, cursor, nil
+		}
+		entries := make([]entry, 0, len(objects))
+		for _, o := range objects {
+			cursor = o.Key
+			name := arch.Source.name(o.Key)
+			// an earlier version of the archive and the prefix itself are not archived
+			if o.Key == arch.Key || name == "" {
+				continue
+			}
+			entries = append(entries, entry{name: name, object: o})
+		}
+		if len(entries) > 0 {
+			return entries, cursor, nil
+		}
+	}
+}
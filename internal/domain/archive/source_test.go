@@ -0,0 +1,43 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import "testing"
+
+func TestSourceValidate(t *testing.T) {
+	tests := []struct {
+		source Source
+		valid  bool
+	}{
+		{source: Source{}, valid: true},
+		{source: Source{Prefix: "docs/", Delimiter: "/", Depth: 2}, valid: true},
+		{source: Source{Prefix: "docs/", Depth: 1}, valid: false},
+		{source: Source{Delimiter: "/", Depth: -1}, valid: false},
+		{source: Source{Tags: map[string]string{"": "x"}}, valid: false},
+	}
+	for _, test := range tests {
+		err := test.source.Validate()
+		if (err == nil) != test.valid {
+			t.Errorf("%+v: expected valid to be %v, got %v", test.source, test.valid, err)
+		}
+	}
+}
+
+func TestSourceName(t *testing.T) {
+	tests := []struct {
+		source   Source
+		key      string
+		expected string
+	}{
+		{source: Source{Prefix: "docs/"}, key: "docs/2026/a.txt", expected: "docs/2026/a.txt"},
+		{source: Source{Prefix: "docs/", StripPrefix: true}, key: "docs/2026/a.txt", expected: "2026/a.txt"},
+		{source: Source{Prefix: "docs/", StripPrefix: true}, key: "docs/", expected: ""},
+	}
+	for _, test := range tests {
+		if actual := test.source.name(test.key); actual != test.expected {
+			t.Errorf("%+v: expected name of %s to be %s, got %s", test.source, test.key, test.expected, actual)
+		}
+	}
+}
@@ -29,6 +29,10 @@ func (s *Stats) AddBytes(n int64) {
 	s.bytes += n
 }
 
+func (s *Stats) Files() int64 {
+	return s.files
+}
+
 func (s *Stats) Summary() string {
 	dur := time.Since(s.start)
 	kb := s.bytes / 1024
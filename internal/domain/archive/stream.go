@@ -0,0 +1,326 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"time"
+)
+
+// The stream writer writes zip archives without compression. Entries are followed by data descriptors, so
+// the length of an archive only depends on the names and sizes of its entries and is known in advance.
+
+const (
+	zipLocalHeaderLen   = 30
+	zipCentralHeaderLen = 46
+	zipDescriptorLen    = 16
+	zip64DescriptorLen  = 24
+	zipEndLen           = 22
+	zip64EndLen         = 56
+	zip64LocatorLen     = 20
+	zip64ExtraHeaderLen = 4
+
+	zipLocalHeaderSignature   = 0x04034b50
+	zipCentralHeaderSignature = 0x02014b50
+	zipDescriptorSignature    = 0x08074b50
+	zipEndSignature           = 0x06054b50
+	zip64EndSignature         = 0x06064b50
+	zip64LocatorSignature     = 0x07064b50
+	zip64ExtraId              = 0x0001
+
+	// zipFlags marks the sizes as following in a data descriptor and the names as UTF-8
+	zipFlags         = 0x0808
+	zipVersion       = 20
+	zip64Version     = 45
+	zipCreatorUnix   = 3
+	zipFileMode      = 0o100644
+	zipMaxEndRecords = math.MaxUint16
+)
+
+var (
+	// zip64Limit is the smallest size or offset that requires ZIP64 extensions
+	zip64Limit int64 = math.MaxUint32
+
+	errStreamEntrySize = errors.New("archive entry doesn't match its size")
+)
+
+// NewStreamWriter returns a writer of zip archives that are stored without compression. Their length is
+// returned by StreamSize.
+func NewStreamWriter(w io.Writer) Writer {
+	return &streamWriter{w: w, crc: crc32.NewIEEE()}
+}
+
+// StreamSize returns the length of a zip archive written by a stream writer
+func StreamSize(headers []Header) int64 {
+	var offset, directorySize int64
+	for _, h := range headers {
+		directorySize += zipCentralHeaderLength(h, offset)
+		offset += zipLocalHeaderLength(h) + h.Size + zipDescriptorLength(h)
+	}
+	return offset + directorySize + zipEndLength(len(headers), directorySize, offset)
+}
+
+type streamEntry struct {
+	Header
+	offset int64
+	crc    uint32
+}
+
+type streamWriter struct {
+	w       io.Writer
+	offset  int64
+	entries []*streamEntry
+	current *streamEntry
+	crc     hash.Hash32
+	written int64
+}
+
+func (s *streamWriter) Create(h Header) (io.Writer, error) {
+	if err := s.finishEntry(); err != nil {
+		return nil, err
+	}
+	e := &streamEntry{Header: h, offset: s.offset}
+	buf := make([]byte, 0, zipLocalHeaderLength(h))
+	date, tm := zipTime(h.ModTime)
+	buf = binary.LittleEndian.AppendUint32(buf, zipLocalHeaderSignature)
+	buf = binary.LittleEndian.AppendUint16(buf, zipEntryVersion(h, e.offset))
+	buf = binary.LittleEndian.AppendUint16(buf, zipFlags)
+	buf = binary.LittleEndian.AppendUint16(buf, 0) // stored
+	buf = binary.LittleEndian.AppendUint16(buf, tm)
+	buf = binary.LittleEndian.AppendUint16(buf, date)
+	// crc and sizes follow in the data descriptor
+	buf = binary.LittleEndian.AppendUint32(buf, 0)
+	buf = binary.LittleEndian.AppendUint32(buf, zipSize32(h.Size, 0))
+	buf = binary.LittleEndian.AppendUint32(buf, zipSize32(h.Size, 0))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(h.Name)))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(zipLocalExtraLength(h)))
+	buf = append(buf, h.Name...)
+	if h.Size >= zip64Limit {
+		buf = binary.LittleEndian.AppendUint16(buf, zip64ExtraId)
+		buf = binary.LittleEndian.AppendUint16(buf, 16)
+		buf = binary.LittleEndian.AppendUint64(buf, 0)
+		buf = binary.LittleEndian.AppendUint64(buf, 0)
+	}
+	if err := s.write(buf); err != nil {
+		return nil, err
+	}
+	s.entries = append(s.entries, e)
+	s.current = e
+	s.crc.Reset()
+	s.written = 0
+	return &streamEntryWriter{s: s}, nil
+}
+
+// finishEntry writes the data descriptor of the current entry
+func (s *streamWriter) finishEntry() error {
+	e := s.current
+	if e == nil {
+		return nil
+	}
+	s.current = nil
+	if s.written != e.Size {
+		return errStreamEntrySize
+	}
+	e.crc = s.crc.Sum32()
+	buf := make([]byte, 0, zipDescriptorLength(e.Header))
+	buf = binary.LittleEndian.AppendUint32(buf, zipDescriptorSignature)
+	buf = binary.LittleEndian.AppendUint32(buf, e.crc)
+	if e.Size >= zip64Limit {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(e.Size))
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(e.Size))
+	} else {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(e.Size))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(e.Size))
+	}
+	return s.write(buf)
+}
+
+func (s *streamWriter) Close() error {
+	if err := s.finishEntry(); err != nil {
+		return err
+	}
+	directoryOffset := s.offset
+	for _, e := range s.entries {
+		if err := s.writeCentralHeader(e); err != nil {
+			return err
+		}
+	}
+	directorySize := s.offset - directoryOffset
+	records := uint64(len(s.entries))
+
+	buf := make([]byte, 0, zipEndLength(len(s.entries), directorySize, directoryOffset))
+	if zipNeeds64End(len(s.entries), directorySize, directoryOffset) {
+		end64Offset := s.offset
+		buf = binary.LittleEndian.AppendUint32(buf, zip64EndSignature)
+		buf = binary.LittleEndian.AppendUint64(buf, zip64EndLen-12)
+		buf = binary.LittleEndian.AppendUint16(buf, zipCreatorUnix<<8|zip64Version)
+		buf = binary.LittleEndian.AppendUint16(buf, zip64Version)
+		buf = binary.LittleEndian.AppendUint32(buf, 0)
+		buf = binary.LittleEndian.AppendUint32(buf, 0)
+		buf = binary.LittleEndian.AppendUint64(buf, records)
+		buf = binary.LittleEndian.AppendUint64(buf, records)
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(directorySize))
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(directoryOffset))
+
+		buf = binary.LittleEndian.AppendUint32(buf, zip64LocatorSignature)
+		buf = binary.LittleEndian.AppendUint32(buf, 0)
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(end64Offset))
+		buf = binary.LittleEndian.AppendUint32(buf, 1)
+
+		// the values of the end record refer to the ZIP64 end record
+		records = zipMaxEndRecords
+		directorySize = math.MaxUint32
+		directoryOffset = math.MaxUint32
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, zipEndSignature)
+	buf = binary.LittleEndian.AppendUint16(buf, 0)
+	buf = binary.LittleEndian.AppendUint16(buf, 0)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(records))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(records))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(directorySize))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(directoryOffset))
+	buf = binary.LittleEndian.AppendUint16(buf, 0)
+	return s.write(buf)
+}
+
+func (s *streamWriter) writeCentralHeader(e *streamEntry) error {
+	buf := make([]byte, 0, zipCentralHeaderLength(e.Header, e.offset))
+	date, tm := zipTime(e.ModTime)
+	buf = binary.LittleEndian.AppendUint32(buf, zipCentralHeaderSignature)
+	buf = binary.LittleEndian.AppendUint16(buf, zipCreatorUnix<<8|zip64Version)
+	buf = binary.LittleEndian.AppendUint16(buf, zipEntryVersion(e.Header, e.offset))
+	buf = binary.LittleEndian.AppendUint16(buf, zipFlags)
+	buf = binary.LittleEndian.AppendUint16(buf, 0) // stored
+	buf = binary.LittleEndian.AppendUint16(buf, tm)
+	buf = binary.LittleEndian.AppendUint16(buf, date)
+	buf = binary.LittleEndian.AppendUint32(buf, e.crc)
+	buf = binary.LittleEndian.AppendUint32(buf, zipSize32(e.Size, e.Size))
+	buf = binary.LittleEndian.AppendUint32(buf, zipSize32(e.Size, e.Size))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(e.Name)))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(zipCentralExtraLength(e.Header, e.offset)))
+	buf = binary.LittleEndian.AppendUint16(buf, 0) // comment
+	buf = binary.LittleEndian.AppendUint16(buf, 0) // disk
+	buf = binary.LittleEndian.AppendUint16(buf, 0) // internal attributes
+	buf = binary.LittleEndian.AppendUint32(buf, zipFileMode<<16)
+	buf = binary.LittleEndian.AppendUint32(buf, zipSize32(e.offset, e.offset))
+	buf = append(buf, e.Name...)
+	if n := zipCentralExtraLength(e.Header, e.offset); n > 0 {
+		buf = binary.LittleEndian.AppendUint16(buf, zip64ExtraId)
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(n-zip64ExtraHeaderLen))
+		if e.Size >= zip64Limit {
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(e.Size))
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(e.Size))
+		}
+		if e.offset >= zip64Limit {
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(e.offset))
+		}
+	}
+	return s.write(buf)
+}
+
+func (s *streamWriter) write(b []byte) error {
+	n, err := s.w.Write(b)
+	s.offset += int64(n)
+	return err
+}
+
+// streamEntryWriter writes the content of an entry
+type streamEntryWriter struct {
+	s *streamWriter
+}
+
+func (w *streamEntryWriter) Write(b []byte) (int, error) {
+	s := w.s
+	if s.current == nil || s.written+int64(len(b)) > s.current.Size {
+		return 0, errStreamEntrySize
+	}
+	n, err := s.w.Write(b)
+	s.crc.Write(b[:n])
+	s.written += int64(n)
+	s.offset += int64(n)
+	return n, err
+}
+
+func zipLocalHeaderLength(h Header) int64 {
+	return zipLocalHeaderLen + int64(len(h.Name)) + zipLocalExtraLength(h)
+}
+
+// zipLocalExtraLength returns the length of the ZIP64 extra field of a local header. The field contains
+// zero sizes as the sizes follow in the data descriptor.
+func zipLocalExtraLength(h Header) int64 {
+	if h.Size >= zip64Limit {
+		return zip64ExtraHeaderLen + 16
+	}
+	return 0
+}
+
+func zipDescriptorLength(h Header) int64 {
+	if h.Size >= zip64Limit {
+		return zip64DescriptorLen
+	}
+	return zipDescriptorLen
+}
+
+func zipCentralHeaderLength(h Header, offset int64) int64 {
+	return zipCentralHeaderLen + int64(len(h.Name)) + zipCentralExtraLength(h, offset)
+}
+
+// zipCentralExtraLength returns the length of the ZIP64 extra field of a central directory header
+func zipCentralExtraLength(h Header, offset int64) int64 {
+	var n int64
+	if h.Size >= zip64Limit {
+		n += 16
+	}
+	if offset >= zip64Limit {
+		n += 8
+	}
+	if n > 0 {
+		n += zip64ExtraHeaderLen
+	}
+	return n
+}
+
+func zipNeeds64End(records int, directorySize, directoryOffset int64) bool {
+	return records >= zipMaxEndRecords || directorySize >= zip64Limit || directoryOffset >= zip64Limit
+}
+
+func zipEndLength(records int, directorySize, directoryOffset int64) int64 {
+	if zipNeeds64End(records, directorySize, directoryOffset) {
+		return zip64EndLen + zip64LocatorLen + zipEndLen
+	}
+	return zipEndLen
+}
+
+func zipEntryVersion(h Header, offset int64) uint16 {
+	if h.Size >= zip64Limit || offset >= zip64Limit {
+		return zip64Version
+	}
+	return zipVersion
+}
+
+// zipSize32 returns the value of a 32 bit size or offset field. Values that don't fit are stored in the
+// ZIP64 extra field instead.
+func zipSize32(v, value int64) uint32 {
+	if v >= zip64Limit {
+		return math.MaxUint32
+	}
+	return uint32(value)
+}
+
+// zipTime returns the MS-DOS date and time of t in UTC
+func zipTime(t time.Time) (uint16, uint16) {
+	t = t.UTC()
+	if t.Year() < 1980 {
+		t = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	date := uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	tm := uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+	return date, tm
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamWriter(t *testing.T) {
+	defer func(limit int64) { zip64Limit = limit }(zip64Limit)
+
+	modTime := time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+	entries := []formatTestEntry{
+		{Header: Header{Name: "docs/readme.txt", ModTime: modTime}, Content: "hello world"},
+		{Header: Header{Name: "empty.txt", ModTime: modTime}, Content: ""},
+		{Header: Header{Name: "fotos/ä.txt", ModTime: modTime.Add(-time.Hour)}, Content: strings.Repeat("x", 100)},
+	}
+	for i := range entries {
+		entries[i].Size = int64(len(entries[i].Content))
+	}
+
+	// a limit of 50 bytes makes the larger entry, the later offsets and the directory use ZIP64 extensions
+	for _, limit := range []int64{zip64Limit, 50} {
+		zip64Limit = limit
+		var buf bytes.Buffer
+		w := NewStreamWriter(&buf)
+		headers := make([]Header, 0, len(entries))
+		for _, e := range entries {
+			ew, err := w.Create(e.Header)
+			if err != nil {
+				t.Fatalf("unable to create entry: %v", err)
+			}
+			if _, err := io.WriteString(ew, e.Content); err != nil {
+				t.Fatalf("unable to write entry: %v", err)
+			}
+			headers = append(headers, e.Header)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("unable to close writer: %v", err)
+		}
+
+		if size := StreamSize(headers); size != int64(buf.Len()) {
+			t.Errorf("limit %d: expected a size of %d, got %d", limit, buf.Len(), size)
+		}
+		actual := readZip(t, buf.Bytes())
+		if len(actual) != len(entries) {
+			t.Fatalf("limit %d: expected %d entries, got %d", limit, len(entries), len(actual))
+		}
+		for i, e := range entries {
+			a := actual[i]
+			if a.Name != e.Name || a.Content != e.Content || !a.ModTime.Equal(e.ModTime) {
+				t.Errorf("limit %d: expected entry %d to be %s %q %v, got %s %q %v", limit, i, e.Name, e.Content, e.ModTime, a.Name, a.Content, a.ModTime)
+			}
+		}
+	}
+}
+
+func TestStreamWriterEntrySize(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewStreamWriter(&buf)
+	ew, err := w.Create(Header{Name: "a.txt", Size: 3})
+	if err != nil {
+		t.Fatalf("unable to create entry: %v", err)
+	}
+	if _, err := io.WriteString(ew, "abcd"); !errors.Is(err, errStreamEntrySize) {
+		t.Errorf("expected writing too much to fail, got %v", err)
+	}
+	if _, err := io.WriteString(ew, "ab"); err != nil {
+		t.Fatalf("unable to write entry: %v", err)
+	}
+	if err := w.Close(); !errors.Is(err, errStreamEntrySize) {
+		t.Errorf("expected closing a short entry to fail, got %v", err)
+	}
+}
@@ -27,6 +27,10 @@ type Bucket struct {
 	Objects   int64
 	Size      int64
 	CreatedAt time.Time
+	// LockMode is the retention mode that is applied to new objects. Empty if the bucket has no default retention.
+	LockMode string
+	// LockDays is the number of days new objects are retained
+	LockDays int
 }
 
 type Stats struct {
@@ -48,17 +52,20 @@ var (
 	listStmt          *sql.Stmt
 	countStmt         *sql.Stmt
 	deleteStmt        *sql.Stmt
+	// Updates the default retention of a bucket. Input: lock mode, lock days, name
+	updateLockStmt *sql.Stmt
 )
 
 func Configure() {
 	createStmt = db.Prepare("INSERT INTO buckets (name, objects, size, created_at, created_by) VALUES ($1, $2, $3, $4, $5)")
-	findManyStmt = db.Prepare("SELECT name, objects, size, created_at FROM buckets ORDER BY name ASC")
-	findOneStmt = db.Prepare("SELECT name, objects, size, created_at FROM buckets WHERE name = $1 LIMIT 1")
+	findManyStmt = db.Prepare("SELECT name, objects, size, created_at, lock_mode, lock_days FROM buckets ORDER BY name ASC")
+	findOneStmt = db.Prepare("SELECT name, objects, size, created_at, lock_mode, lock_days FROM buckets WHERE name = $1 LIMIT 1")
 	updateStmt = db.Prepare("UPDATE buckets SET objects = $1, size = $2 WHERE name = $3")
 	statsStmt = db.Prepare("SELECT COUNT(*) AS count, TOTAL(objects) AS objects from buckets")
-	listStmt = db.Prepare("SELECT name, objects, size, created_at FROM buckets WHERE name > $1 ORDER BY name LIMIT $2")
+	listStmt = db.Prepare("SELECT name, objects, size, created_at, lock_mode, lock_days FROM buckets WHERE name > $1 ORDER BY name LIMIT $2")
 	countStmt = db.Prepare("SELECT COUNT(*) FROM buckets WHERE name > $1")
 	deleteStmt = db.Prepare("DELETE FROM buckets WHERE name = $1")
+	updateLockStmt = db.Prepare("UPDATE buckets SET lock_mode = $1, lock_days = $2 WHERE name = $3")
 }
 
 func ValidateName(name string) error {
@@ -108,6 +115,8 @@ func FindOne(ctx context.Context, name string) (*Bucket, error) {
 			&b.Objects,
 			&b.Size,
 			&b.CreatedAt,
+			&b.LockMode,
+			&b.LockDays,
 		); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ec.NoSuchBucket
@@ -132,6 +141,17 @@ func Save(ctx context.Context, b *Bucket) error {
 	return nil
 }
 
+// SetDefaultRetention sets the retention that is applied to new objects of a bucket.
+// An empty mode removes the default retention.
+func SetDefaultRetention(ctx context.Context, b *Bucket, mode string, days int) error {
+	if _, err := updateLockStmt.ExecContext(ctx, mode, days, b.Name); err != nil {
+		return fmt.Errorf("unable to update bucket default retention: %w", err)
+	}
+	b.LockMode = mode
+	b.LockDays = days
+	return nil
+}
+
 func Delete(ctx context.Context, name string) error {
 	if _, err := deleteStmt.ExecContext(ctx, name); err != nil {
 		return fmt.Errorf("unable to delete bucket: %w", err)
@@ -151,6 +171,8 @@ func decodeRows(rows *sql.Rows, err error) ([]*Bucket, error) {
 			&b.Objects,
 			&b.Size,
 			&b.CreatedAt,
+			&b.LockMode,
+			&b.LockDays,
 		); err != nil {
 			return nil, fmt.Errorf("unable to scan row: %w", err)
 		}
@@ -18,11 +18,14 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cfichtmueller/stor/internal/config"
 	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
 )
 
 type Chunk struct {
@@ -38,6 +41,7 @@ type Stats struct {
 
 var (
 	ErrNotFound                = fmt.Errorf("chunk not found")
+	ErrHashMismatch            = fmt.Errorf("chunk hash mismatch")
 	tempDir                    string
 	createStmt                 *sql.Stmt
 	findOneStmt                *sql.Stmt
@@ -46,7 +50,18 @@ var (
 	increaseReferenceCountStmt *sql.Stmt
 	deleteStmt                 *sql.Stmt
 	statsStmt                  *sql.Stmt
-	writeMutex                 sync.Mutex
+	// Creates a chunk without references. Input: chunk id, size, staged at
+	createStagedStmt *sql.Stmt
+	// Finds staged chunks that have not been referenced in time. Input: staged before
+	findStaleStagedStmt *sql.Stmt
+	// Counts a chunk if it is unreferenced and has been staged before a point in time. Input: chunk id, staged before
+	countStaleStagedStmt *sql.Stmt
+	// Restarts the staging time of an unreferenced chunk. Input: chunk id, staged at
+	restageStmt *sql.Stmt
+	writeMutex  sync.Mutex
+	// StagedChunkTTL is the time a staged chunk may stay unreferenced before it is purged
+	StagedChunkTTL = time.Hour
+	hashPattern    = regexp.MustCompile("^[a-f0-9]{64}$")
 )
 
 func Configure() {
@@ -80,6 +95,17 @@ func Configure() {
 	increaseReferenceCountStmt = db.Prepare("UPDATE chunks SET rc = rc + 1 WHERE id = ?")
 	deleteStmt = db.Prepare("DELETE FROM chunks WHERE id = $1")
 	statsStmt = db.Prepare("SELECT COUNT(*) AS count, TOTAL(size) as size FROM chunks")
+	createStagedStmt = db.Prepare("INSERT INTO chunks (id, size, rc, staged_at) VALUES ($1, $2, 0, $3)")
+	findStaleStagedStmt = db.Prepare("SELECT id FROM chunks WHERE rc = 0 AND staged_at < $1 LIMIT 1000")
+	countStaleStagedStmt = db.Prepare("SELECT COUNT(*) FROM chunks WHERE id = $1 AND rc = 0 AND staged_at < $2")
+	restageStmt = db.Prepare("UPDATE chunks SET staged_at = $2 WHERE id = $1 AND rc = 0")
+
+	go worker()
+}
+
+// TempFile creates a file in the temp directory. Temp files are removed when the server starts.
+func TempFile() (*os.File, error) {
+	return os.CreateTemp(tempDir, "tmp-*")
 }
 
 func Check() bool {
@@ -235,8 +261,28 @@ func Delete(ctx context.Context, id string) error {
 		return DecreaseReferenceCount(ctx, id)
 	}
 
-	if _, err := deleteStmt.ExecContext(ctx, c.ID); err != nil {
-		return fmt.Errorf("unable to delete chunk %s: %w", c.ID, err)
+	return remove(ctx, c.ID)
+}
+
+// deleteStaged deletes a chunk if it is still unreferenced and hasn't been staged again since before
+func deleteStaged(ctx context.Context, id string, before time.Time) error {
+	writeMutex.Lock()
+	defer writeMutex.Unlock()
+
+	var count int
+	if err := countStaleStagedStmt.QueryRowContext(ctx, id, before).Scan(&count); err != nil {
+		return fmt.Errorf("unable to count staged chunks: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	return remove(ctx, id)
+}
+
+func remove(ctx context.Context, id string) error {
+	if _, err := deleteStmt.ExecContext(ctx, id); err != nil {
+		return fmt.Errorf("unable to delete chunk %s: %w", id, err)
 	}
 	folder := id[:2]
 	filename := id[2:]
@@ -246,6 +292,97 @@ func Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// IsValidId reports whether id is a well-formed chunk id (a hex encoded sha256 hash)
+func IsValidId(id string) bool {
+	return hashPattern.MatchString(id)
+}
+
+// Stage stores data as an unreferenced chunk. The chunk must be referenced using Reference
+// within StagedChunkTTL, otherwise it is purged. Staging data that is already stored is a no-op.
+// Returns ErrHashMismatch if the data does not hash to id.
+func Stage(ctx context.Context, id string, data []byte) error {
+	hash, err := computeHash(data)
+	if err != nil {
+		return err
+	}
+	if hash != id {
+		return ErrHashMismatch
+	}
+
+	writeMutex.Lock()
+	defer writeMutex.Unlock()
+
+	c, err := find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c != nil {
+		if c.References == 0 {
+			// the chunk must not be purged before the client that staged it again references it
+			if _, err := restageStmt.ExecContext(ctx, id, domain.TimeNow()); err != nil {
+				return fmt.Errorf("unable to persist staged chunk: %w", err)
+			}
+		}
+		return nil
+	}
+
+	filename, err := prepareChunkFile(id)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, data, 0700); err != nil {
+		return err
+	}
+	if _, err := createStagedStmt.ExecContext(ctx, id, len(data), domain.TimeNow()); err != nil {
+		return fmt.Errorf("unable to persist staged chunk: %w", err)
+	}
+	return nil
+}
+
+// Reference increases the reference count of all given chunks. If any of the chunks does not
+// exist, no reference count is changed and the ids of the missing chunks are returned.
+func Reference(ctx context.Context, ids []string) ([]string, error) {
+	writeMutex.Lock()
+	defer writeMutex.Unlock()
+
+	missing := make([]string, 0)
+	for _, id := range ids {
+		c, err := find(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return missing, nil
+	}
+
+	for _, id := range ids {
+		if err := IncreaseReferenceCount(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
+
+// Size returns the total size of the given chunks. Returns ErrNotFound if one of the chunks does not exist.
+func Size(ctx context.Context, ids []string) (int64, error) {
+	var size int64
+	for _, id := range ids {
+		c, err := find(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		if c == nil {
+			return 0, ErrNotFound
+		}
+		size += int64(c.Size)
+	}
+	return size, nil
+}
+
 func IncreaseReferenceCount(ctx context.Context, chunkId string) error {
 	if _, err := increaseReferenceCountStmt.ExecContext(ctx, chunkId); err != nil {
 		return fmt.Errorf("unable to increase reference count for chunk %s: %w", chunkId, err)
@@ -320,6 +457,50 @@ func update(ctx context.Context, c *Chunk) error {
 	return nil
 }
 
+func worker() {
+	ticker := time.NewTicker(time.Minute)
+	for {
+		<-ticker.C
+		purgeStaged()
+	}
+}
+
+// purgeStaged deletes staged chunks that have not been referenced within StagedChunkTTL
+func purgeStaged() {
+	ctx := context.Background()
+	before := domain.TimeNow().Add(-StagedChunkTTL)
+	ids, err := findStaleStaged(ctx, before)
+	if err != nil {
+		slog.Error("unable to find stale staged chunks", "error", err)
+		return
+	}
+	for _, id := range ids {
+		if err := deleteStaged(ctx, id, before); err != nil {
+			slog.Error("unable to purge staged chunk", "chunk", id, "error", err)
+		}
+	}
+	if len(ids) > 0 {
+		slog.Info("purged staged chunks", "chunks", len(ids))
+	}
+}
+
+func findStaleStaged(ctx context.Context, before time.Time) ([]string, error) {
+	rows, err := findStaleStagedStmt.QueryContext(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("unable to decode chunk row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 func computeHash(data []byte) (string, error) {
 	hash := sha256.New()
 	if _, err := hash.Write(data); err != nil {
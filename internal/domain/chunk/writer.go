@@ -95,3 +95,9 @@ func (w *Writer) Commit(ctx context.Context) (string, error) {
 func (w *Writer) Size() int64 {
 	return w.size
 }
+
+// Abort discards the data that has been written
+func (w *Writer) Abort() error {
+	_ = w.backing.Close()
+	return os.Remove(w.filename)
+}
@@ -0,0 +1,372 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package job runs persistent background jobs. A job is leased by the process that runs it. The lease is
+// renewed while the job runs, so a job whose process crashed is picked up again once its lease expires.
+// Handlers must therefore be safe to run again; they can store a checkpoint to resume where they left off.
+package job
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+const (
+	StateQueued    = "queued"
+	StateRunning   = "running"
+	StateComplete  = "complete"
+	StateFailed    = "failed"
+	StateCancelled = "cancelled"
+)
+
+var (
+	// PollInterval is the time between two looks for due jobs
+	PollInterval = time.Second
+	// LeaseDuration is the time after which a running job is taken over if its lease isn't renewed
+	LeaseDuration = time.Minute
+	// RetryDelay is the delay before the first retry of a failed job. It doubles with every attempt.
+	RetryDelay = 10 * time.Second
+	// MaxRetryDelay is the longest delay between two attempts
+	MaxRetryDelay = time.Hour
+	// Retention is the time finished jobs are kept for inspection
+	Retention = 7 * 24 * time.Hour
+
+	jobFields   = "id, type, bucket, dedupe_key, payload, checkpoint, state, attempts, max_attempts, done, total, error, cancel_requested, run_after, lease_until, created_at, updated_at, finished_at"
+	createStmt  *sql.Stmt
+	findOneStmt *sql.Stmt
+	// Finds an unfinished job of a type by its dedupe key. Input: type, key
+	findActiveStmt *sql.Stmt
+	// Lists the jobs in a state. Input: state, limit
+	listStmt *sql.Stmt
+	// Claims the next due job of a type. Input: lease until, now, type
+	claimStmt *sql.Stmt
+	// Renews the lease of a running job. Attempts fences off runs whose lease has been taken over. Input: lease until, id, attempts
+	renewStmt *sql.Stmt
+	// Stores the progress of a running job. Input: done, total, now, id
+	progressStmt *sql.Stmt
+	// Stores the checkpoint and progress of a running job. Input: checkpoint, done, total, now, id
+	checkpointStmt *sql.Stmt
+	// Finishes a job. Input: state, error, now, id, attempts
+	finishStmt *sql.Stmt
+	// Schedules the next attempt of a job. Input: error, run after, now, id, attempts
+	requeueStmt *sql.Stmt
+	// Cancels a queued job. Input: now, id
+	cancelQueuedStmt *sql.Stmt
+	// Requests the cancellation of a running job. Input: now, id
+	requestCancelStmt *sql.Stmt
+	// Queues a finished job again. Input: now, id
+	retryStmt *sql.Stmt
+	// Deletes the jobs that finished before a time. Input: time
+	deleteFinishedStmt *sql.Stmt
+
+	mu       sync.Mutex
+	handlers = make(map[string]*handler)
+	wake     = make(chan struct{}, 1)
+)
+
+type Job struct {
+	ID     string
+	Type   string
+	Bucket string
+	// Key prevents a job from being queued twice. A job with a key isn't enqueued while another job of the
+	// same type and key is queued or running.
+	Key        string
+	Payload    []byte
+	checkpoint []byte
+	State      string
+	// Attempts is the number of times the job has been started
+	Attempts    int
+	MaxAttempts int
+	// Done and Total are the progress of the job in units of the job type
+	Done            int64
+	Total           int64
+	Error           string
+	CancelRequested bool
+	RunAfter        time.Time
+	LeaseUntil      *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	FinishedAt      *time.Time
+}
+
+// Finished returns true if the job will not run again
+func (j *Job) Finished() bool {
+	return j.State == StateComplete || j.State == StateFailed || j.State == StateCancelled
+}
+
+// Percent returns the progress of the job in percent. Returns -1 if the job has not reported a total.
+func (j *Job) Percent() int {
+	if j.Total <= 0 {
+		return -1
+	}
+	return int(min(j.Done*100/j.Total, 100))
+}
+
+// Type is a kind of job with a payload of type T
+type Type[T any] struct {
+	name string
+}
+
+func (t Type[T]) Name() string {
+	return t.name
+}
+
+// Handler runs a job. Returning an error retries the job unless the error is Permanent.
+type Handler[T any] func(ctx context.Context, r *Run, payload T) error
+
+type Option func(h *handler)
+
+// Concurrency sets the number of jobs of a type that run at the same time in a process. The default is 1.
+func Concurrency(n int) Option {
+	return func(h *handler) {
+		h.concurrency = max(n, 1)
+	}
+}
+
+// MaxAttempts sets the number of attempts after which a job fails. The default is 3.
+func MaxAttempts(n int) Option {
+	return func(h *handler) {
+		h.maxAttempts = max(n, 1)
+	}
+}
+
+// Cleanup sets a function that deletes what the jobs of a type leave behind. It is called whenever finished jobs
+// are deleted, with the time before which they are deleted.
+func Cleanup(f func(ctx context.Context, before time.Time) error) Option {
+	return func(h *handler) {
+		h.cleanup = f
+	}
+}
+
+type handler struct {
+	name        string
+	run         func(ctx context.Context, r *Run) error
+	concurrency int
+	maxAttempts int
+	cleanup     func(ctx context.Context, before time.Time) error
+	// running is the number of jobs of the type that run at the moment. Guarded by mu.
+	running int
+}
+
+// Register registers the handler of a job type. Jobs of the type are run once it is registered.
+func Register[T any](name string, h Handler[T], opts ...Option) Type[T] {
+	hd := &handler{
+		name: name,
+		run: func(ctx context.Context, r *Run) error {
+			var payload T
+			if err := json.Unmarshal(r.job.Payload, &payload); err != nil {
+				return Permanent(fmt.Errorf("unable to decode payload: %w", err))
+			}
+			return h(ctx, r, payload)
+		},
+		concurrency: 1,
+		maxAttempts: 3,
+	}
+	for _, opt := range opts {
+		opt(hd)
+	}
+	mu.Lock()
+	handlers[name] = hd
+	mu.Unlock()
+	return Type[T]{name: name}
+}
+
+func Configure() {
+	createStmt = db.Prepare("INSERT INTO jobs (" + jobFields + ") VALUES ($1, $2, $3, $4, $5, '', $6, 0, $7, 0, 0, '', false, $8, NULL, $8, $8, NULL)")
+	findOneStmt = db.Prepare("SELECT " + jobFields + " FROM jobs WHERE id = $1")
+	findActiveStmt = db.Prepare("SELECT " + jobFields + " FROM jobs WHERE type = $1 AND dedupe_key = $2 AND state IN ('queued', 'running') LIMIT 1")
+	listStmt = db.Prepare("SELECT " + jobFields + " FROM jobs WHERE state = $1 ORDER BY created_at DESC LIMIT $2")
+	claimStmt = db.Prepare("UPDATE jobs SET state = 'running', attempts = attempts + 1, lease_until = $1, updated_at = $2 WHERE id = (SELECT id FROM jobs WHERE type = $3 AND ((state = 'queued' AND run_after <= $2) OR (state = 'running' AND lease_until < $2)) ORDER BY run_after, created_at LIMIT 1) RETURNING " + jobFields)
+	renewStmt = db.Prepare("UPDATE jobs SET lease_until = $1 WHERE id = $2 AND attempts = $3 AND state = 'running' RETURNING cancel_requested")
+	progressStmt = db.Prepare("UPDATE jobs SET done = $1, total = $2, updated_at = $3 WHERE id = $4")
+	checkpointStmt = db.Prepare("UPDATE jobs SET checkpoint = $1, done = $2, total = $3, updated_at = $4 WHERE id = $5")
+	finishStmt = db.Prepare("UPDATE jobs SET state = $1, error = $2, lease_until = NULL, updated_at = $3, finished_at = $3 WHERE id = $4 AND attempts = $5")
+	requeueStmt = db.Prepare("UPDATE jobs SET state = 'queued', error = $1, run_after = $2, lease_until = NULL, updated_at = $3 WHERE id = $4 AND attempts = $5")
+	cancelQueuedStmt = db.Prepare("UPDATE jobs SET state = 'cancelled', updated_at = $1, finished_at = $1 WHERE id = $2 AND state = 'queued'")
+	requestCancelStmt = db.Prepare("UPDATE jobs SET cancel_requested = true, updated_at = $1 WHERE id = $2")
+	retryStmt = db.Prepare("UPDATE jobs SET state = 'queued', attempts = 0, error = '', cancel_requested = false, run_after = $1, updated_at = $1, finished_at = NULL WHERE id = $2")
+	deleteFinishedStmt = db.Prepare("DELETE FROM jobs WHERE state IN ('complete', 'failed', 'cancelled') AND finished_at < $1")
+
+	go worker()
+}
+
+// Enqueue queues a job. If key isn't empty and a job of the same type and key is queued or running,
+// that job is returned instead.
+func Enqueue[T any](ctx context.Context, t Type[T], bucket, key string, payload T) (*Job, error) {
+	if key != "" {
+		j, err := scanJob(findActiveStmt.QueryRowContext(ctx, t.name, key))
+		if err == nil {
+			return j, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("unable to find job: %w", err)
+		}
+	}
+	p, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode job payload: %w", err)
+	}
+	maxAttempts := 3
+	mu.Lock()
+	if h, ok := handlers[t.name]; ok {
+		maxAttempts = h.maxAttempts
+	}
+	mu.Unlock()
+	now := domain.TimeNow()
+	j := &Job{
+		ID:          domain.RandomId(),
+		Type:        t.name,
+		Bucket:      bucket,
+		Key:         key,
+		Payload:     p,
+		State:       StateQueued,
+		MaxAttempts: maxAttempts,
+		RunAfter:    now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := createStmt.ExecContext(ctx, j.ID, j.Type, j.Bucket, j.Key, string(j.Payload), j.State, j.MaxAttempts, now); err != nil {
+		return nil, fmt.Errorf("unable to create job: %w", err)
+	}
+	trigger()
+	return j, nil
+}
+
+// FindOne finds a job. Returns ec.NoSuchJob if the job cannot be found
+func FindOne(ctx context.Context, id string) (*Job, error) {
+	j, err := scanJob(findOneStmt.QueryRowContext(ctx, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ec.NoSuchJob
+		}
+		return nil, fmt.Errorf("unable to find job: %w", err)
+	}
+	return j, nil
+}
+
+// List lists the newest jobs in a state
+func List(ctx context.Context, state string, limit int) ([]*Job, error) {
+	rows, err := listStmt.QueryContext(ctx, state, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list jobs: %w", err)
+	}
+	defer rows.Close()
+	jobs := make([]*Job, 0)
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode job: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+// Cancel cancels a job. A queued job is cancelled right away, a running job once its handler returns.
+// Returns ec.JobNotCancellable if the job has already finished.
+func Cancel(ctx context.Context, j *Job) error {
+	if j.Finished() {
+		return ec.JobNotCancellable
+	}
+	now := domain.TimeNow()
+	res, err := cancelQueuedStmt.ExecContext(ctx, now, j.ID)
+	if err != nil {
+		return fmt.Errorf("unable to cancel job: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return nil
+	}
+	// the job has been started in the meantime
+	if _, err := requestCancelStmt.ExecContext(ctx, now, j.ID); err != nil {
+		return fmt.Errorf("unable to cancel job: %w", err)
+	}
+	cancelRunning(j.ID)
+	return nil
+}
+
+// Retry queues a failed or cancelled job again. Its checkpoint is kept, so it resumes where it stopped.
+// Returns ec.JobNotRetryable if the job hasn't failed or been cancelled.
+func Retry(ctx context.Context, j *Job) error {
+	if j.State != StateFailed && j.State != StateCancelled {
+		return ec.JobNotRetryable
+	}
+	if _, err := retryStmt.ExecContext(ctx, domain.TimeNow(), j.ID); err != nil {
+		return fmt.Errorf("unable to retry job: %w", err)
+	}
+	trigger()
+	return nil
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks an error of a handler as permanent. The job fails without further attempts.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent returns true if err has been marked as Permanent
+func IsPermanent(err error) bool {
+	var perr *permanentError
+	return errors.As(err, &perr)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(s scanner) (*Job, error) {
+	var j Job
+	var payload, checkpoint string
+	var leaseUntil, finishedAt sql.NullTime
+	if err := s.Scan(
+		&j.ID,
+		&j.Type,
+		&j.Bucket,
+		&j.Key,
+		&payload,
+		&checkpoint,
+		&j.State,
+		&j.Attempts,
+		&j.MaxAttempts,
+		&j.Done,
+		&j.Total,
+		&j.Error,
+		&j.CancelRequested,
+		&j.RunAfter,
+		&leaseUntil,
+		&j.CreatedAt,
+		&j.UpdatedAt,
+		&finishedAt,
+	); err != nil {
+		return nil, err
+	}
+	j.Payload = []byte(payload)
+	j.checkpoint = []byte(checkpoint)
+	if leaseUntil.Valid {
+		j.LeaseUntil = &leaseUntil.Time
+	}
+	if finishedAt.Valid {
+		j.FinishedAt = &finishedAt.Time
+	}
+	return &j, nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/config"
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+type testPayload struct {
+	Items int
+}
+
+type testCheckpoint struct {
+	Next int
+}
+
+var configureOnce sync.Once
+
+func configure() {
+	configureOnce.Do(func() {
+		config.DataDir = os.TempDir()
+		PollInterval = 10 * time.Millisecond
+		RetryDelay = 10 * time.Millisecond
+		LeaseDuration = 300 * time.Millisecond
+		db.Configure()
+		Configure()
+	})
+}
+
+// await waits until a job is in a state
+func await(t *testing.T, id, state string) *Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		j, err := FindOne(context.Background(), id)
+		if err != nil {
+			t.Fatalf("unable to find job: %v", err)
+		}
+		if j.State == state {
+			return j
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected job to be %s, got %s (%s)", state, j.State, j.Error)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobProgressAndRetries(t *testing.T) {
+	configure()
+	ctx := context.Background()
+	var attempts atomic.Int32
+	typ := Register("test.items."+domain.RandomId(), func(ctx context.Context, r *Run, p testPayload) error {
+		var c testCheckpoint
+		if _, err := r.Resume(&c); err != nil {
+			return err
+		}
+		for i := c.Next; i < p.Items; i++ {
+			if err := r.Checkpoint(ctx, testCheckpoint{Next: i + 1}, int64(i+1), int64(p.Items)); err != nil {
+				return err
+			}
+			// the first attempt fails halfway through
+			if i == p.Items/2 && attempts.Add(1) == 1 {
+				return errors.New("interrupted")
+			}
+		}
+		return nil
+	}, MaxAttempts(2))
+
+	j, err := Enqueue(ctx, typ, "jobs-test", "", testPayload{Items: 10})
+	if err != nil {
+		t.Fatalf("unable to enqueue job: %v", err)
+	}
+	j = await(t, j.ID, StateComplete)
+	if j.Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", j.Attempts)
+	}
+	if j.Done != 10 || j.Total != 10 || j.Percent() != 100 {
+		t.Errorf("expected progress 10/10, got %d/%d", j.Done, j.Total)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("expected the second attempt to resume after the checkpoint")
+	}
+}
+
+func TestJobFailure(t *testing.T) {
+	configure()
+	ctx := context.Background()
+	var calls atomic.Int32
+	typ := Register("test.fail."+domain.RandomId(), func(ctx context.Context, r *Run, p testPayload) error {
+		calls.Add(1)
+		if p.Items == 0 {
+			return Permanent(errors.New("nothing to do"))
+		}
+		return errors.New("broken")
+	}, MaxAttempts(3))
+
+	j, err := Enqueue(ctx, typ, "", "", testPayload{Items: 1})
+	if err != nil {
+		t.Fatalf("unable to enqueue job: %v", err)
+	}
+	j = await(t, j.ID, StateFailed)
+	if j.Attempts != 3 || j.Error != "broken" || calls.Load() != 3 {
+		t.Errorf("expected failure after 3 attempts, got %d attempts with %q", j.Attempts, j.Error)
+	}
+	if err := Cancel(ctx, j); !errors.Is(err, ec.JobNotCancellable) {
+		t.Errorf("expected failed job not to be cancellable, got %v", err)
+	}
+
+	calls.Store(0)
+	p, err := Enqueue(ctx, typ, "", "", testPayload{})
+	if err != nil {
+		t.Fatalf("unable to enqueue job: %v", err)
+	}
+	p = await(t, p.ID, StateFailed)
+	if p.Attempts != 1 || calls.Load() != 1 {
+		t.Errorf("expected permanent failure after 1 attempt, got %d", p.Attempts)
+	}
+
+	if err := Retry(ctx, p); err != nil {
+		t.Fatalf("unable to retry job: %v", err)
+	}
+	await(t, p.ID, StateFailed)
+	if calls.Load() != 2 {
+		t.Errorf("expected retried job to run again")
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	err := errors.New("broken")
+	if IsPermanent(err) {
+		t.Errorf("expected %v not to be permanent", err)
+	}
+	if !IsPermanent(fmt.Errorf("wrapped: %w", Permanent(err))) {
+		t.Errorf("expected wrapped permanent error to be permanent")
+	}
+}
+
+func TestJobCancelAndDedupe(t *testing.T) {
+	configure()
+	ctx := context.Background()
+	started := make(chan struct{}, 1)
+	typ := Register("test.block."+domain.RandomId(), func(ctx context.Context, r *Run, p testPayload) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	j, err := Enqueue(ctx, typ, "", "only-one", testPayload{})
+	if err != nil {
+		t.Fatalf("unable to enqueue job: %v", err)
+	}
+	again, err := Enqueue(ctx, typ, "", "only-one", testPayload{})
+	if err != nil {
+		t.Fatalf("unable to enqueue job: %v", err)
+	}
+	if again.ID != j.ID {
+		t.Errorf("expected job with the same key to be deduplicated")
+	}
+
+	<-started
+	j = await(t, j.ID, StateRunning)
+	if err := Cancel(ctx, j); err != nil {
+		t.Fatalf("unable to cancel job: %v", err)
+	}
+	await(t, j.ID, StateCancelled)
+
+	next, err := Enqueue(ctx, typ, "", "only-one", testPayload{})
+	if err != nil {
+		t.Fatalf("unable to enqueue job: %v", err)
+	}
+	if next.ID == j.ID {
+		t.Errorf("expected a new job once the previous one has finished")
+	}
+	<-started
+	if err := Cancel(ctx, next); err != nil {
+		t.Fatalf("unable to cancel job: %v", err)
+	}
+	await(t, next.ID, StateCancelled)
+}
+
+func TestJobLeaseTakeover(t *testing.T) {
+	configure()
+	ctx := context.Background()
+	var runs atomic.Int32
+	name := "test.lease." + domain.RandomId()
+	typ := Type[testPayload]{name: name}
+
+	j, err := Enqueue(ctx, typ, "", "", testPayload{})
+	if err != nil {
+		t.Fatalf("unable to enqueue job: %v", err)
+	}
+	// a process claims the job and crashes before its lease is renewed
+	now := domain.TimeNow()
+	if _, err := scanJob(claimStmt.QueryRow(now.Add(-time.Second), now, name)); err != nil {
+		t.Fatalf("unable to claim job: %v", err)
+	}
+
+	Register(name, func(ctx context.Context, r *Run, p testPayload) error {
+		runs.Add(1)
+		return nil
+	})
+	j = await(t, j.ID, StateComplete)
+	if j.Attempts != 2 || runs.Load() != 1 {
+		t.Errorf("expected the job to be taken over, got %d attempts and %d runs", j.Attempts, runs.Load())
+	}
+}
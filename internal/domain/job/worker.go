@@ -0,0 +1,267 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/util"
+)
+
+var (
+	runningMu sync.Mutex
+	// running holds the cancel functions of the jobs that run in this process
+	running = make(map[string]context.CancelFunc)
+)
+
+// Run is a job while it is executed by its handler
+type Run struct {
+	job *Job
+}
+
+// Job returns the job that is run
+func (r *Run) Job() *Job {
+	return r.job
+}
+
+// LastAttempt returns true if the job fails when this attempt fails
+func (r *Run) LastAttempt() bool {
+	return r.job.Attempts >= r.job.MaxAttempts
+}
+
+// Progress stores the progress of the job
+func (r *Run) Progress(ctx context.Context, done, total int64) error {
+	r.job.Done, r.job.Total = done, total
+	if _, err := progressStmt.ExecContext(ctx, done, total, domain.TimeNow(), r.job.ID); err != nil {
+		return fmt.Errorf("unable to store job progress: %w", err)
+	}
+	return nil
+}
+
+// Checkpoint stores the state the job resumes from together with its progress
+func (r *Run) Checkpoint(ctx context.Context, v any, done, total int64) error {
+	c, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("unable to encode job checkpoint: %w", err)
+	}
+	r.job.checkpoint, r.job.Done, r.job.Total = c, done, total
+	if _, err := checkpointStmt.ExecContext(ctx, string(c), done, total, domain.TimeNow(), r.job.ID); err != nil {
+		return fmt.Errorf("unable to store job checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Resume decodes the last checkpoint of the job into v. Returns false if the job has no checkpoint.
+func (r *Run) Resume(v any) (bool, error) {
+	if len(r.job.checkpoint) == 0 {
+		return false, nil
+	}
+	if err := json.Unmarshal(r.job.checkpoint, v); err != nil {
+		return false, fmt.Errorf("unable to decode job checkpoint: %w", err)
+	}
+	return true, nil
+}
+
+func trigger() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+func worker() {
+	ticker := time.NewTicker(PollInterval)
+	lastCleanup := time.Time{}
+	for {
+		select {
+		case <-ticker.C:
+		case <-wake:
+		}
+		if time.Since(lastCleanup) > time.Hour {
+			deleteFinished()
+			lastCleanup = time.Now()
+		}
+		dispatch()
+	}
+}
+
+// dispatch starts due jobs of every type that runs fewer jobs than its concurrency allows
+func dispatch() {
+	mu.Lock()
+	hs := make([]*handler, 0, len(handlers))
+	for _, h := range handlers {
+		hs = append(hs, h)
+	}
+	mu.Unlock()
+
+	for _, h := range hs {
+		for h.acquire() {
+			j, err := scanJob(claimStmt.QueryRow(domain.TimeNow().Add(LeaseDuration), domain.TimeNow(), h.name))
+			if err != nil {
+				h.release()
+				if !errors.Is(err, sql.ErrNoRows) {
+					slog.Error("unable to claim job", "type", h.name, "error", err)
+				}
+				break
+			}
+			go execute(h, j)
+		}
+	}
+}
+
+func (h *handler) acquire() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if h.running >= h.concurrency {
+		return false
+	}
+	h.running++
+	return true
+}
+
+func (h *handler) release() {
+	mu.Lock()
+	h.running--
+	mu.Unlock()
+}
+
+// execute runs a claimed job and records its outcome
+func execute(h *handler, j *Job) {
+	defer trigger()
+	defer h.release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runningMu.Lock()
+	running[j.ID] = cancel
+	runningMu.Unlock()
+	defer func() {
+		runningMu.Lock()
+		delete(running, j.ID)
+		runningMu.Unlock()
+	}()
+
+	var leaseLost bool
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		leaseLost = heartbeat(ctx, cancel, j)
+	}()
+
+	slog.Info("running job", "job", j.ID, "type", j.Type, "attempt", j.Attempts)
+	err := call(ctx, h, &Run{job: j})
+	cancel()
+	wg.Wait()
+
+	if leaseLost {
+		slog.Warn("lost lease of job", "job", j.ID, "type", j.Type)
+		return
+	}
+	// the cancellation may have been requested by another process while the handler returned
+	current, ferr := FindOne(context.Background(), j.ID)
+	if ferr != nil {
+		slog.Error("unable to find job", "job", j.ID, "error", ferr)
+		return
+	}
+	now := domain.TimeNow()
+	switch {
+	case err == nil:
+		slog.Info("finished job", "job", j.ID, "type", j.Type)
+		_, err = finishStmt.Exec(StateComplete, "", now, j.ID, j.Attempts)
+	case current.CancelRequested:
+		slog.Info("cancelled job", "job", j.ID, "type", j.Type)
+		_, err = finishStmt.Exec(StateCancelled, "", now, j.ID, j.Attempts)
+	case IsPermanent(err) || j.Attempts >= j.MaxAttempts:
+		slog.Error("job failed", "job", j.ID, "type", j.Type, "attempts", j.Attempts, "error", err)
+		_, err = finishStmt.Exec(StateFailed, err.Error(), now, j.ID, j.Attempts)
+	default:
+		slog.Warn("job attempt failed", "job", j.ID, "type", j.Type, "attempts", j.Attempts, "error", err)
+		_, err = requeueStmt.Exec(err.Error(), now.Add(util.Backoff(RetryDelay, MaxRetryDelay, j.Attempts)), now, j.ID, j.Attempts)
+	}
+	if err != nil {
+		slog.Error("unable to update job", "job", j.ID, "error", err)
+	}
+}
+
+// call runs the handler. Panics are returned as errors so that they are retried like failures.
+func call(ctx context.Context, h *handler, r *Run) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	return h.run(ctx, r)
+}
+
+// heartbeat renews the lease of a job until ctx is done. It cancels the job if its cancellation is requested
+// or the lease has been lost. Returns true if the lease has been lost.
+func heartbeat(ctx context.Context, cancel context.CancelFunc, j *Job) bool {
+	ticker := time.NewTicker(LeaseDuration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+		var cancelRequested bool
+		err := renewStmt.QueryRow(domain.TimeNow().Add(LeaseDuration), j.ID, j.Attempts).Scan(&cancelRequested)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			cancel()
+			return true
+		case err != nil:
+			slog.Error("unable to renew lease of job", "job", j.ID, "error", err)
+		case cancelRequested:
+			cancel()
+		}
+	}
+}
+
+// cancelRunning cancels a job that runs in this process. Jobs of other processes see the request with their
+// next lease renewal.
+func cancelRunning(id string) {
+	runningMu.Lock()
+	defer runningMu.Unlock()
+	if cancel, ok := running[id]; ok {
+		cancel()
+	}
+}
+
+// deleteFinished deletes the jobs that finished before the retention and cleans up after their types
+func deleteFinished() {
+	before := domain.TimeNow().Add(-Retention)
+	res, err := deleteFinishedStmt.Exec(before)
+	if err != nil {
+		slog.Error("unable to delete finished jobs", "error", err)
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		slog.Info("deleted finished jobs", "jobs", n)
+	}
+
+	mu.Lock()
+	hs := make([]*handler, 0, len(handlers))
+	for _, h := range handlers {
+		if h.cleanup != nil {
+			hs = append(hs, h)
+		}
+	}
+	mu.Unlock()
+	for _, h := range hs {
+		if err := h.cleanup(context.Background(), before); err != nil {
+			slog.Error("unable to clean up after jobs", "type", h.name, "error", err)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package lifecycle
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/domain/job"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+const MaxRules = 100
+
+var (
+	ruleFields          = "id, bucket, prefix, tags, expiration_days, keep_versions, abort_incomplete_days, created_at"
+	createStmt          *sql.Stmt
+	listStmt            *sql.Stmt
+	listAllStmt         *sql.Stmt
+	findOneStmt         *sql.Stmt
+	deleteStmt          *sql.Stmt
+	deleteForBucketStmt *sql.Stmt
+)
+
+// Rule is a lifecycle rule of a bucket. Each action of a rule is disabled if its value is 0.
+type Rule struct {
+	ID     string
+	Bucket string
+	// Prefix restricts the rule to objects whose keys start with the prefix. An empty prefix matches all objects.
+	Prefix string
+	// Tags restricts the rule to objects that have all of the tags. Incomplete uploads are not restricted by tags.
+	Tags map[string]string
+	// ExpirationDays is the number of days after which objects are deleted
+	ExpirationDays int
+	// KeepVersions is the number of versions that are kept per object including the current version.
+	// Previous versions of matching objects are kept when they are replaced and pruned beyond this number.
+	KeepVersions int
+	// AbortIncompleteDays is the number of days after which incomplete uploads are aborted
+	AbortIncompleteDays int
+	CreatedAt           time.Time
+}
+
+type RuleCommand struct {
+	Prefix              string            `json:"prefix"`
+	Tags                map[string]string `json:"tags"`
+	ExpirationDays      int               `json:"expirationDays"`
+	KeepVersions        int               `json:"keepVersions"`
+	AbortIncompleteDays int               `json:"abortIncompleteDays"`
+}
+
+func (c RuleCommand) Validate() error {
+	return srv.Validate(c.validate("", nil))
+}
+
+func (c RuleCommand) validate(field string, v *srv.ValidationError) *srv.ValidationError {
+	v = srv.Require(field+"prefix", srv.ValidationCodeInvalid, "prefix must not be longer than 1024 characters", len(c.Prefix) <= 1024, v)
+	v = object.ValidateTagsField(field+"tags", c.Tags, v)
+	v = srv.Require(field+"expirationDays", srv.ValidationCodeInvalid, "expirationDays must not be negative", c.ExpirationDays >= 0, v)
+	v = srv.Require(field+"keepVersions", srv.ValidationCodeInvalid, "keepVersions must not be negative", c.KeepVersions >= 0, v)
+	v = srv.Require(field+"abortIncompleteDays", srv.ValidationCodeInvalid, "abortIncompleteDays must not be negative", c.AbortIncompleteDays >= 0, v)
+	v = srv.Require(field+"expirationDays", srv.ValidationCodeInvalid, "rule must have at least one action",
+		c.ExpirationDays > 0 || c.KeepVersions > 0 || c.AbortIncompleteDays > 0, v)
+	return v
+}
+
+// ValidateRules validates a complete set of rules of a bucket
+func ValidateRules(rules []RuleCommand) error {
+	v := srv.RequireMaxLengthSlice("rules", MaxRules, rules, nil)
+	for i, r := range rules {
+		v = r.validate(fmt.Sprintf("rules[%d].", i), v)
+	}
+	return srv.Validate(v)
+}
+
+func Configure() {
+	createStmt = db.Prepare("INSERT INTO lifecycle_rules (" + ruleFields + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)")
+	listStmt = db.Prepare("SELECT " + ruleFields + " FROM lifecycle_rules WHERE bucket = $1 ORDER BY created_at, id")
+	listAllStmt = db.Prepare("SELECT " + ruleFields + " FROM lifecycle_rules ORDER BY bucket, created_at, id")
+	findOneStmt = db.Prepare("SELECT " + ruleFields + " FROM lifecycle_rules WHERE bucket = $1 AND id = $2")
+	deleteStmt = db.Prepare("DELETE FROM lifecycle_rules WHERE bucket = $1 AND id = $2")
+	deleteForBucketStmt = db.Prepare("DELETE FROM lifecycle_rules WHERE bucket = $1")
+
+	sweepJob = job.Register("lifecycle.sweep", sweep)
+	go worker()
+}
+
+// List lists the rules of a bucket
+func List(ctx context.Context, bucket string) ([]*Rule, error) {
+	return decodeRows(listStmt.QueryContext(ctx, bucket))
+}
+
+func listAll(ctx context.Context) ([]*Rule, error) {
+	return decodeRows(listAllStmt.QueryContext(ctx))
+}
+
+// FindOne finds a rule. Returns ec.NoSuchLifecycleRule if the rule cannot be found
+func FindOne(ctx context.Context, bucket, id string) (*Rule, error) {
+	r, err := scanRule(findOneStmt.QueryRowContext(ctx, bucket, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ec.NoSuchLifecycleRule
+		}
+		return nil, fmt.Errorf("unable to find lifecycle rule: %w", err)
+	}
+	return r, nil
+}
+
+// Add adds a rule to a bucket
+func Add(ctx context.Context, bucket string, cmd RuleCommand) (*Rule, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	existing, err := List(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxRules {
+		return nil, ec.TooManyLifecycleRules
+	}
+	var r *Rule
+	err = db.Tx(ctx, func(tx *sql.Tx) error {
+		r, err = create(ctx, tx, bucket, cmd)
+		return err
+	})
+	return r, err
+}
+
+// Replace replaces all rules of a bucket
+func Replace(ctx context.Context, bucket string, cmds []RuleCommand) ([]*Rule, error) {
+	if err := ValidateRules(cmds); err != nil {
+		return nil, err
+	}
+	rules := make([]*Rule, 0, len(cmds))
+	err := db.Tx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.StmtContext(ctx, deleteForBucketStmt).ExecContext(ctx, bucket); err != nil {
+			return fmt.Errorf("unable to delete lifecycle rules: %w", err)
+		}
+		for _, cmd := range cmds {
+			r, err := create(ctx, tx, bucket, cmd)
+			if err != nil {
+				return err
+			}
+			rules = append(rules, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func create(ctx context.Context, tx *sql.Tx, bucket string, cmd RuleCommand) (*Rule, error) {
+	r := &Rule{
+		ID:                  domain.RandomId(),
+		Bucket:              bucket,
+		Prefix:              cmd.Prefix,
+		Tags:                cmd.Tags,
+		ExpirationDays:      cmd.ExpirationDays,
+		KeepVersions:        cmd.KeepVersions,
+		AbortIncompleteDays: cmd.AbortIncompleteDays,
+		CreatedAt:           domain.TimeNow(),
+	}
+	tags, err := json.Marshal(r.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode lifecycle rule tags: %w", err)
+	}
+	if _, err := tx.StmtContext(ctx, createStmt).ExecContext(ctx, r.ID, r.Bucket, r.Prefix, string(tags), r.ExpirationDays, r.KeepVersions, r.AbortIncompleteDays, r.CreatedAt); err != nil {
+		return nil, fmt.Errorf("unable to create lifecycle rule: %w", err)
+	}
+	return r, nil
+}
+
+// Delete deletes a rule of a bucket
+func Delete(ctx context.Context, bucket, id string) error {
+	if _, err := deleteStmt.ExecContext(ctx, bucket, id); err != nil {
+		return fmt.Errorf("unable to delete lifecycle rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll deletes all rules of a bucket
+func DeleteAll(ctx context.Context, bucket string) error {
+	if _, err := deleteForBucketStmt.ExecContext(ctx, bucket); err != nil {
+		return fmt.Errorf("unable to delete lifecycle rules: %w", err)
+	}
+	return nil
+}
+
+func decodeRows(rows *sql.Rows, err error) ([]*Rule, error) {
+	if err != nil {
+		return nil, fmt.Errorf("unable to query lifecycle rules: %w", err)
+	}
+	rules := make([]*Rule, 0)
+	for rows.Next() {
+		r, err := scanRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode lifecycle rule: %w", err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRule(row scanner) (*Rule, error) {
+	var r Rule
+	var tags string
+	if err := row.Scan(
+		&r.ID,
+		&r.Bucket,
+		&r.Prefix,
+		&tags,
+		&r.ExpirationDays,
+		&r.KeepVersions,
+		&r.AbortIncompleteDays,
+		&r.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &r.Tags); err != nil {
+		return nil, fmt.Errorf("unable to decode lifecycle rule tags: %w", err)
+	}
+	return &r, nil
+}
+
+// KeepsVersions returns true if a rule of the bucket of o keeps the previous versions of o
+func KeepsVersions(ctx context.Context, o *object.Object) (bool, error) {
+	rules, err := List(ctx, o.Bucket)
+	if err != nil {
+		return false, err
+	}
+	// the tags are only loaded for rules that need them
+	var tags map[string]string
+	loaded := false
+	for _, r := range rules {
+		if r.KeepVersions == 0 || !strings.HasPrefix(o.Key, r.Prefix) {
+			continue
+		}
+		if len(r.Tags) > 0 && !loaded {
+			if tags, err = object.Tags(ctx, o); err != nil {
+				return false, err
+			}
+			loaded = true
+		}
+		if hasTags(tags, r.Tags) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasTags returns true if tags contains all of want
+func hasTags(tags, want map[string]string) bool {
+	for k, v := range want {
+		if t, ok := tags[k]; !ok || t != v {
+			return false
+		}
+	}
+	return true
+}
+
+// filter returns the object filter of the rule. createdBefore is optional.
+func (r *Rule) filter(createdBefore *time.Time) object.Filter {
+	return object.Filter{
+		Prefix:        r.Prefix,
+		Tags:          r.Tags,
+		CreatedBefore: createdBefore,
+	}
+}
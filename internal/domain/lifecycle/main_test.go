@@ -0,0 +1,47 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package lifecycle
+
+import "testing"
+
+func TestRuleCommandValidate(t *testing.T) {
+	tests := []struct {
+		cmd   RuleCommand
+		valid bool
+	}{
+		{cmd: RuleCommand{}, valid: false},
+		{cmd: RuleCommand{Prefix: "tmp/"}, valid: false},
+		{cmd: RuleCommand{Prefix: "tmp/", ExpirationDays: 7}, valid: true},
+		{cmd: RuleCommand{KeepVersions: 5}, valid: true},
+		{cmd: RuleCommand{AbortIncompleteDays: 1}, valid: true},
+		{cmd: RuleCommand{ExpirationDays: 5, KeepVersions: 5, AbortIncompleteDays: 1}, valid: true},
+		{cmd: RuleCommand{ExpirationDays: -1, KeepVersions: 5}, valid: false},
+		{cmd: RuleCommand{KeepVersions: -1, ExpirationDays: 5}, valid: false},
+		{cmd: RuleCommand{AbortIncompleteDays: -1, ExpirationDays: 5}, valid: false},
+	}
+
+	for _, test := range tests {
+		err := test.cmd.Validate()
+		if (err == nil) != test.valid {
+			t.Errorf("expected %+v to be valid=%v, got %v", test.cmd, test.valid, err)
+		}
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	if err := ValidateRules(nil); err != nil {
+		t.Errorf("expected empty rules to be valid, got %v", err)
+	}
+	rules := make([]RuleCommand, MaxRules+1)
+	for i := range rules {
+		rules[i] = RuleCommand{ExpirationDays: 1}
+	}
+	if err := ValidateRules(rules); err == nil {
+		t.Errorf("expected %d rules to be invalid", len(rules))
+	}
+	if err := ValidateRules([]RuleCommand{{ExpirationDays: 1}, {}}); err == nil {
+		t.Errorf("expected rule without action to be invalid")
+	}
+}
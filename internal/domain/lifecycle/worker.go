@@ -0,0 +1,149 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/bus"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/domain/job"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+var (
+	// EventExpired indicates that an object has been deleted by a lifecycle rule
+	EventExpired = bus.NewTopic[ExpiredEvent]("lifecycle.expired")
+	// EventApplied indicates that the lifecycle rules of a bucket have been applied
+	EventApplied = bus.NewDurableTopic[AppliedEvent]("lifecycle.applied")
+	// Interval is the time between two lifecycle runs
+	Interval = time.Hour
+	sweepJob job.Type[sweepPayload]
+)
+
+type ExpiredEvent struct {
+	Bucket string
+	Key    string
+	Rule   string
+}
+
+type AppliedEvent struct {
+	Bucket  string
+	Expired int
+	Pruned  int
+	Aborted int
+}
+
+// sweepPayload is the payload of a lifecycle sweep job
+type sweepPayload struct{}
+
+// worker queues a sweep every Interval. Sweeps that are still queued or running aren't queued again.
+func worker() {
+	time.Sleep(time.Minute)
+	ticker := time.NewTicker(Interval)
+	for {
+		if _, err := job.Enqueue(context.Background(), sweepJob, "", "lifecycle", sweepPayload{}); err != nil {
+			slog.Error("unable to queue lifecycle sweep", "error", err)
+		}
+		<-ticker.C
+	}
+}
+
+// sweep applies the lifecycle rules of all buckets
+func sweep(ctx context.Context, r *job.Run, _ sweepPayload) error {
+	rules, err := listAll(ctx)
+	if err != nil {
+		return err
+	}
+	byBucket := make(map[string][]*Rule)
+	buckets := make([]string, 0)
+	for _, r := range rules {
+		if _, ok := byBucket[r.Bucket]; !ok {
+			buckets = append(buckets, r.Bucket)
+		}
+		byBucket[r.Bucket] = append(byBucket[r.Bucket], r)
+	}
+	for i, b := range buckets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		e := AppliedEvent{Bucket: b}
+		for _, r := range byBucket[b] {
+			if err := apply(ctx, r, &e); err != nil {
+				slog.Error("unable to apply lifecycle rule", "bucket", b, "rule", r.ID, "error", err)
+			}
+		}
+		if e.Expired > 0 || e.Pruned > 0 || e.Aborted > 0 {
+			slog.Info("applied lifecycle rules", "bucket", b, "expired", e.Expired, "pruned", e.Pruned, "aborted", e.Aborted)
+			bus.Publish(ctx, EventApplied, e)
+		}
+		if err := r.Progress(ctx, int64(i+1), int64(len(buckets))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, r *Rule, e *AppliedEvent) error {
+	now := domain.TimeNow()
+	if r.ExpirationDays > 0 {
+		n, err := expire(ctx, r, now.AddDate(0, 0, -r.ExpirationDays))
+		e.Expired += n
+		if err != nil {
+			return err
+		}
+	}
+	if r.KeepVersions > 0 {
+		n, err := object.PruneVersions(ctx, r.Bucket, r.filter(nil), r.KeepVersions)
+		e.Pruned += n
+		if err != nil {
+			return err
+		}
+	}
+	if r.AbortIncompleteDays > 0 {
+		n, err := archive.AbortPending(ctx, r.Bucket, r.Prefix, now.AddDate(0, 0, -r.AbortIncompleteDays))
+		e.Aborted += n
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expire deletes all objects matched by r that have been created before t. Locked objects are skipped.
+// The chunks of the deleted objects are reclaimed by the object purge.
+func expire(ctx context.Context, r *Rule, t time.Time) (int, error) {
+	expired := 0
+	startAfter := ""
+	for {
+		objects, err := object.ListFiltered(ctx, r.Bucket, r.filter(&t), startAfter, 1000)
+		if err != nil {
+			return expired, err
+		}
+		if len(objects) == 0 {
+			return expired, nil
+		}
+		for _, o := range objects {
+			startAfter = o.Key
+			if err := object.Delete(ctx, o); err != nil {
+				if errors.Is(err, ec.ObjectLocked) {
+					continue
+				}
+				return expired, err
+			}
+			expired++
+			bus.Publish(ctx, EventExpired, ExpiredEvent{
+				Bucket: o.Bucket,
+				Key:    o.Key,
+				Rule:   r.ID,
+			})
+		}
+	}
+}
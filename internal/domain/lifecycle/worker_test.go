@@ -0,0 +1,152 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package lifecycle
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/bus"
+	"github.com/cfichtmueller/stor/internal/config"
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/domain/chunk"
+	"github.com/cfichtmueller/stor/internal/domain/job"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+func TestSweep(t *testing.T) {
+	config.DataDir = os.TempDir()
+	job.PollInterval = 10 * time.Millisecond
+	db.Configure()
+	chunk.Configure()
+	object.Configure()
+	job.Configure()
+	archive.Configure()
+	Configure()
+	ctx := context.Background()
+	bucket := "lifecycle-test-" + domain.RandomId()
+
+	for _, cmd := range []object.CreateCommand{
+		{Key: "tmp/old", ContentType: "text/plain", Data: []byte("old")},
+		{Key: "tmp/new", ContentType: "text/plain", Data: []byte("new")},
+		{Key: "tmp/held", ContentType: "text/plain", Data: []byte("held"), LegalHold: true},
+		{Key: "keep/old", ContentType: "text/plain", Data: []byte("old")},
+	} {
+		if _, err := object.Create(ctx, bucket, cmd); err != nil {
+			t.Fatalf("unable to create object %s: %v", cmd.Key, err)
+		}
+	}
+	versioned, err := object.Create(ctx, bucket, object.CreateCommand{Key: "ver/a", ContentType: "text/plain", Data: []byte("1")})
+	if err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+	for _, data := range []string{"2", "3", "4"} {
+		versioned, err = object.Update(ctx, versioned, object.UpdateCommand{ContentType: "text/plain", Data: []byte(data), KeepPreviousVersion: true})
+		if err != nil {
+			t.Fatalf("unable to update object: %v", err)
+		}
+	}
+	stale, err := archive.Create(ctx, archive.CreateCommand{Bucket: bucket, Key: "tmp/stale.zip", Type: archive.TypeZip})
+	if err != nil {
+		t.Fatalf("unable to create archive: %v", err)
+	}
+	recent, err := archive.Create(ctx, archive.CreateCommand{Bucket: bucket, Key: "tmp/recent.zip", Type: archive.TypeZip})
+	if err != nil {
+		t.Fatalf("unable to create archive: %v", err)
+	}
+	if err := db.Tx(ctx, func(tx *sql.Tx) error {
+		before := domain.TimeNow().AddDate(0, 0, -8)
+		if _, err := tx.ExecContext(ctx, "UPDATE objects SET created_at = $1 WHERE bucket = $2 AND key IN ('tmp/old', 'tmp/held', 'keep/old')", before, bucket); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "UPDATE archives SET created_at = $1 WHERE id = $2", before, stale)
+		return err
+	}); err != nil {
+		t.Fatalf("unable to backdate objects: %v", err)
+	}
+
+	if _, err := Replace(ctx, bucket, []RuleCommand{
+		{Prefix: "tmp/", ExpirationDays: 7, AbortIncompleteDays: 7},
+		{Prefix: "ver/", KeepVersions: 2},
+	}); err != nil {
+		t.Fatalf("unable to add lifecycle rule: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = DeleteAll(ctx, bucket)
+	})
+
+	expired := bus.Listen(EventExpired, 16)
+	defer expired.Close()
+
+	j, err := job.Enqueue(ctx, sweepJob, "", "lifecycle", sweepPayload{})
+	if err != nil {
+		t.Fatalf("unable to queue sweep: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !j.Finished() {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected sweep to finish, got %s", j.State)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if j, err = job.FindOne(ctx, j.ID); err != nil {
+			t.Fatalf("unable to find job: %v", err)
+		}
+	}
+	if j.State != job.StateComplete {
+		t.Fatalf("expected sweep to complete, got %s (%s)", j.State, j.Error)
+	}
+
+	for key, exists := range map[string]bool{"tmp/old": false, "tmp/new": true, "tmp/held": true, "keep/old": true} {
+		_, err := object.FindOne(ctx, bucket, key, false)
+		if exists && err != nil {
+			t.Errorf("expected %s to be kept, got %v", key, err)
+		}
+		if !exists && err != ec.NoSuchKey {
+			t.Errorf("expected %s to be expired, got %v", key, err)
+		}
+	}
+
+	var versions int
+	if err := db.QueryRow("SELECT COUNT(*) FROM object_versions WHERE object = $1 AND is_deleted = 0", versioned.ID).Scan(&versions); err != nil {
+		t.Fatalf("unable to count object versions: %v", err)
+	}
+	if versions != 2 {
+		t.Errorf("expected 2 versions to be kept, got %d", versions)
+	}
+	if keep, err := KeepsVersions(ctx, versioned); err != nil || !keep {
+		t.Errorf("expected the versions of ver/a to be kept, got %v, %v", keep, err)
+	}
+
+	for _, want := range []struct{ id, key, state string }{
+		{id: stale, key: "tmp/stale.zip", state: archive.StateAborted},
+		{id: recent, key: "tmp/recent.zip", state: archive.StatePending},
+	} {
+		a, err := archive.FindOne(ctx, bucket, want.key, want.id)
+		if err != nil {
+			t.Fatalf("unable to find archive %s: %v", want.key, err)
+		}
+		if a.State != want.state {
+			t.Errorf("expected archive %s to be %s, got %s", want.key, want.state, a.State)
+		}
+	}
+
+	keys := make([]string, 0)
+	for len(expired.C) > 0 {
+		e := <-expired.C
+		if e.Bucket == bucket {
+			keys = append(keys, e.Key)
+		}
+	}
+	if !slices.Equal(keys, []string{"tmp/old"}) {
+		t.Errorf("expected an expired event for tmp/old, got %v", keys)
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package move
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/bus"
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/domain/job"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+var (
+	StateProcessing = "processing"
+	StateComplete   = "complete"
+	StateFailed     = "failed"
+	StateCancelled  = "cancelled"
+	moveFields      = "id, bucket, prefix, destination, state, total, moved, failed, job, created_at"
+	createStmt      *sql.Stmt
+	findOneStmt     *sql.Stmt
+	// Finds a move by its id. Input: id
+	findByIdStmt *sql.Stmt
+	// Finds the processing moves that have no job because they have been created before moves ran as jobs. Input: state
+	findWithoutJobStmt *sql.Stmt
+	// Sets the job of a move. Input: job, id
+	setJobStmt *sql.Stmt
+	updateStmt *sql.Stmt
+	// Deletes the finished moves that have been created before a time. Input: processing state, time
+	deleteFinishedStmt *sql.Stmt
+	processJob         job.Type[processPayload]
+)
+
+// Move moves all objects under a prefix to a destination prefix
+type Move struct {
+	ID          string
+	Bucket      string
+	Prefix      string
+	Destination string
+	State       string
+	// Total is the number of objects under the prefix when the move was created
+	Total int64
+	// Moved is the number of objects moved so far
+	Moved int64
+	// Failed is the number of objects that couldn't be moved because they are locked or their destination key is
+	// taken, locked or invalid
+	Failed int64
+	// Job is the id of the job that processes the move
+	Job       string
+	CreatedAt time.Time
+}
+
+type CreateCommand struct {
+	Bucket      string
+	Prefix      string
+	Destination string
+}
+
+func (c CreateCommand) Validate() error {
+	v := srv.RequireNotEmpty("prefix", c.Prefix, nil)
+	v = srv.RequireNotEmpty("destination", c.Destination, v)
+	v = srv.RequireMaxLength("destination", 1024, c.Destination, v)
+	v = srv.Require("destination", srv.ValidationCodeInvalid, "destination must not overlap with prefix",
+		!strings.HasPrefix(c.Destination, c.Prefix) && !strings.HasPrefix(c.Prefix, c.Destination), v)
+	return srv.Validate(v)
+}
+
+// processPayload is the payload of the job that processes a move
+type processPayload struct {
+	MoveId string
+}
+
+func Configure() {
+	createStmt = db.Prepare("INSERT INTO moves (" + moveFields + ") VALUES ($1, $2, $3, $4, $5, $6, 0, 0, '', $7)")
+	findOneStmt = db.Prepare("SELECT " + moveFields + " FROM moves WHERE id = $1 AND bucket = $2")
+	findByIdStmt = db.Prepare("SELECT " + moveFields + " FROM moves WHERE id = $1")
+	findWithoutJobStmt = db.Prepare("SELECT id, bucket FROM moves WHERE state = $1 AND job = ''")
+	setJobStmt = db.Prepare("UPDATE moves SET job = $1 WHERE id = $2")
+	updateStmt = db.Prepare("UPDATE moves SET state = $1, moved = $2, failed = $3 WHERE id = $4")
+	deleteFinishedStmt = db.Prepare("DELETE FROM moves WHERE state != $1 AND created_at < $2")
+
+	processJob = job.Register("objects.move-prefix", run, job.Cleanup(deleteFinished))
+	if err := enqueueWithoutJob(context.Background()); err != nil {
+		slog.Error("unable to queue moves", "error", err)
+	}
+}
+
+// Create creates a new move. The move is processed by a job.
+func Create(ctx context.Context, cmd CreateCommand) (*Move, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	total, err := object.CountPrefix(ctx, cmd.Bucket, cmd.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	m := &Move{
+		ID:          domain.RandomId(),
+		Bucket:      cmd.Bucket,
+		Prefix:      cmd.Prefix,
+		Destination: cmd.Destination,
+		State:       StateProcessing,
+		Total:       int64(total),
+		CreatedAt:   domain.TimeNow(),
+	}
+	if _, err := createStmt.ExecContext(ctx, m.ID, m.Bucket, m.Prefix, m.Destination, m.State, m.Total, m.CreatedAt); err != nil {
+		return nil, fmt.Errorf("unable to create move record: %w", err)
+	}
+	if m.Job, err = enqueue(ctx, m.Bucket, m.ID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// enqueue queues the job that processes a move
+func enqueue(ctx context.Context, bucket, id string) (string, error) {
+	j, err := job.Enqueue(ctx, processJob, bucket, id, processPayload{MoveId: id})
+	if err != nil {
+		return "", err
+	}
+	if _, err := setJobStmt.ExecContext(ctx, j.ID, id); err != nil {
+		return "", fmt.Errorf("unable to update move record: %w", err)
+	}
+	return j.ID, nil
+}
+
+// enqueueWithoutJob queues the jobs of processing moves that don't have one
+func enqueueWithoutJob(ctx context.Context) error {
+	rows, err := findWithoutJobStmt.QueryContext(ctx, StateProcessing)
+	if err != nil {
+		return fmt.Errorf("unable to find moves: %w", err)
+	}
+	type pending struct{ id, bucket string }
+	moves := make([]pending, 0)
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.bucket); err != nil {
+			return fmt.Errorf("unable to decode move row: %w", err)
+		}
+		moves = append(moves, p)
+	}
+	for _, p := range moves {
+		if _, err := enqueue(ctx, p.bucket, p.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindOne finds a move. Returns ec.NoSuchMove if the move cannot be found
+func FindOne(ctx context.Context, bucket, id string) (*Move, error) {
+	m, err := scanRow(findOneStmt.QueryRowContext(ctx, id, bucket))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ec.NoSuchMove
+		}
+		return nil, fmt.Errorf("unable to find move: %w", err)
+	}
+	return m, nil
+}
+
+// run processes a move. The move fails when the last attempt fails and is cancelled if its job is cancelled.
+func run(ctx context.Context, r *job.Run, p processPayload) error {
+	m, err := scanRow(findByIdStmt.QueryRowContext(ctx, p.MoveId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("unable to find move: %w", err)
+	}
+	if m.State != StateProcessing {
+		return nil
+	}
+	err = process(ctx, r, m)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		cancelled(context.WithoutCancel(ctx), r, m)
+		return err
+	}
+	if r.LastAttempt() || job.IsPermanent(err) {
+		m.State = StateFailed
+		if err := save(ctx, m); err != nil {
+			slog.Error("unable to fail move", "move", m.ID, "error", err)
+		}
+	}
+	return err
+}
+
+// cancelled marks a move as cancelled if its job has been cancelled
+func cancelled(ctx context.Context, r *job.Run, m *Move) {
+	j, err := job.FindOne(ctx, r.Job().ID)
+	if err != nil {
+		slog.Error("unable to find move job", "move", m.ID, "error", err)
+		return
+	}
+	if !j.CancelRequested {
+		return
+	}
+	m.State = StateCancelled
+	if err := save(ctx, m); err != nil {
+		slog.Error("unable to cancel move", "move", m.ID, "error", err)
+	}
+}
+
+// process moves all objects under the prefix of m. Objects that have been moved before a restart
+// are no longer under the prefix, so the move resumes where it left off.
+func process(ctx context.Context, r *job.Run, m *Move) error {
+	// objects that failed before a restart are still under the prefix and will be counted again
+	m.Failed = 0
+	startAfter := ""
+	for {
+		objects, err := object.ListPrefix(ctx, m.Bucket, m.Prefix, startAfter, 1000)
+		if err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			break
+		}
+		for _, o := range objects {
+			startAfter = o.Key
+			old := *o
+			key := m.Destination + o.Key[len(m.Prefix):]
+			if err := object.ValidateKey(key); err != nil {
+				// the destination prefix can push long keys over the maximum key length
+				m.Failed++
+				continue
+			}
+			unlock := object.LockKeys(m.Bucket, o.Key, key)
+			err := object.Rename(ctx, o, key)
+			unlock()
+			if err != nil {
+				if errors.Is(err, ec.NoSuchKey) {
+					// the object has been deleted since it was listed
+					continue
+				}
+				if !errors.Is(err, ec.ObjectAlreadyExists) && !errors.Is(err, ec.ObjectLocked) {
+					return err
+				}
+				m.Failed++
+				continue
+			}
+			m.Moved++
+			bus.Publish(ctx, object.EventDeleted, object.Event{Object: &old})
+			bus.Publish(ctx, object.EventCreated, object.Event{Object: o})
+		}
+		if err := save(ctx, m); err != nil {
+			return err
+		}
+		if err := r.Progress(ctx, m.Moved+m.Failed, m.Total); err != nil {
+			return err
+		}
+	}
+
+	m.State = StateComplete
+	if err := save(ctx, m); err != nil {
+		return err
+	}
+	slog.Info("finished move", "move", m.ID, "moved", m.Moved, "failed", m.Failed)
+	return nil
+}
+
+func save(ctx context.Context, m *Move) error {
+	if _, err := updateStmt.ExecContext(ctx, m.State, m.Moved, m.Failed, m.ID); err != nil {
+		return fmt.Errorf("unable to update move record: %w", err)
+	}
+	return nil
+}
+
+func scanRow(row *sql.Row) (*Move, error) {
+	m := Move{}
+	if err := row.Scan(
+		&m.ID,
+		&m.Bucket,
+		&m.Prefix,
+		&m.Destination,
+		&m.State,
+		&m.Total,
+		&m.Moved,
+		&m.Failed,
+		&m.Job,
+		&m.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// deleteFinished deletes the finished moves that have been created before a time
+func deleteFinished(ctx context.Context, before time.Time) error {
+	if _, err := deleteFinishedStmt.ExecContext(ctx, StateProcessing, before); err != nil {
+		return fmt.Errorf("unable to delete finished moves: %w", err)
+	}
+	return nil
+}
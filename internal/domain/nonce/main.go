@@ -18,12 +18,15 @@ import (
 
 type CreateCommand struct {
 	TTL time.Duration
+	// Payload describes the request the nonce grants access to if it doesn't refer to a single object
+	Payload string
 }
 
 type Nonce struct {
 	ID        string
 	Bucket    string
 	Key       string
+	Payload   string
 	ExpiresAt time.Time
 }
 
@@ -36,8 +39,8 @@ var (
 )
 
 func Configure() {
-	createStmt = db.Prepare("INSERT INTO nonces (id, bucket, key, expires_at) VALUES ($1, $2, $3, $4)")
-	findOneStmt = db.Prepare("SELECT id, bucket, key, expires_at FROM nonces WHERE id = $1 LIMIT 1")
+	createStmt = db.Prepare("INSERT INTO nonces (id, bucket, key, payload, expires_at) VALUES ($1, $2, $3, $4, $5)")
+	findOneStmt = db.Prepare("SELECT id, bucket, key, payload, expires_at FROM nonces WHERE id = $1 LIMIT 1")
 	deleteStmt = db.Prepare("DELETE FROM nonces WHERE id = $1")
 	deleteExpiredStmt = db.Prepare("DELETE FROM nonces WHERE expires_at < $1")
 
@@ -49,9 +52,10 @@ func Create(ctx context.Context, bucket, key string, cmd CreateCommand) (*Nonce,
 		ID:        domain.NewId(64),
 		Bucket:    bucket,
 		Key:       key,
+		Payload:   cmd.Payload,
 		ExpiresAt: domain.TimeNow().Add(cmd.TTL),
 	}
-	if _, err := createStmt.ExecContext(ctx, nonce.ID, nonce.Bucket, nonce.Key, nonce.ExpiresAt); err != nil {
+	if _, err := createStmt.ExecContext(ctx, nonce.ID, nonce.Bucket, nonce.Key, nonce.Payload, nonce.ExpiresAt); err != nil {
 		return nil, fmt.Errorf("unable to create nonce record: %w", err)
 	}
 	return nonce, nil
@@ -59,7 +63,7 @@ func Create(ctx context.Context, bucket, key string, cmd CreateCommand) (*Nonce,
 
 func Get(ctx context.Context, id string) (*Nonce, error) {
 	var nonce Nonce
-	if err := findOneStmt.QueryRowContext(ctx, id).Scan(&nonce.ID, &nonce.Bucket, &nonce.Key, &nonce.ExpiresAt); err != nil {
+	if err := findOneStmt.QueryRowContext(ctx, id).Scan(&nonce.ID, &nonce.Bucket, &nonce.Key, &nonce.Payload, &nonce.ExpiresAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
 		}
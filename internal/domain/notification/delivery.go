@@ -0,0 +1,298 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/ec"
+	"github.com/cfichtmueller/stor/internal/util"
+)
+
+const (
+	StatePending = "pending"
+	StateDead    = "dead"
+
+	HeaderDelivery  = "Stor-Delivery"
+	HeaderEvent     = "Stor-Event"
+	HeaderSignature = "Stor-Signature"
+
+	// maxConcurrentDeliveries is the number of deliveries that are sent at the same time
+	maxConcurrentDeliveries = 8
+	deliveryBatchSize       = 100
+)
+
+var (
+	// DeliveryInterval is the time between two runs of the delivery worker
+	DeliveryInterval = time.Second
+	// RetryDelay is the delay before the first retry of a failed delivery. It doubles with every attempt.
+	RetryDelay = 10 * time.Second
+	// MaxRetryDelay is the longest delay between two attempts
+	MaxRetryDelay = time.Hour
+	// MaxAttempts is the number of attempts after which a delivery becomes a dead letter
+	MaxAttempts = 10
+
+	client = &http.Client{Timeout: 10 * time.Second}
+
+	deliveryFields = "d.id, d.webhook, d.bucket, d.event, d.payload, d.state, d.attempts, d.last_error, d.created_at, d.updated_at, w.url, w.secret"
+	// Adds a delivery to the outbox. Input: id, webhook, bucket, event, payload, state, next attempt, now
+	createDeliveryStmt *sql.Stmt
+	// Finds the pending deliveries that are due. Input: now, limit
+	findDueDeliveriesStmt *sql.Stmt
+	// Lists the deliveries of a bucket in a state. Input: bucket, state
+	listDeliveriesStmt *sql.Stmt
+	// Finds a delivery of a bucket. Input: bucket, id
+	findDeliveryStmt *sql.Stmt
+	// Schedules the next attempt of a delivery. Input: state, attempts, next attempt, last error, now, id
+	updateDeliveryStmt *sql.Stmt
+	// Deletes a delivery. Input: id
+	deleteDeliveryStmt *sql.Stmt
+	// Deletes the deliveries of a webhook. Input: webhook id
+	deleteWebhookDeliveriesStmt *sql.Stmt
+	// Deletes the deliveries of a bucket. Input: bucket
+	deleteBucketDeliveriesStmt *sql.Stmt
+)
+
+// Event is the payload of a delivery
+type Event struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Bucket      string    `json:"bucket"`
+	Key         string    `json:"key"`
+	Time        time.Time `json:"time"`
+	Size        int64     `json:"size,omitempty"`
+	ETag        string    `json:"etag,omitempty"`
+	ContentType string    `json:"contentType,omitempty"`
+	VersionId   string    `json:"versionId,omitempty"`
+	ArchiveId   string    `json:"archiveId,omitempty"`
+}
+
+// Delivery is an event in the outbox of a webhook
+type Delivery struct {
+	ID        string
+	Webhook   string
+	Bucket    string
+	Event     string
+	Payload   []byte
+	State     string
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	URL       string
+	secret    string
+}
+
+func configureDeliveries() {
+	createDeliveryStmt = db.Prepare("INSERT INTO webhook_deliveries (id, webhook, bucket, event, payload, state, attempts, next_attempt_at, last_error, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, 0, $7, '', $8, $8)")
+	findDueDeliveriesStmt = db.Prepare("SELECT " + deliveryFields + " FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook WHERE d.state = 'pending' AND d.next_attempt_at <= $1 ORDER BY d.next_attempt_at LIMIT $2")
+	listDeliveriesStmt = db.Prepare("SELECT " + deliveryFields + " FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook WHERE d.bucket = $1 AND d.state = $2 ORDER BY d.updated_at DESC LIMIT 1000")
+	findDeliveryStmt = db.Prepare("SELECT " + deliveryFields + " FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook WHERE d.bucket = $1 AND d.id = $2")
+	updateDeliveryStmt = db.Prepare("UPDATE webhook_deliveries SET state = $1, attempts = $2, next_attempt_at = $3, last_error = $4, updated_at = $5 WHERE id = $6")
+	deleteDeliveryStmt = db.Prepare("DELETE FROM webhook_deliveries WHERE id = $1")
+	deleteWebhookDeliveriesStmt = db.Prepare("DELETE FROM webhook_deliveries WHERE webhook = $1")
+	deleteBucketDeliveriesStmt = db.Prepare("DELETE FROM webhook_deliveries WHERE bucket = $1")
+}
+
+// Notify adds an event to the outbox of every webhook of the event's bucket that subscribes to it
+func Notify(ctx context.Context, e Event) error {
+	webhooks, err := List(ctx, e.Bucket)
+	if err != nil {
+		return err
+	}
+	if e.ID == "" {
+		e.ID = domain.RandomId()
+	}
+	if e.Time.IsZero() {
+		e.Time = domain.TimeNow()
+	}
+	var payload []byte
+	now := domain.TimeNow()
+	for _, w := range webhooks {
+		if !w.Matches(e) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(e); err != nil {
+				return fmt.Errorf("unable to encode event: %w", err)
+			}
+		}
+		if _, err := createDeliveryStmt.ExecContext(ctx, domain.RandomId(), w.ID, e.Bucket, e.Type, string(payload), StatePending, now, now); err != nil {
+			return fmt.Errorf("unable to create webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeadLetters lists the deliveries of a bucket that failed too often
+func DeadLetters(ctx context.Context, bucket string) ([]*Delivery, error) {
+	rows, err := listDeliveriesStmt.QueryContext(ctx, bucket, StateDead)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query webhook deliveries: %w", err)
+	}
+	deliveries := make([]*Delivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
+
+// FindDelivery finds a delivery of a bucket. Returns ec.NoSuchDelivery if the delivery cannot be found
+func FindDelivery(ctx context.Context, bucket, id string) (*Delivery, error) {
+	d, err := scanDelivery(findDeliveryStmt.QueryRowContext(ctx, bucket, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ec.NoSuchDelivery
+		}
+		return nil, fmt.Errorf("unable to find webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+// Redeliver moves a dead letter back into the outbox with a fresh number of attempts
+func Redeliver(ctx context.Context, d *Delivery) error {
+	now := domain.TimeNow()
+	if _, err := updateDeliveryStmt.ExecContext(ctx, StatePending, 0, now, d.LastError, now, d.ID); err != nil {
+		return fmt.Errorf("unable to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// Discard deletes a delivery
+func Discard(ctx context.Context, d *Delivery) error {
+	if _, err := deleteDeliveryStmt.ExecContext(ctx, d.ID); err != nil {
+		return fmt.Errorf("unable to delete webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// Sign computes the signature of a payload. Receivers compare it to the Stor-Signature header.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func worker() {
+	ticker := time.NewTicker(DeliveryInterval)
+	for {
+		<-ticker.C
+		deliverDue()
+	}
+}
+
+// deliverDue sends the pending deliveries that are due
+func deliverDue() {
+	ctx := context.Background()
+	for {
+		rows, err := findDueDeliveriesStmt.QueryContext(ctx, domain.TimeNow(), deliveryBatchSize)
+		if err != nil {
+			slog.Error("unable to find due webhook deliveries", "error", err)
+			return
+		}
+		deliveries := make([]*Delivery, 0)
+		for rows.Next() {
+			d, err := scanDelivery(rows)
+			if err != nil {
+				slog.Error("unable to decode webhook delivery", "error", err)
+				continue
+			}
+			deliveries = append(deliveries, d)
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, maxConcurrentDeliveries)
+		for _, d := range deliveries {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(d *Delivery) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				attempt(ctx, d)
+			}(d)
+		}
+		wg.Wait()
+
+		if len(deliveries) < deliveryBatchSize {
+			return
+		}
+	}
+}
+
+// attempt sends a delivery. Successful deliveries are removed from the outbox, failed ones are retried
+// with exponential backoff until they become dead letters.
+func attempt(ctx context.Context, d *Delivery) {
+	err := send(ctx, d)
+	if err == nil {
+		if err := Discard(ctx, d); err != nil {
+			slog.Error("unable to remove webhook delivery", "delivery", d.ID, "error", err)
+		}
+		return
+	}
+	attempts := d.Attempts + 1
+	state := StatePending
+	if attempts >= MaxAttempts {
+		state = StateDead
+		slog.Warn("webhook delivery failed permanently", "delivery", d.ID, "webhook", d.Webhook, "error", err)
+	}
+	now := domain.TimeNow()
+	if _, err := updateDeliveryStmt.ExecContext(ctx, state, attempts, now.Add(util.Backoff(RetryDelay, MaxRetryDelay, attempts)), err.Error(), now, d.ID); err != nil {
+		slog.Error("unable to update webhook delivery", "delivery", d.ID, "error", err)
+	}
+}
+
+func send(ctx context.Context, d *Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "stor-webhooks")
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderSignature, Sign(d.secret, d.Payload))
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+func scanDelivery(s scanner) (*Delivery, error) {
+	var d Delivery
+	var payload string
+	if err := s.Scan(&d.ID, &d.Webhook, &d.Bucket, &d.Event, &payload, &d.State, &d.Attempts, &d.LastError, &d.CreatedAt, &d.UpdatedAt, &d.URL, &d.secret); err != nil {
+		return nil, err
+	}
+	d.Payload = []byte(payload)
+	return &d, nil
+}
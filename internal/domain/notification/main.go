@@ -0,0 +1,237 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package notification delivers events of a bucket to webhooks. Events are written to an outbox and
+// POSTed as signed JSON with retries, so receivers may see an event more than once. Deliveries that fail
+// too often are kept as dead letters. Objects removed by lifecycle rules or expiration are not notified.
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+const (
+	MaxWebhooks = 20
+
+	EventArchiveCompleted = "archive.completed"
+	EventObjectCreated    = "object.created"
+	EventObjectDeleted    = "object.deleted"
+)
+
+// Events are the event types webhooks can subscribe to
+var Events = []string{EventArchiveCompleted, EventObjectCreated, EventObjectDeleted}
+
+var (
+	webhookFields       = "id, bucket, url, secret, events, prefix, suffix, created_at"
+	createStmt          *sql.Stmt
+	listStmt            *sql.Stmt
+	findOneStmt         *sql.Stmt
+	deleteStmt          *sql.Stmt
+	deleteForBucketStmt *sql.Stmt
+)
+
+// Webhook receives the events of a bucket
+type Webhook struct {
+	ID     string
+	Bucket string
+	URL    string
+	// Secret is the key of the HMAC signature of the deliveries
+	Secret string
+	Events []string
+	// Prefix and Suffix restrict the webhook to objects whose keys start and end with them
+	Prefix    string
+	Suffix    string
+	CreatedAt time.Time
+}
+
+// Matches returns true if the webhook subscribes to an event
+func (w *Webhook) Matches(e Event) bool {
+	return slices.Contains(w.Events, e.Type) && strings.HasPrefix(e.Key, w.Prefix) && strings.HasSuffix(e.Key, w.Suffix)
+}
+
+type WebhookCommand struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Prefix string   `json:"prefix"`
+	Suffix string   `json:"suffix"`
+	// Secret is generated if empty
+	Secret string `json:"secret"`
+}
+
+func (c WebhookCommand) Validate() error {
+	return srv.Validate(c.validate("", nil))
+}
+
+func (c WebhookCommand) validate(field string, v *srv.ValidationError) *srv.ValidationError {
+	u, err := url.Parse(c.URL)
+	v = srv.Require(field+"url", srv.ValidationCodeInvalid, "url must be an absolute http or https url",
+		err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", v)
+	v = srv.RequireMinLengthSlice(field+"events", 1, c.Events, v)
+	for i, e := range c.Events {
+		v = srv.Require(fmt.Sprintf("%sevents[%d]", field, i), srv.ValidationCodeInvalid, "event type is not supported", slices.Contains(Events, e), v)
+	}
+	v = srv.Require(field+"prefix", srv.ValidationCodeInvalid, "prefix must not be longer than 1024 characters", len(c.Prefix) <= 1024, v)
+	v = srv.Require(field+"suffix", srv.ValidationCodeInvalid, "suffix must not be longer than 1024 characters", len(c.Suffix) <= 1024, v)
+	v = srv.Require(field+"secret", srv.ValidationCodeInvalid, "secret must not be longer than 256 characters", len(c.Secret) <= 256, v)
+	return v
+}
+
+// ValidateWebhooks validates a complete set of webhooks of a bucket
+func ValidateWebhooks(webhooks []WebhookCommand) error {
+	v := srv.RequireMaxLengthSlice("webhooks", MaxWebhooks, webhooks, nil)
+	for i, w := range webhooks {
+		v = w.validate(fmt.Sprintf("webhooks[%d].", i), v)
+	}
+	return srv.Validate(v)
+}
+
+func Configure() {
+	createStmt = db.Prepare("INSERT INTO webhooks (" + webhookFields + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)")
+	listStmt = db.Prepare("SELECT " + webhookFields + " FROM webhooks WHERE bucket = $1 ORDER BY created_at, id")
+	findOneStmt = db.Prepare("SELECT " + webhookFields + " FROM webhooks WHERE bucket = $1 AND id = $2")
+	deleteStmt = db.Prepare("DELETE FROM webhooks WHERE bucket = $1 AND id = $2")
+	deleteForBucketStmt = db.Prepare("DELETE FROM webhooks WHERE bucket = $1")
+
+	configureDeliveries()
+
+	go worker()
+}
+
+// List lists the webhooks of a bucket
+func List(ctx context.Context, bucket string) ([]*Webhook, error) {
+	rows, err := listStmt.QueryContext(ctx, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query webhooks: %w", err)
+	}
+	webhooks := make([]*Webhook, 0)
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode webhook: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
+
+// FindOne finds a webhook. Returns ec.NoSuchWebhook if the webhook cannot be found
+func FindOne(ctx context.Context, bucket, id string) (*Webhook, error) {
+	w, err := scanWebhook(findOneStmt.QueryRowContext(ctx, bucket, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ec.NoSuchWebhook
+		}
+		return nil, fmt.Errorf("unable to find webhook: %w", err)
+	}
+	return w, nil
+}
+
+// Add adds a webhook to a bucket
+func Add(ctx context.Context, bucket string, cmd WebhookCommand) (*Webhook, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	existing, err := List(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxWebhooks {
+		return nil, ec.TooManyWebhooks
+	}
+	return create(ctx, bucket, cmd)
+}
+
+// Replace replaces all webhooks of a bucket. Pending deliveries of the replaced webhooks are dropped.
+func Replace(ctx context.Context, bucket string, cmds []WebhookCommand) ([]*Webhook, error) {
+	if err := ValidateWebhooks(cmds); err != nil {
+		return nil, err
+	}
+	if err := DeleteAll(ctx, bucket); err != nil {
+		return nil, err
+	}
+	webhooks := make([]*Webhook, 0, len(cmds))
+	for _, cmd := range cmds {
+		w, err := create(ctx, bucket, cmd)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
+
+func create(ctx context.Context, bucket string, cmd WebhookCommand) (*Webhook, error) {
+	w := &Webhook{
+		ID:        domain.RandomId(),
+		Bucket:    bucket,
+		URL:       cmd.URL,
+		Secret:    cmd.Secret,
+		Events:    cmd.Events,
+		Prefix:    cmd.Prefix,
+		Suffix:    cmd.Suffix,
+		CreatedAt: domain.TimeNow(),
+	}
+	if w.Secret == "" {
+		w.Secret = domain.NewId(32)
+	}
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode webhook events: %w", err)
+	}
+	if _, err := createStmt.ExecContext(ctx, w.ID, w.Bucket, w.URL, w.Secret, string(events), w.Prefix, w.Suffix, w.CreatedAt); err != nil {
+		return nil, fmt.Errorf("unable to create webhook: %w", err)
+	}
+	return w, nil
+}
+
+// Delete deletes a webhook of a bucket and its deliveries
+func Delete(ctx context.Context, bucket, id string) error {
+	if _, err := deleteStmt.ExecContext(ctx, bucket, id); err != nil {
+		return fmt.Errorf("unable to delete webhook: %w", err)
+	}
+	if _, err := deleteWebhookDeliveriesStmt.ExecContext(ctx, id); err != nil {
+		return fmt.Errorf("unable to delete webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll deletes all webhooks of a bucket and their deliveries
+func DeleteAll(ctx context.Context, bucket string) error {
+	if _, err := deleteForBucketStmt.ExecContext(ctx, bucket); err != nil {
+		return fmt.Errorf("unable to delete webhooks: %w", err)
+	}
+	if _, err := deleteBucketDeliveriesStmt.ExecContext(ctx, bucket); err != nil {
+		return fmt.Errorf("unable to delete webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(s scanner) (*Webhook, error) {
+	var w Webhook
+	var events string
+	if err := s.Scan(&w.ID, &w.Bucket, &w.URL, &w.Secret, &events, &w.Prefix, &w.Suffix, &w.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(events), &w.Events); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
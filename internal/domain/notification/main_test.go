@@ -0,0 +1,165 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/config"
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+)
+
+func TestWebhookMatches(t *testing.T) {
+	w := &Webhook{Events: []string{EventObjectCreated}, Prefix: "images/", Suffix: ".jpg"}
+	tests := []struct {
+		event   Event
+		matches bool
+	}{
+		{event: Event{Type: EventObjectCreated, Key: "images/a.jpg"}, matches: true},
+		{event: Event{Type: EventObjectDeleted, Key: "images/a.jpg"}, matches: false},
+		{event: Event{Type: EventObjectCreated, Key: "docs/a.jpg"}, matches: false},
+		{event: Event{Type: EventObjectCreated, Key: "images/a.png"}, matches: false},
+	}
+	for _, test := range tests {
+		if got := w.Matches(test.event); got != test.matches {
+			t.Errorf("expected %+v to match=%v, got %v", test.event, test.matches, got)
+		}
+	}
+}
+
+func TestWebhookCommandValidate(t *testing.T) {
+	tests := []struct {
+		cmd   WebhookCommand
+		valid bool
+	}{
+		{cmd: WebhookCommand{}, valid: false},
+		{cmd: WebhookCommand{URL: "https://example.com/hook"}, valid: false},
+		{cmd: WebhookCommand{URL: "https://example.com/hook", Events: []string{EventObjectCreated}}, valid: true},
+		{cmd: WebhookCommand{URL: "ftp://example.com/hook", Events: []string{EventObjectCreated}}, valid: false},
+		{cmd: WebhookCommand{URL: "/hook", Events: []string{EventObjectCreated}}, valid: false},
+		{cmd: WebhookCommand{URL: "https://example.com/hook", Events: []string{"object.touched"}}, valid: false},
+	}
+	for _, test := range tests {
+		err := test.cmd.Validate()
+		if (err == nil) != test.valid {
+			t.Errorf("expected %+v to be valid=%v, got %v", test.cmd, test.valid, err)
+		}
+	}
+}
+
+func TestDeliveries(t *testing.T) {
+	config.DataDir = os.TempDir()
+	// deliveries are sent by the test
+	DeliveryInterval = time.Hour
+	RetryDelay = 0
+	MaxAttempts = 2
+	db.Configure()
+	Configure()
+	ctx := context.Background()
+	bucket := "notification-test-" + domain.RandomId()
+
+	var mu sync.Mutex
+	status := http.StatusInternalServerError
+	received := make([]*http.Request, 0)
+	bodies := make([][]byte, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	setStatus := func(s int) {
+		mu.Lock()
+		status = s
+		mu.Unlock()
+	}
+
+	w, err := Add(ctx, bucket, WebhookCommand{URL: server.URL, Events: []string{EventObjectCreated}})
+	if err != nil {
+		t.Fatalf("unable to add webhook: %v", err)
+	}
+	defer DeleteAll(ctx, bucket)
+	if w.Secret == "" {
+		t.Errorf("expected a generated secret")
+	}
+
+	if err := Notify(ctx, Event{Type: EventObjectDeleted, Bucket: bucket, Key: "a"}); err != nil {
+		t.Fatalf("unable to notify: %v", err)
+	}
+	if err := Notify(ctx, Event{Type: EventObjectCreated, Bucket: bucket, Key: "a", Size: 3}); err != nil {
+		t.Fatalf("unable to notify: %v", err)
+	}
+
+	// every attempt fails until the delivery is dead
+	for range MaxAttempts {
+		deliverDue()
+	}
+	if len(received) != MaxAttempts {
+		t.Fatalf("expected %d attempts, got %d", MaxAttempts, len(received))
+	}
+	dead, err := DeadLetters(ctx, bucket)
+	if err != nil {
+		t.Fatalf("unable to list dead letters: %v", err)
+	}
+	if len(dead) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(dead))
+	}
+	if dead[0].Attempts != MaxAttempts || dead[0].LastError == "" {
+		t.Errorf("expected dead letter after %d attempts with an error, got %d %q", MaxAttempts, dead[0].Attempts, dead[0].LastError)
+	}
+	deliverDue()
+	if len(received) != MaxAttempts {
+		t.Errorf("expected dead letter not to be sent again")
+	}
+
+	setStatus(http.StatusNoContent)
+	if err := Redeliver(ctx, dead[0]); err != nil {
+		t.Fatalf("unable to redeliver: %v", err)
+	}
+	deliverDue()
+	if len(received) != MaxAttempts+1 {
+		t.Fatalf("expected redelivery, got %d requests", len(received))
+	}
+	r, body := received[MaxAttempts], bodies[MaxAttempts]
+	if got := r.Header.Get(HeaderSignature); got != Sign(w.Secret, body) {
+		t.Errorf("expected signature %s, got %s", Sign(w.Secret, body), got)
+	}
+	if got := r.Header.Get(HeaderEvent); got != EventObjectCreated {
+		t.Errorf("expected event header %s, got %s", EventObjectCreated, got)
+	}
+	if got := r.Header.Get(HeaderDelivery); got != dead[0].ID {
+		t.Errorf("expected delivery header %s, got %s", dead[0].ID, got)
+	}
+	var e Event
+	if err := json.Unmarshal(body, &e); err != nil {
+		t.Fatalf("unable to decode payload: %v", err)
+	}
+	if e.Bucket != bucket || e.Key != "a" || e.Size != 3 || e.ID == "" {
+		t.Errorf("unexpected payload %s", body)
+	}
+
+	if _, err := FindDelivery(ctx, bucket, dead[0].ID); err == nil {
+		t.Errorf("expected delivered event to be removed from the outbox")
+	}
+	dead, err = DeadLetters(ctx, bucket)
+	if err != nil {
+		t.Fatalf("unable to list dead letters: %v", err)
+	}
+	if len(dead) != 0 {
+		t.Errorf("expected no dead letters, got %d", len(dead))
+	}
+}
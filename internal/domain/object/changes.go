@@ -0,0 +1,167 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/config"
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+// ChangesPruneInterval is the time between two removals of changes older than config.ChangesRetention
+var ChangesPruneInterval = time.Hour
+
+var (
+	// Records a change of an object from its current row. Input: type, now, object id
+	insertChangeStmt *sql.Stmt
+	// Lists the changes of a bucket. Input: bucket, after seq, up to seq, limit
+	listChangesStmt *sql.Stmt
+	// Finds the seq of the newest change. Input: none
+	findHeadSeqStmt *sql.Stmt
+	// Finds the seq before the oldest change that is still retained. Input: none
+	findPrunedSeqStmt *sql.Stmt
+	// Deletes all changes up to the newest change created before a time. Input: time
+	pruneChangesStmt *sql.Stmt
+
+	changesMu sync.Mutex
+	// changesSignal is closed and replaced whenever changes are committed
+	changesSignal = make(chan struct{})
+)
+
+// Change is an entry of the change journal. Changes are ordered by Seq without gaps within the retention.
+// A renamed object is recorded as the deletion of the old key and the creation of the new key.
+type Change struct {
+	Seq       int64
+	Bucket    string
+	Key       string
+	Type      string
+	Version   string
+	ETag      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+func configureChanges() {
+	insertChangeStmt = db.Prepare("INSERT INTO object_changes (bucket, key, type, version, etag, size, created_at) SELECT bucket, key, $1, current, etag, size, $2 FROM objects WHERE id = $3")
+	listChangesStmt = db.Prepare("SELECT seq, bucket, key, type, version, etag, size, created_at FROM object_changes WHERE bucket = $1 AND seq > $2 AND seq <= $3 ORDER BY seq LIMIT $4")
+	findHeadSeqStmt = db.Prepare("SELECT COALESCE((SELECT seq FROM sqlite_sequence WHERE name = 'object_changes'), 0)")
+	findPrunedSeqStmt = db.Prepare("SELECT COALESCE((SELECT MIN(seq) - 1 FROM object_changes), (SELECT seq FROM sqlite_sequence WHERE name = 'object_changes'), 0)")
+	pruneChangesStmt = db.Prepare("DELETE FROM object_changes WHERE seq <= (SELECT MAX(seq) FROM object_changes WHERE created_at < $1)")
+
+	go changesWorker()
+}
+
+// Changes lists the changes of a bucket after the change with seq after. The returned cursor is the seq
+// to continue from. It may be ahead of the last change if the journal contains newer changes of other buckets.
+// Returns ec.ExpiredCursor if changes after after have already been removed from the journal
+// and ec.InvalidArgument if after is ahead of the journal.
+func Changes(ctx context.Context, bucketName string, after int64, limit int) ([]*Change, int64, error) {
+	// all changes up to the head are committed because writers are serialized
+	head, err := ChangesHead(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if after > head {
+		return nil, 0, ec.InvalidArgument
+	}
+	rows, err := listChangesStmt.QueryContext(ctx, bucketName, after, head, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to list changes: %w", err)
+	}
+	defer rows.Close()
+	changes := make([]*Change, 0)
+	for rows.Next() {
+		var c Change
+		if err := rows.Scan(&c.Seq, &c.Bucket, &c.Key, &c.Type, &c.Version, &c.ETag, &c.Size, &c.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("unable to decode change: %w", err)
+		}
+		changes = append(changes, &c)
+	}
+	// the journal is checked after listing because a prune in between would remove changes from the list
+	var pruned int64
+	if err := findPrunedSeqStmt.QueryRowContext(ctx).Scan(&pruned); err != nil {
+		return nil, 0, fmt.Errorf("unable to find pruned changes: %w", err)
+	}
+	if after < pruned {
+		return nil, 0, ec.ExpiredCursor
+	}
+	if len(changes) < limit {
+		return changes, head, nil
+	}
+	if len(changes) == 0 {
+		return changes, after, nil
+	}
+	return changes, changes[len(changes)-1].Seq, nil
+}
+
+// ChangesHead returns the seq of the newest change
+func ChangesHead(ctx context.Context) (int64, error) {
+	var head int64
+	if err := findHeadSeqStmt.QueryRowContext(ctx).Scan(&head); err != nil {
+		return 0, fmt.Errorf("unable to find newest change: %w", err)
+	}
+	return head, nil
+}
+
+// ChangesSignal returns a channel that is closed when the next changes are committed
+func ChangesSignal() <-chan struct{} {
+	changesMu.Lock()
+	defer changesMu.Unlock()
+	return changesSignal
+}
+
+// recordChange adds the current state of an object to the change journal
+func recordChange(ctx context.Context, tx *sql.Tx, changeType, objectId string) error {
+	if _, err := tx.StmtContext(ctx, insertChangeStmt).ExecContext(ctx, changeType, domain.TimeNow(), objectId); err != nil {
+		return fmt.Errorf("unable to record change: %w", err)
+	}
+	return nil
+}
+
+// change runs f in a transaction and wakes up waiting readers of the change journal once it is committed
+func change(ctx context.Context, f func(tx *sql.Tx) error) error {
+	if err := db.Tx(ctx, f); err != nil {
+		return err
+	}
+	changesMu.Lock()
+	close(changesSignal)
+	changesSignal = make(chan struct{})
+	changesMu.Unlock()
+	return nil
+}
+
+func changesWorker() {
+	ticker := time.NewTicker(ChangesPruneInterval)
+	for {
+		<-ticker.C
+		pruneChanges()
+	}
+}
+
+// pruneChanges removes the changes that are older than the retention
+func pruneChanges() {
+	res, err := pruneChangesStmt.Exec(domain.TimeNow().Add(-config.ChangesRetention))
+	if err != nil {
+		slog.Error("unable to prune changes", "error", err)
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		slog.Info("pruned changes", "changes", n)
+	}
+}
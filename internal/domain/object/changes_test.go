@@ -0,0 +1,117 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/config"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+func TestChanges(t *testing.T) {
+	ctx := context.Background()
+	bucketName := uniqueString("changes-test")
+	otherBucket := uniqueString("changes-other")
+
+	start := head(t)
+
+	signal := ChangesSignal()
+	o, err := Create(ctx, bucketName, CreateCommand{Key: "a", ContentType: "text/plain", Data: []byte("a")})
+	if err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+	select {
+	case <-signal:
+	default:
+		t.Errorf("expected signal to be closed after a change")
+	}
+	if _, err := Create(ctx, otherBucket, CreateCommand{Key: "other", ContentType: "text/plain", Data: []byte("b")}); err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+	updated, err := Update(ctx, o, UpdateCommand{ContentType: "text/plain", Data: []byte("ab")})
+	if err != nil {
+		t.Fatalf("unable to update object: %v", err)
+	}
+	if err := Rename(ctx, updated, "b"); err != nil {
+		t.Fatalf("unable to rename object: %v", err)
+	}
+	if err := Delete(ctx, updated); err != nil {
+		t.Fatalf("unable to delete object: %v", err)
+	}
+	purge()
+
+	expected := []string{"created a", "updated a", "deleted a", "created b", "deleted b"}
+	changes, cursor, err := Changes(ctx, bucketName, start, 100)
+	if err != nil {
+		t.Fatalf("unable to list changes: %v", err)
+	}
+	if got := describeChanges(changes); !slices.Equal(got, expected) {
+		t.Errorf("expected changes %v, got %v", expected, got)
+	}
+	if changes[1].Size != 2 || changes[1].Version != updated.CurrentVersion || changes[1].ETag != updated.ETag {
+		t.Errorf("expected update to record the new version, got %+v", changes[1])
+	}
+	if cursor != head(t) {
+		t.Errorf("expected cursor to be the head of the journal %d, got %d", head(t), cursor)
+	}
+
+	// pages
+	got := make([]string, 0)
+	after := start
+	for i := 0; i < 10; i++ {
+		page, next, err := Changes(ctx, bucketName, after, 2)
+		if err != nil {
+			t.Fatalf("unable to list changes: %v", err)
+		}
+		got = append(got, describeChanges(page)...)
+		if next == after {
+			break
+		}
+		after = next
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected paged changes %v, got %v", expected, got)
+	}
+
+	if _, _, err := Changes(ctx, bucketName, head(t)+1, 10); !errors.Is(err, ec.InvalidArgument) {
+		t.Errorf("expected cursor ahead of the journal to be invalid, got %v", err)
+	}
+
+	retention := config.ChangesRetention
+	config.ChangesRetention = -time.Hour
+	pruneChanges()
+	config.ChangesRetention = retention
+	if _, _, err := Changes(ctx, bucketName, start, 10); !errors.Is(err, ec.ExpiredCursor) {
+		t.Errorf("expected pruned cursor to be expired, got %v", err)
+	}
+	changes, _, err = Changes(ctx, bucketName, cursor, 10)
+	if err != nil {
+		t.Errorf("expected cursor at the head to be valid after pruning, got %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes after pruning, got %v", describeChanges(changes))
+	}
+}
+
+func head(t *testing.T) int64 {
+	var seq int64
+	if err := findHeadSeqStmt.QueryRow().Scan(&seq); err != nil {
+		t.Fatalf("unable to find head of the journal: %v", err)
+	}
+	return seq
+}
+
+func describeChanges(changes []*Change) []string {
+	d := make([]string, 0, len(changes))
+	for _, c := range changes {
+		d = append(d, c.Type+" "+c.Key)
+	}
+	return d
+}
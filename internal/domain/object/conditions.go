@@ -0,0 +1,48 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"strings"
+
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+// Conditions are the preconditions of a write as given by the If-Match and If-None-Match headers
+type Conditions struct {
+	// IfMatch is either "*" or a list of etags. The object must exist and have one of the etags.
+	IfMatch string
+	// IfNoneMatch is either "*" or a list of etags. The object must not exist or must have none of the etags.
+	IfNoneMatch string
+}
+
+// Check checks the conditions against the current state of an object. o is nil if the object doesn't exist.
+// Returns ec.PreconditionFailed if a condition doesn't hold.
+func (c Conditions) Check(o *Object) error {
+	if c.IfMatch != "" {
+		if o == nil || !etagListMatches(c.IfMatch, o.ETag) {
+			return ec.PreconditionFailed
+		}
+	}
+	if c.IfNoneMatch != "" && o != nil && etagListMatches(c.IfNoneMatch, o.ETag) {
+		return ec.PreconditionFailed
+	}
+	return nil
+}
+
+// etagListMatches reports whether a header value (either "*" or a comma separated list of etags) matches etag
+func etagListMatches(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		candidate = strings.TrimPrefix(candidate, "W/")
+		if strings.Trim(candidate, "\"") == etag {
+			return true
+		}
+	}
+	return false
+}
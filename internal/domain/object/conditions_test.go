@@ -0,0 +1,40 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import "testing"
+
+type conditionsTest struct {
+	conditions Conditions
+	object     *Object
+	ok         bool
+}
+
+func TestConditions(t *testing.T) {
+	o := &Object{ETag: "abc"}
+	tests := []conditionsTest{
+		{conditions: Conditions{}, object: nil, ok: true},
+		{conditions: Conditions{}, object: o, ok: true},
+		{conditions: Conditions{IfNoneMatch: "*"}, object: nil, ok: true},
+		{conditions: Conditions{IfNoneMatch: "*"}, object: o, ok: false},
+		{conditions: Conditions{IfNoneMatch: "\"abc\""}, object: o, ok: false},
+		{conditions: Conditions{IfNoneMatch: "\"def\""}, object: o, ok: true},
+		{conditions: Conditions{IfMatch: "*"}, object: nil, ok: false},
+		{conditions: Conditions{IfMatch: "*"}, object: o, ok: true},
+		{conditions: Conditions{IfMatch: "\"abc\""}, object: o, ok: true},
+		{conditions: Conditions{IfMatch: "abc"}, object: o, ok: true},
+		{conditions: Conditions{IfMatch: "\"def\", \"abc\""}, object: o, ok: true},
+		{conditions: Conditions{IfMatch: "W/\"abc\""}, object: o, ok: true},
+		{conditions: Conditions{IfMatch: "\"def\""}, object: o, ok: false},
+		{conditions: Conditions{IfMatch: "\"abc\""}, object: nil, ok: false},
+	}
+
+	for i, test := range tests {
+		err := test.conditions.Check(test.object)
+		if (err == nil) != test.ok {
+			t.Errorf("Test %d: %+v returned %v, expected ok to be %v", i, test.conditions, err, test.ok)
+		}
+	}
+}
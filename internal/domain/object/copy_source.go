@@ -0,0 +1,46 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"strings"
+
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+const copySourceVersionParam = "?version-id="
+
+// CopySource identifies the object a copy reads from
+type CopySource struct {
+	Bucket    string
+	Key       string
+	VersionId string
+}
+
+// ParseCopySource parses a copy source of the form [/bucket/]key[?version-id=id].
+// A source without a leading slash refers to a key in defaultBucket.
+func ParseCopySource(source, defaultBucket string) (CopySource, error) {
+	s := CopySource{Bucket: defaultBucket}
+	if i := strings.LastIndex(source, copySourceVersionParam); i >= 0 {
+		s.VersionId = source[i+len(copySourceVersionParam):]
+		source = source[:i]
+		if s.VersionId == "" {
+			return CopySource{}, ec.InvalidArgument
+		}
+	}
+	if strings.HasPrefix(source, "/") {
+		bucketName, key, ok := strings.Cut(source[1:], "/")
+		if !ok || bucketName == "" {
+			return CopySource{}, ec.InvalidArgument
+		}
+		s.Bucket = bucketName
+		source = key
+	}
+	if source == "" {
+		return CopySource{}, ec.InvalidArgument
+	}
+	s.Key = source
+	return s, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import "testing"
+
+type copySourceTest struct {
+	source   string
+	expected CopySource
+	valid    bool
+}
+
+func TestParseCopySource(t *testing.T) {
+	tests := []copySourceTest{
+		{source: "", valid: false},
+		{source: "/", valid: false},
+		{source: "/other", valid: false},
+		{source: "/other/", valid: false},
+		{source: "//key", valid: false},
+		{source: "key?version-id=", valid: false},
+		{source: "key", valid: true, expected: CopySource{Bucket: "default", Key: "key"}},
+		{source: "docs/a.pdf", valid: true, expected: CopySource{Bucket: "default", Key: "docs/a.pdf"}},
+		{source: "docs/2026/a.pdf?version-id=abc", valid: true, expected: CopySource{Bucket: "default", Key: "docs/2026/a.pdf", VersionId: "abc"}},
+		{source: "/other/docs/a.pdf", valid: true, expected: CopySource{Bucket: "other", Key: "docs/a.pdf"}},
+		{source: "/other/a.pdf?version-id=abc", valid: true, expected: CopySource{Bucket: "other", Key: "a.pdf", VersionId: "abc"}},
+		{source: "a.pdf?version-id=abc", valid: true, expected: CopySource{Bucket: "default", Key: "a.pdf", VersionId: "abc"}},
+	}
+
+	for _, test := range tests {
+		actual, err := ParseCopySource(test.source, "default")
+		if (err == nil) != test.valid {
+			t.Errorf("Source '%s' valid is %v, expected %v", test.source, err == nil, test.valid)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("Source '%s' parsed to %+v, expected %+v", test.source, actual, test.expected)
+		}
+	}
+}
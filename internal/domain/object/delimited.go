@@ -0,0 +1,96 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	minDelimitedBatch = 16
+	maxDelimitedBatch = 1000
+)
+
+// DelimitedList is a page of objects and common prefixes
+type DelimitedList struct {
+	Objects        []*Object
+	CommonPrefixes []string
+	// IsTruncated is true if there are more objects or common prefixes after NextMarker
+	IsTruncated bool
+	// NextMarker is the last key or common prefix of the page. It is empty if the list is not truncated.
+	NextMarker string
+}
+
+// ListDelimited lists the objects of a bucket that match f and rolls up all keys that contain delimiter after
+// f.Prefix into common prefixes. Objects and common prefixes are returned in key order and both count
+// towards maxKeys. startAfter may be a key or a common prefix of a previous page.
+//
+// Instead of scanning all keys below a common prefix, the listing seeks past the prefix with a new range query.
+func ListDelimited(ctx context.Context, bucketName string, f Filter, delimiter, startAfter string, maxKeys int) (*DelimitedList, error) {
+	l := &DelimitedList{
+		Objects:        make([]*Object, 0),
+		CommonPrefixes: make([]string, 0),
+	}
+	from, inclusive := startAfter, false
+	if cp := commonPrefix(f.Prefix, delimiter, startAfter); cp != "" {
+		// the previous page ended with the common prefix or a key within it
+		if from = PrefixEnd(cp); from == "" {
+			return l, nil
+		}
+		inclusive = true
+	}
+	last := ""
+	// Rows after a seek often belong to the next common prefix, so the batch size starts small and grows
+	// while the listing encounters objects.
+	batch := minDelimitedBatch
+	for {
+		limit := maxKeys - len(l.Objects) - len(l.CommonPrefixes) + 1
+		objects, err := listFrom(ctx, bucketName, f, from, inclusive, min(limit, batch))
+		if err != nil {
+			return nil, err
+		}
+		if len(objects) == 0 {
+			return l, nil
+		}
+		seek := false
+		for _, o := range objects {
+			if len(l.Objects)+len(l.CommonPrefixes) == maxKeys {
+				l.IsTruncated = true
+				l.NextMarker = last
+				return l, nil
+			}
+			if cp := commonPrefix(f.Prefix, delimiter, o.Key); cp != "" {
+				l.CommonPrefixes = append(l.CommonPrefixes, cp)
+				last = cp
+				if from = PrefixEnd(cp); from == "" {
+					return l, nil
+				}
+				inclusive, seek = true, true
+				batch = minDelimitedBatch
+				break
+			}
+			l.Objects = append(l.Objects, o)
+			last = o.Key
+		}
+		if !seek {
+			from, inclusive = last, false
+			batch = min(batch*2, maxDelimitedBatch)
+		}
+	}
+}
+
+// commonPrefix returns the common prefix that key is rolled up into. Returns an empty string if the key
+// is not below prefix or doesn't contain delimiter after prefix.
+func commonPrefix(prefix, delimiter, key string) string {
+	if delimiter == "" || !strings.HasPrefix(key, prefix) {
+		return ""
+	}
+	i := strings.Index(key[len(prefix):], delimiter)
+	if i < 0 {
+		return ""
+	}
+	return key[:len(prefix)+i+len(delimiter)]
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+)
+
+func TestListDelimited(t *testing.T) {
+	ctx := context.Background()
+	bucketName := uniqueString("delimited-test")
+	insertObjects(t, bucketName, []string{
+		"documents/2020/contract.pdf",
+		"documents/2021/contract.pdf",
+		"documents/2021/info.pdf",
+		"image.jpg",
+		"photos/2020/avatar.jpg",
+		"photos/2021/avatar.jpg",
+		"photos/avatar.jpg",
+		"photos/group.jpg",
+		"zebra.jpg",
+	})
+
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{prefix: "", expected: []string{"documents/", "image.jpg", "photos/", "zebra.jpg"}},
+		{prefix: "documents/", expected: []string{"documents/2020/", "documents/2021/"}},
+		{prefix: "photos/", expected: []string{"photos/2020/", "photos/2021/", "photos/avatar.jpg", "photos/group.jpg"}},
+		{prefix: "photos/2021/", expected: []string{"photos/2021/avatar.jpg"}},
+		{prefix: "videos/", expected: []string{}},
+	}
+
+	for _, test := range tests {
+		// every page size has to produce the same listing
+		for maxKeys := 1; maxKeys <= len(test.expected)+1; maxKeys++ {
+			actual := make([]string, 0)
+			startAfter := ""
+			for {
+				l, err := ListDelimited(ctx, bucketName, Filter{Prefix: test.prefix}, "/", startAfter, maxKeys)
+				if err != nil {
+					t.Fatalf("unable to list objects: %v", err)
+				}
+				page := listEntries(l)
+				if len(page) > maxKeys {
+					t.Errorf("prefix %q: expected at most %d entries, got %v", test.prefix, maxKeys, page)
+				}
+				actual = append(actual, page...)
+				if !l.IsTruncated {
+					break
+				}
+				if l.NextMarker != page[len(page)-1] {
+					t.Errorf("prefix %q: expected next marker %q, got %q", test.prefix, page[len(page)-1], l.NextMarker)
+				}
+				startAfter = l.NextMarker
+			}
+			if !slices.Equal(actual, test.expected) {
+				t.Errorf("prefix %q, max keys %d: expected %v, got %v", test.prefix, maxKeys, test.expected, actual)
+			}
+		}
+	}
+}
+
+func BenchmarkListDelimited(b *testing.B) {
+	ctx := context.Background()
+	bucketName := benchmarkBucket(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l, err := ListDelimited(ctx, bucketName, Filter{}, "/", "", 1000)
+		if err != nil {
+			b.Fatalf("unable to list objects: %v", err)
+		}
+		if len(l.CommonPrefixes) != benchmarkFolders {
+			b.Fatalf("expected %d common prefixes, got %d", benchmarkFolders, len(l.CommonPrefixes))
+		}
+	}
+}
+
+const (
+	benchmarkFolders = 100
+	benchmarkFiles   = 1000
+)
+
+var benchmarkBucketName string
+
+// benchmarkBucket returns a bucket with benchmarkFolders folders of benchmarkFiles objects each
+func benchmarkBucket(b *testing.B) string {
+	if benchmarkBucketName != "" {
+		return benchmarkBucketName
+	}
+	bucketName := uniqueString("delimited-benchmark")
+	// the rows are generated in a single statement since inserting them one by one takes minutes
+	stmt := db.Prepare(`INSERT INTO objects (id, bucket, key, etag, content_type, size, created_at, is_deleted, current)
+		WITH RECURSIVE n(i) AS (SELECT 0 UNION ALL SELECT i + 1 FROM n WHERE i + 1 < ?1 * ?2)
+		SELECT lower(hex(randomblob(16))), ?3, printf('folder-%03d/file-%05d', i / ?2, i % ?2), lower(hex(randomblob(32))), 'text/plain', 0, ?4, false, lower(hex(randomblob(16))) FROM n`)
+	defer stmt.Close()
+	if _, err := stmt.Exec(benchmarkFolders, benchmarkFiles, bucketName, domain.TimeNow()); err != nil {
+		b.Fatalf("unable to insert objects: %v", err)
+	}
+	benchmarkBucketName = bucketName
+	return bucketName
+}
+
+// insertObjects inserts object rows without data
+func insertObjects(t testing.TB, bucketName string, keys []string) {
+	stmt := db.Prepare("INSERT INTO objects (id, bucket, key, etag, content_type, size, created_at, is_deleted, current) VALUES ($1, $2, $3, $4, $5, 0, $6, false, $7)")
+	defer stmt.Close()
+	now := domain.TimeNow()
+	for _, key := range keys {
+		if _, err := stmt.Exec(domain.RandomId(), bucketName, key, domain.NewEtag(), "text/plain", now, domain.RandomId()); err != nil {
+			t.Fatalf("unable to insert object: %v", err)
+		}
+	}
+}
+
+func listEntries(l *DelimitedList) []string {
+	entries := make([]string, 0, len(l.Objects)+len(l.CommonPrefixes))
+	for _, o := range l.Objects {
+		entries = append(entries, o.Key)
+	}
+	entries = append(entries, l.CommonPrefixes...)
+	slices.Sort(entries)
+	return entries
+}
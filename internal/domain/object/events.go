@@ -0,0 +1,19 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import "github.com/cfichtmueller/stor/internal/bus"
+
+var (
+	// EventCreated indicates that an object has been created or overwritten
+	EventCreated = bus.NewDurableTopic[Event]("object.created")
+	// EventDeleted indicates that an object has been deleted
+	EventDeleted = bus.NewDurableTopic[Event]("object.deleted")
+)
+
+// Event is the data of EventCreated and EventDeleted
+type Event struct {
+	Object *Object
+}
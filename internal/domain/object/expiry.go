@@ -0,0 +1,157 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/bus"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+var (
+	// EventExpired indicates that objects of a bucket have been deleted because they expired
+	EventExpired = bus.NewDurableTopic[ExpiredEvent]("object.expired")
+	// ExpiryInterval is the time between two expiry sweeps
+	ExpiryInterval = 10 * time.Second
+)
+
+type ExpiredEvent struct {
+	Bucket  string
+	Objects int
+}
+
+// ParseExpiration determines the expiration time of an object from an absolute time in RFC 3339 format
+// or a time to live in seconds. Returns nil if both are empty and ec.InvalidExpiration if they are invalid,
+// both set or not in the future.
+func ParseExpiration(expiresAt, ttl string) (*time.Time, error) {
+	if expiresAt == "" && ttl == "" {
+		return nil, nil
+	}
+	if expiresAt != "" && ttl != "" {
+		return nil, ec.InvalidExpiration
+	}
+	now := domain.TimeNow()
+	var t time.Time
+	if expiresAt != "" {
+		parsed, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			return nil, ec.InvalidExpiration
+		}
+		t = parsed.UTC().Truncate(time.Millisecond)
+	} else {
+		seconds, err := strconv.ParseInt(ttl, 10, 64)
+		if err != nil {
+			return nil, ec.InvalidExpiration
+		}
+		t = now.Add(time.Duration(seconds) * time.Second)
+	}
+	if !t.After(now) {
+		return nil, ec.InvalidExpiration
+	}
+	return &t, nil
+}
+
+// deleteExpiredKey marks an expired object that still holds key as deleted so that the key can be reused.
+// Returns ec.ObjectLocked if the expired object is under retention or legal hold.
+func deleteExpiredKey(ctx context.Context, tx *sql.Tx, bucketName, key string) error {
+	now := domain.TimeNow()
+	var locked int
+	if err := tx.StmtContext(ctx, countLockedExpiredKeyStmt).QueryRowContext(ctx, bucketName, key, now).Scan(&locked); err != nil {
+		return fmt.Errorf("unable to count locked expired objects: %w", err)
+	}
+	if locked > 0 {
+		return ec.ObjectLocked
+	}
+	rows, err := tx.StmtContext(ctx, markExpiredKeyDeletedStmt).QueryContext(ctx, bucketName, key, now)
+	if err != nil {
+		return fmt.Errorf("unable to delete expired object: %w", err)
+	}
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("unable to delete expired object: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("unable to delete expired object: %w", err)
+	}
+	for _, id := range ids {
+		if err := recordChange(ctx, tx, ChangeDeleted, id); err != nil {
+			return err
+		}
+	}
+	if len(ids) > 0 {
+		triggerPurge()
+	}
+	return nil
+}
+
+func expiryWorker() {
+	ticker := time.NewTicker(ExpiryInterval)
+	for {
+		<-ticker.C
+		sweepExpired()
+	}
+}
+
+// sweepExpired marks all expired objects as deleted. Their chunks are reclaimed by the purge.
+func sweepExpired() {
+	ctx := context.Background()
+	expired := make(map[string]int)
+	for {
+		rows, err := findExpiredStmt.QueryContext(ctx, domain.TimeNow())
+		if err != nil {
+			slog.Error("unable to find expired objects", "error", err)
+			break
+		}
+		type ref struct{ id, bucket string }
+		refs := make([]ref, 0)
+		for rows.Next() {
+			var r ref
+			if err := rows.Scan(&r.id, &r.bucket); err != nil {
+				slog.Error("unable to decode expired object", "error", err)
+				break
+			}
+			refs = append(refs, r)
+		}
+		deleted := 0
+		for _, r := range refs {
+			err := change(ctx, func(tx *sql.Tx) error {
+				if _, err := tx.StmtContext(ctx, markObjectDeletedStmt).ExecContext(ctx, r.id); err != nil {
+					return err
+				}
+				return recordChange(ctx, tx, ChangeDeleted, r.id)
+			})
+			if err != nil {
+				slog.Error("unable to delete expired object", "object", r.id, "error", err)
+				continue
+			}
+			expired[r.bucket]++
+			deleted++
+		}
+		if deleted == 0 {
+			break
+		}
+	}
+	if len(expired) == 0 {
+		return
+	}
+	triggerPurge()
+	for b, n := range expired {
+		slog.Info("deleted expired objects", "bucket", b, "objects", n)
+		bus.Publish(ctx, EventExpired, ExpiredEvent{Bucket: b, Objects: n})
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExpiration(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		expiresAt string
+		ttl       string
+		valid     bool
+		expires   bool
+	}{
+		{expiresAt: "", ttl: "", valid: true, expires: false},
+		{expiresAt: future, ttl: "", valid: true, expires: true},
+		{expiresAt: "", ttl: "60", valid: true, expires: true},
+		{expiresAt: past, ttl: "", valid: false},
+		{expiresAt: "", ttl: "0", valid: false},
+		{expiresAt: "", ttl: "-5", valid: false},
+		{expiresAt: "", ttl: "1h", valid: false},
+		{expiresAt: "tomorrow", ttl: "", valid: false},
+		{expiresAt: future, ttl: "60", valid: false},
+	}
+
+	for _, test := range tests {
+		exp, err := ParseExpiration(test.expiresAt, test.ttl)
+		if (err == nil) != test.valid {
+			t.Errorf("expected %q/%q to be valid=%v, got %v", test.expiresAt, test.ttl, test.valid, err)
+			continue
+		}
+		if test.valid && (exp != nil) != test.expires {
+			t.Errorf("expected %q/%q to expire=%v, got %v", test.expiresAt, test.ttl, test.expires, exp)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+)
+
+// Filter restricts the objects of a bucket. The zero value matches all objects.
+type Filter struct {
+	Prefix string
+	// Tags contains the tags an object must have. All tags have to match.
+	Tags map[string]string
+	// CreatedBefore matches objects that have been created before a point in time if not nil
+	CreatedBefore *time.Time
+	// CreatedAfter matches objects that have been created after a point in time if not nil
+	CreatedAfter *time.Time
+	// MinSize matches objects with at least the given size if not nil
+	MinSize *int64
+	// MaxSize matches objects with at most the given size if not nil
+	MaxSize *int64
+	// ContentType matches objects with the content type. A type with a wildcard subtype like "image/*"
+	// matches all subtypes.
+	ContentType string
+	// Delimiter and Depth match objects with less than Depth delimiters in the part of their keys after Prefix.
+	// Depth 1 matches the objects directly under Prefix. The depth is not restricted if Depth is 0.
+	Delimiter string
+	Depth     int
+}
+
+// where builds the conditions of a query on the objects table. The conditions start with " AND" and
+// qualify their columns so that they can be used in joins.
+func (f Filter) where() (string, []any) {
+	var sb strings.Builder
+	args := make([]any, 0)
+	if f.Prefix != "" {
+		sb.WriteString(" AND objects.key >= ?")
+		args = append(args, f.Prefix)
+		if end := PrefixEnd(f.Prefix); end != "" {
+			sb.WriteString(" AND objects.key < ?")
+			args = append(args, end)
+		}
+	}
+	if f.CreatedBefore != nil {
+		sb.WriteString(" AND objects.created_at < ?")
+		args = append(args, *f.CreatedBefore)
+	}
+	if f.CreatedAfter != nil {
+		sb.WriteString(" AND objects.created_at > ?")
+		args = append(args, *f.CreatedAfter)
+	}
+	if f.MinSize != nil {
+		sb.WriteString(" AND objects.size >= ?")
+		args = append(args, *f.MinSize)
+	}
+	if f.MaxSize != nil {
+		sb.WriteString(" AND objects.size <= ?")
+		args = append(args, *f.MaxSize)
+	}
+	if t, ok := strings.CutSuffix(f.ContentType, "/*"); ok {
+		sb.WriteString(" AND objects.content_type >= ? AND objects.content_type < ?")
+		args = append(args, t+"/", t+"0")
+	} else if f.ContentType != "" {
+		sb.WriteString(" AND objects.content_type = ?")
+		args = append(args, f.ContentType)
+	}
+	if f.Delimiter != "" && f.Depth > 0 {
+		// SUBSTR counts characters from 1
+		sb.WriteString(" AND (LENGTH(SUBSTR(objects.key, ?)) - LENGTH(REPLACE(SUBSTR(objects.key, ?), ?, ''))) / LENGTH(?) < ?")
+		start := utf8.RuneCountInString(f.Prefix) + 1
+		args = append(args, start, start, f.Delimiter, f.Delimiter, f.Depth)
+	}
+	for _, k := range sortedKeys(f.Tags) {
+		sb.WriteString(" AND EXISTS (SELECT 1 FROM object_tags t WHERE t.object = objects.id AND t.key = ? AND t.value = ?)")
+		args = append(args, k, f.Tags[k])
+	}
+	return sb.String(), args
+}
+
+// ListFiltered lists the objects of a bucket that match f
+func ListFiltered(ctx context.Context, bucketName string, f Filter, startAfter string, limit int) ([]*Object, error) {
+	return listFrom(ctx, bucketName, f, startAfter, false, limit)
+}
+
+// listFrom lists the objects of a bucket that match f and whose keys are greater than from.
+// Keys equal to from are included if inclusive is true.
+func listFrom(ctx context.Context, bucketName string, f Filter, from string, inclusive bool, limit int) ([]*Object, error) {
+	op := ">"
+	if inclusive {
+		op = ">="
+	}
+	where, args := f.where()
+	query := "SELECT " + objectFields + " FROM objects WHERE bucket = ? AND is_deleted = false AND key " + op + " ? AND (expires_at IS NULL OR expires_at > ?)" +
+		where + " ORDER BY key LIMIT ?"
+	args = append([]any{bucketName, from, domain.TimeNow()}, args...)
+	args = append(args, limit)
+	return decodeRows(db.QueryContext(ctx, query, args...))
+}
+
+// CountFiltered counts the objects of a bucket that match f
+func CountFiltered(ctx context.Context, bucketName string, f Filter, startAfter string) (int, error) {
+	where, args := f.where()
+	query := "SELECT COUNT(*) FROM objects WHERE bucket = ? AND is_deleted = false AND key > ? AND (expires_at IS NULL OR expires_at > ?)" + where
+	args = append([]any{bucketName, startAfter, domain.TimeNow()}, args...)
+	var count int
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("unable to count objects: %w", err)
+	}
+	return count, nil
+}
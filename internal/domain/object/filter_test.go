@@ -0,0 +1,60 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+)
+
+func TestListFilteredDepth(t *testing.T) {
+	ctx := context.Background()
+	bucketName := uniqueString("depth-test")
+	now := domain.TimeNow()
+	stmt := db.Prepare("INSERT INTO objects (id, bucket, key, etag, content_type, size, created_at, is_deleted, current) VALUES ($1, $2, $3, $4, 'text/plain', 1, $5, false, $6)")
+	defer stmt.Close()
+	for _, key := range []string{"a.txt", "docs/b.txt", "docs/2026/c.txt", "docs/2026/03/d.txt", "fotos/ä/e.jpg", "fotos/f.jpg"} {
+		if _, err := stmt.Exec(domain.RandomId(), bucketName, key, domain.NewEtag(), now, domain.RandomId()); err != nil {
+			t.Fatalf("unable to insert object: %v", err)
+		}
+	}
+
+	tests := []struct {
+		filter   Filter
+		expected []string
+	}{
+		{filter: Filter{Delimiter: "/"}, expected: []string{"a.txt", "docs/2026/03/d.txt", "docs/2026/c.txt", "docs/b.txt", "fotos/f.jpg", "fotos/ä/e.jpg"}},
+		{filter: Filter{Delimiter: "/", Depth: 1}, expected: []string{"a.txt"}},
+		{filter: Filter{Delimiter: "/", Depth: 2}, expected: []string{"a.txt", "docs/b.txt", "fotos/f.jpg"}},
+		{filter: Filter{Prefix: "docs/", Delimiter: "/", Depth: 1}, expected: []string{"docs/b.txt"}},
+		{filter: Filter{Prefix: "docs/", Delimiter: "/", Depth: 2}, expected: []string{"docs/2026/c.txt", "docs/b.txt"}},
+		{filter: Filter{Prefix: "fotos/ä/", Delimiter: "/", Depth: 1}, expected: []string{"fotos/ä/e.jpg"}},
+	}
+
+	for _, test := range tests {
+		objects, err := ListFiltered(ctx, bucketName, test.filter, "", 100)
+		if err != nil {
+			t.Fatalf("unable to list objects: %v", err)
+		}
+		actual := make([]string, 0, len(objects))
+		for _, o := range objects {
+			actual = append(actual, o.Key)
+		}
+		if !slices.Equal(actual, test.expected) {
+			t.Errorf("%+v: expected %v, got %v", test.filter, test.expected, actual)
+		}
+		count, err := CountFiltered(ctx, bucketName, test.filter, "")
+		if err != nil {
+			t.Fatalf("unable to count objects: %v", err)
+		}
+		if count != len(test.expected) {
+			t.Errorf("%+v: expected a count of %d, got %d", test.filter, len(test.expected), count)
+		}
+	}
+}
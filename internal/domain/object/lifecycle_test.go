@@ -2,23 +2,21 @@ package object
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
-	"os"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/cfichtmueller/stor/internal/config"
 	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
 	"github.com/cfichtmueller/stor/internal/domain/chunk"
+	"github.com/cfichtmueller/stor/internal/ec"
 )
 
 func Test_lifecycle(t *testing.T) {
-	config.DataDir = os.TempDir()
 	ctx := context.Background()
 	bucketName := "lifecycle-test"
-	db.Configure()
-	chunk.Configure()
-	Configure()
 
 	initialObjects := countRows(t, objectsTable)
 	initialVersions := countRows(t, objectVersionsTable)
@@ -68,6 +66,409 @@ func Test_lifecycle(t *testing.T) {
 	expectRows(t, "delete object", chunksTable, initialChunks)
 }
 
+func Test_chunkManifestLifecycle(t *testing.T) {
+	ctx := context.Background()
+	bucketName := "lifecycle-test"
+
+	initialObjects := countRows(t, objectsTable)
+	initialChunks := countRows(t, chunksTable)
+
+	parts := [][]byte{
+		[]byte(uniqueString("first part ")),
+		[]byte(uniqueString("second part ")),
+	}
+	ids := make([]string, 0, len(parts))
+	var size int64
+	for _, p := range parts {
+		id := fmt.Sprintf("%x", sha256.Sum256(p))
+		if err := chunk.Stage(ctx, id, p); err != nil {
+			t.Fatalf("unable to stage chunk: %v", err)
+		}
+		ids = append(ids, id)
+		size += int64(len(p))
+	}
+
+	if err := chunk.Stage(ctx, ids[0], parts[1]); err != chunk.ErrHashMismatch {
+		t.Errorf("expected hash mismatch, got %v", err)
+	}
+
+	missing, err := chunk.Reference(ctx, append(ids, strings.Repeat("0", 64)))
+	if err != nil {
+		t.Fatalf("unable to reference chunks: %v", err)
+	}
+	if len(missing) != 1 {
+		t.Fatalf("expected 1 missing chunk, got %d", len(missing))
+	}
+
+	missing, err = chunk.Reference(ctx, ids)
+	if err != nil || len(missing) != 0 {
+		t.Fatalf("unable to reference chunks: %v, missing %v", err, missing)
+	}
+
+	o, err := CreateWithChunks(ctx, bucketName, ids, CreateCommand{
+		Key:         uniqueString("m-"),
+		ContentType: "text/plain",
+		Size:        size,
+	})
+	if err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+
+	refs, err := Chunks(ctx, o)
+	if err != nil {
+		t.Fatalf("unable to list chunks: %v", err)
+	}
+	if len(refs) != len(ids) || refs[0].ID != ids[0] || refs[1].ID != ids[1] {
+		t.Errorf("unexpected chunk list %v", refs)
+	}
+
+	expectRows(t, "commit manifest", objectsTable, initialObjects+1)
+	expectRows(t, "commit manifest", chunksTable, initialChunks+2)
+
+	if err := Delete(ctx, o); err != nil {
+		t.Errorf("unable to delete object: %v", err)
+	}
+
+	purge()
+
+	expectRows(t, "delete object", objectsTable, initialObjects)
+	expectRows(t, "delete object", chunksTable, initialChunks)
+}
+
+func Test_expiryLifecycle(t *testing.T) {
+	ctx := context.Background()
+	bucketName := "lifecycle-test"
+
+	initialObjects := countRows(t, objectsTable)
+	initialChunks := countRows(t, chunksTable)
+
+	key := uniqueString("e-")
+	expiresAt := domain.TimeNow().Add(-time.Second)
+	if _, err := Create(ctx, bucketName, CreateCommand{
+		Key:         key,
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Expired ")),
+		ExpiresAt:   &expiresAt,
+	}); err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+
+	if _, err := FindOne(ctx, bucketName, key, false); err != ec.NoSuchKey {
+		t.Errorf("expected expired object to be hidden, got %v", err)
+	}
+
+	o, err := Create(ctx, bucketName, CreateCommand{
+		Key:         key,
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Fresh ")),
+	})
+	if err != nil {
+		t.Fatalf("unable to recreate object: %v", err)
+	}
+	found, err := FindOne(ctx, bucketName, key, false)
+	if err != nil || found.ID != o.ID {
+		t.Fatalf("expected to find recreated object, got %v", err)
+	}
+
+	sweepExpired()
+	purge()
+
+	expectRows(t, "expire object", objectsTable, initialObjects+1)
+	expectRows(t, "expire object", chunksTable, initialChunks+1)
+
+	if err := Delete(ctx, o); err != nil {
+		t.Errorf("unable to delete object: %v", err)
+	}
+
+	purge()
+
+	expectRows(t, "delete object", objectsTable, initialObjects)
+	expectRows(t, "delete object", chunksTable, initialChunks)
+}
+
+func Test_expiredLockedLifecycle(t *testing.T) {
+	ctx := context.Background()
+	bucketName := uniqueString("expired-lock-test")
+	key := uniqueString("el-")
+
+	retention, err := NewRetention(RetentionCompliance, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unable to create retention: %v", err)
+	}
+	expiresAt := domain.TimeNow().Add(-time.Second)
+	if _, err := Create(ctx, bucketName, CreateCommand{
+		Key:         key,
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Retained ")),
+		ExpiresAt:   &expiresAt,
+		Retention:   retention,
+	}); err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+
+	if _, err := Create(ctx, bucketName, CreateCommand{
+		Key:         key,
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Overwrite ")),
+	}); err != ec.ObjectLocked {
+		t.Errorf("expected overwriting an expired retained object to fail with ObjectLocked, got %v", err)
+	}
+
+	other, err := Create(ctx, bucketName, CreateCommand{
+		Key:         uniqueString("el-other-"),
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Other ")),
+	})
+	if err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+	if err := Rename(ctx, other, key); err != ec.ObjectLocked {
+		t.Errorf("expected renaming onto an expired retained object to fail with ObjectLocked, got %v", err)
+	}
+
+	var live int
+	if err := db.QueryRow("SELECT COUNT(*) FROM objects WHERE bucket = $1 AND key = $2 AND is_deleted = false", bucketName, key).Scan(&live); err != nil {
+		t.Fatalf("unable to count objects: %v", err)
+	}
+	if live != 1 {
+		t.Errorf("expected the retained object to be kept, got %d live objects", live)
+	}
+}
+
+func Test_copyOntoLockedObject(t *testing.T) {
+	ctx := context.Background()
+	bucketName := uniqueString("copy-lock-test")
+
+	src, err := Create(ctx, bucketName, CreateCommand{
+		Key:         uniqueString("src-"),
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Source ")),
+	})
+	if err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+	dest, err := Create(ctx, bucketName, CreateCommand{
+		Key:         uniqueString("dest-"),
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Destination ")),
+		LegalHold:   true,
+	})
+	if err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+	chunkIds, err := findObjectChunks(ctx, src.CurrentVersion)
+	if err != nil {
+		t.Fatalf("unable to find object chunks: %v", err)
+	}
+
+	if _, err := UpdateFromCopy(ctx, src, dest, UpdateCommand{}); err != ec.ObjectLocked {
+		t.Errorf("expected copying onto a held object to fail with ObjectLocked, got %v", err)
+	}
+	if _, err := Copy(ctx, src, CopyCommand{Bucket: bucketName, Key: dest.Key}); err == nil {
+		t.Errorf("expected creating a copy with the key of a live object to fail")
+	}
+
+	var rc int
+	if err := db.QueryRow("SELECT rc FROM chunks WHERE id = $1", chunkIds[0]).Scan(&rc); err != nil {
+		t.Fatalf("unable to find chunk: %v", err)
+	}
+	if rc != 1 {
+		t.Errorf("expected failed copies to release their chunk references, got %d references", rc)
+	}
+}
+
+func Test_renameStaleObject(t *testing.T) {
+	ctx := context.Background()
+	bucketName := uniqueString("rename-stale-test")
+
+	o, err := Create(ctx, bucketName, CreateCommand{
+		Key:         uniqueString("stale-"),
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Stale ")),
+	})
+	if err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+
+	// the hold is placed on a copy, so o doesn't know about it
+	held := *o
+	if err := SetLegalHold(ctx, &held, true); err != nil {
+		t.Fatalf("unable to place legal hold: %v", err)
+	}
+	stale := *o
+	if err := Rename(ctx, &stale, uniqueString("renamed-")); err != ec.ObjectLocked {
+		t.Errorf("expected renaming an object that has been held since it was loaded to fail with ObjectLocked, got %v", err)
+	}
+
+	if err := SetLegalHold(ctx, &held, false); err != nil {
+		t.Fatalf("unable to clear legal hold: %v", err)
+	}
+	if err := Delete(ctx, &held); err != nil {
+		t.Fatalf("unable to delete object: %v", err)
+	}
+	stale = *o
+	if err := Rename(ctx, &stale, uniqueString("renamed-")); err != ec.NoSuchKey {
+		t.Errorf("expected renaming an object that has been deleted since it was loaded to fail with NoSuchKey, got %v", err)
+	}
+}
+
+func Test_objectLockLifecycle(t *testing.T) {
+	ctx := context.Background()
+	bucketName := uniqueString("lock-test")
+
+	retention, err := NewRetention(RetentionCompliance, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unable to create retention: %v", err)
+	}
+	o, err := Create(ctx, bucketName, CreateCommand{
+		Key:         uniqueString("l-"),
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Locked ")),
+		Retention:   retention,
+	})
+	if err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+
+	if locked, err := CountLocked(ctx, bucketName); err != nil || locked != 1 {
+		t.Errorf("expected 1 locked object, got %d (%v)", locked, err)
+	}
+	if err := Delete(ctx, o); err != ec.ObjectLocked {
+		t.Errorf("expected delete to fail with ObjectLocked, got %v", err)
+	}
+	if _, err := Update(ctx, o, UpdateCommand{ContentType: "text/plain", Data: []byte("overwrite")}); err != ec.ObjectLocked {
+		t.Errorf("expected update to fail with ObjectLocked, got %v", err)
+	}
+	if err := SetRetention(ctx, o, nil, true); err != ec.ObjectLocked {
+		t.Errorf("expected removing compliance retention to fail, got %v", err)
+	}
+	extended := &Retention{Mode: RetentionCompliance, RetainUntil: retention.RetainUntil.Add(time.Hour)}
+	if err := SetRetention(ctx, o, extended, false); err != nil {
+		t.Errorf("unable to extend compliance retention: %v", err)
+	}
+
+	// Objects are looked up again to make sure the lock state is persisted
+	found, err := FindOne(ctx, bucketName, o.Key, false)
+	if err != nil {
+		t.Fatalf("unable to find object: %v", err)
+	}
+	if !found.Retention.RetainUntil.Equal(extended.RetainUntil) {
+		t.Errorf("expected retention until %v, got %v", extended.RetainUntil, found.Retention.RetainUntil)
+	}
+
+	governed, err := Create(ctx, bucketName, CreateCommand{
+		Key:         uniqueString("g-"),
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Governed ")),
+		Retention:   &Retention{Mode: RetentionGovernance, RetainUntil: retention.RetainUntil},
+		LegalHold:   true,
+	})
+	if err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+	if err := SetRetention(ctx, governed, nil, false); err != ec.ObjectLocked {
+		t.Errorf("expected removing governance retention without bypass to fail, got %v", err)
+	}
+	if err := SetRetention(ctx, governed, nil, true); err != nil {
+		t.Errorf("unable to remove governance retention with bypass: %v", err)
+	}
+	if err := Delete(ctx, governed); err != ec.ObjectLocked {
+		t.Errorf("expected delete under legal hold to fail, got %v", err)
+	}
+	if err := SetLegalHold(ctx, governed, false); err != nil {
+		t.Errorf("unable to clear legal hold: %v", err)
+	}
+	if err := Delete(ctx, governed); err != nil {
+		t.Errorf("unable to delete unlocked object: %v", err)
+	}
+
+	// The compliance locked object is released by moving its retention into the past
+	if _, err := updateRetentionStmt.ExecContext(ctx, RetentionCompliance, time.Now().Add(-time.Hour).UTC(), o.ID); err != nil {
+		t.Fatalf("unable to release object: %v", err)
+	}
+	if err := Delete(ctx, o); err != nil {
+		t.Errorf("unable to delete released object: %v", err)
+	}
+	purge()
+}
+
+func Test_tagsLifecycle(t *testing.T) {
+	ctx := context.Background()
+	bucketName := uniqueString("tags-test")
+
+	tagged, err := Create(ctx, bucketName, CreateCommand{
+		Key:         "a/" + uniqueString("t-"),
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Tagged ")),
+	})
+	if err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+	untagged, err := Create(ctx, bucketName, CreateCommand{
+		Key:         "a/" + uniqueString("u-"),
+		ContentType: "text/plain",
+		Data:        []byte(uniqueString("Untagged ")),
+	})
+	if err != nil {
+		t.Fatalf("unable to create object: %v", err)
+	}
+	if err := SetTags(ctx, tagged, map[string]string{"env": "prod", "team": "a"}); err != nil {
+		t.Fatalf("unable to set tags: %v", err)
+	}
+	if err := SetTags(ctx, untagged, map[string]string{"env": "dev"}); err != nil {
+		t.Fatalf("unable to set tags: %v", err)
+	}
+
+	tests := []struct {
+		filter   Filter
+		expected int
+	}{
+		{filter: Filter{}, expected: 2},
+		{filter: Filter{Prefix: "a/"}, expected: 2},
+		{filter: Filter{Prefix: "b/"}, expected: 0},
+		{filter: Filter{Tags: map[string]string{"env": "prod"}}, expected: 1},
+		{filter: Filter{Tags: map[string]string{"env": "prod", "team": "a"}}, expected: 1},
+		{filter: Filter{Tags: map[string]string{"env": "prod", "team": "b"}}, expected: 0},
+	}
+	for _, test := range tests {
+		objects, err := ListFiltered(ctx, bucketName, test.filter, "", 100)
+		if err != nil {
+			t.Fatalf("unable to list objects: %v", err)
+		}
+		if len(objects) != test.expected {
+			t.Errorf("expected %d objects for %+v, got %d", test.expected, test.filter, len(objects))
+		}
+		count, err := CountFiltered(ctx, bucketName, test.filter, "")
+		if err != nil {
+			t.Fatalf("unable to count objects: %v", err)
+		}
+		if count != test.expected {
+			t.Errorf("expected count %d for %+v, got %d", test.expected, test.filter, count)
+		}
+	}
+
+	if err := SetTags(ctx, tagged, map[string]string{"env": "dev"}); err != nil {
+		t.Fatalf("unable to replace tags: %v", err)
+	}
+	tags, err := Tags(ctx, tagged)
+	if err != nil {
+		t.Fatalf("unable to find tags: %v", err)
+	}
+	if len(tags) != 1 || tags["env"] != "dev" {
+		t.Errorf("expected tags to be replaced, got %v", tags)
+	}
+
+	initialTags := countRows(t, objectTagsTable)
+	if err := Delete(ctx, tagged); err != nil {
+		t.Errorf("unable to delete object: %v", err)
+	}
+	if err := Delete(ctx, untagged); err != nil {
+		t.Errorf("unable to delete object: %v", err)
+	}
+	purge()
+	expectRows(t, "delete tagged objects", objectTagsTable, initialTags-2)
+}
+
 func countRows(t *testing.T, table string) int64 {
 	var count int64
 	if err := db.QueryRow("SELECT COUNT(*) AS count FROM " + table).Scan(&count); err != nil {
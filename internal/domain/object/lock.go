@@ -0,0 +1,64 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"slices"
+	"sync"
+)
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+var (
+	keyLocksMutex sync.Mutex
+	keyLocks      = make(map[string]*keyLock)
+)
+
+// LockKey acquires the write lock of a key in a bucket. Writers that need to check an object's state
+// and write it atomically hold the lock across both steps. The returned function releases the lock.
+func LockKey(bucketName, key string) func() {
+	id := bucketName + "/" + key
+
+	keyLocksMutex.Lock()
+	l, ok := keyLocks[id]
+	if !ok {
+		l = &keyLock{}
+		keyLocks[id] = l
+	}
+	l.refs++
+	keyLocksMutex.Unlock()
+
+	l.Lock()
+
+	return func() {
+		l.Unlock()
+		keyLocksMutex.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(keyLocks, id)
+		}
+		keyLocksMutex.Unlock()
+	}
+}
+
+// LockKeys acquires the write locks of several keys in a bucket. The locks are taken in key order so
+// that two writers locking the same keys can't deadlock. The returned function releases all locks.
+func LockKeys(bucketName string, keys ...string) func() {
+	sorted := slices.Clone(keys)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+	unlocks := make([]func(), 0, len(sorted))
+	for _, key := range sorted {
+		unlocks = append(unlocks, LockKey(bucketName, key))
+	}
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
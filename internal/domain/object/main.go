@@ -30,12 +30,23 @@ type CreateCommand struct {
 	ContentType string
 	Data        []byte
 	Size        int64
+	// ExpiresAt is the time after which the object is deleted. The object never expires if nil.
+	ExpiresAt *time.Time
+	Retention *Retention
+	LegalHold bool
 }
 
 type UpdateCommand struct {
 	ContentType string
 	Data        []byte
 	Size        int64
+	// ExpiresAt is the time after which the object is deleted. The object never expires if nil.
+	ExpiresAt *time.Time
+	Retention *Retention
+	LegalHold bool
+	// KeepPreviousVersion keeps the replaced version instead of deleting it. Kept versions are deleted
+	// with the object or by PruneVersions.
+	KeepPreviousVersion bool
 }
 
 type Object struct {
@@ -48,12 +59,23 @@ type Object struct {
 	CreatedAt      time.Time
 	Deleted        bool
 	CurrentVersion string
+	ExpiresAt      *time.Time
+	// Retention protects the object until a point in time. Nil if the object has no retention.
+	Retention *Retention
+	// LegalHold protects the object until the hold is cleared
+	LegalHold bool
+}
+
+// Expired returns true if the object has an expiration time that has passed
+func (o *Object) Expired() bool {
+	return o.ExpiresAt != nil && !o.ExpiresAt.After(domain.TimeNow())
 }
 
 const (
 	objectsTable        = "objects"
 	objectVersionsTable = "object_versions"
 	objectChunksTable   = "object_chunks"
+	objectTagsTable     = "object_tags"
 	chunksTable         = "chunks"
 )
 
@@ -62,7 +84,7 @@ var (
 	maxPurgeTime = 400 * time.Millisecond
 	purgeFlag    = true
 
-	objectFields = "id, bucket, key, etag, content_type, size, created_at, is_deleted, current"
+	objectFields = "id, bucket, key, etag, content_type, size, created_at, is_deleted, current, expires_at, retention_mode, retain_until, legal_hold"
 
 	createStmt               *sql.Stmt
 	listStmt                 *sql.Stmt
@@ -71,6 +93,12 @@ var (
 	updateObjectMetadataStmt *sql.Stmt
 	// Marks an object as deleted. Input: object id
 	markObjectDeletedStmt *sql.Stmt
+	// Marks an object as deleted unless it is locked. Input: object id, now
+	markUnlockedObjectDeletedStmt *sql.Stmt
+	// Counts the objects with an id that aren't deleted. Input: object id
+	countUndeletedStmt *sql.Stmt
+	// Counts the objects with an id that are under retention or legal hold. Input: object id, now
+	countLockedIdStmt *sql.Stmt
 	// Finds all deleted objects. Input: none
 	findDeletedObjectsStmt *sql.Stmt
 	// Deletes an object. Input: object id
@@ -78,10 +106,26 @@ var (
 	// creates a new object_chunks row. Input: object id, chunk id, seq number
 	addObjectChunkStmt   *sql.Stmt
 	findObjectChunksStmt *sql.Stmt
+	// Finds the chunks of an object version including their size. Input: object version id
+	findObjectChunkRefsStmt *sql.Stmt
 	//TODO: rename object col to version
 	// Deletes all object chunks ob an object. Input: object id
-	deleteObjectChunksStmt  *sql.Stmt
-	countStmt               *sql.Stmt
+	deleteObjectChunksStmt *sql.Stmt
+	countStmt              *sql.Stmt
+	// Lists objects within a key range. Input: bucket, start after, range start, range end, limit
+	listRangeStmt *sql.Stmt
+	// Counts objects within a key range. Input: bucket, range start, range end, now
+	countRangeStmt *sql.Stmt
+	// Counts and sums the sizes of the objects within a key range. Input: bucket, range start, range end, now
+	statsRangeStmt *sql.Stmt
+	// Changes the key of an object that isn't deleted or locked if the new key is not taken. Input: new key, object id, bucket, now
+	renameStmt *sql.Stmt
+	// Marks an expired object that isn't locked as deleted and returns its id. Input: bucket, key, now
+	markExpiredKeyDeletedStmt *sql.Stmt
+	// Counts the expired objects of a key that are under retention or legal hold. Input: bucket, key, time
+	countLockedExpiredKeyStmt *sql.Stmt
+	// Finds expired objects that aren't locked. Input: now
+	findExpiredStmt         *sql.Stmt
 	statsStmt               *sql.Stmt
 	createObjectVersionStmt *sql.Stmt
 	// Marks all object versions of an object as deleted. Input: object id
@@ -99,26 +143,43 @@ func Configure() {
 		log.Fatalf("unable to create chunk directory: %v", err)
 	}
 
-	createStmt = db.Prepare("INSERT INTO objects (" + objectFields + ") VALUES ($1, $2, $3, $4, $5, $6, $7, false, $8)")
-	listStmt = db.Prepare("SELECT " + objectFields + " FROM objects WHERE bucket = $1 AND key > $2 AND is_deleted = $3 ORDER BY key LIMIT $4")
-	findOneStmt = db.Prepare("SELECT " + objectFields + " FROM objects WHERE bucket = $1 AND key = $2 AND is_deleted = $3 LIMIT 1")
-	existsStmt = db.Prepare("SELECT COUNT(*) as count FROM objects WHERE bucket = $1 AND key = $2 AND is_deleted = $3")
+	createStmt = db.Prepare("INSERT INTO objects (" + objectFields + ") VALUES ($1, $2, $3, $4, $5, $6, $7, false, $8, $9, $10, $11, $12)")
+	listStmt = db.Prepare("SELECT " + objectFields + " FROM objects WHERE bucket = $1 AND key > $2 AND is_deleted = $3 AND (expires_at IS NULL OR expires_at > $4) ORDER BY key LIMIT $5")
+	findOneStmt = db.Prepare("SELECT " + objectFields + " FROM objects WHERE bucket = $1 AND key = $2 AND is_deleted = $3 AND (expires_at IS NULL OR expires_at > $4) LIMIT 1")
+	existsStmt = db.Prepare("SELECT COUNT(*) as count FROM objects WHERE bucket = $1 AND key = $2 AND is_deleted = $3 AND (expires_at IS NULL OR expires_at > $4)")
 	markObjectDeletedStmt = db.Prepare("UPDATE objects SET is_deleted = 1 WHERE id = ?")
+	markUnlockedObjectDeletedStmt = db.Prepare("UPDATE objects SET is_deleted = 1 WHERE id = $1 AND legal_hold = 0 AND (retain_until IS NULL OR retain_until <= $2)")
+	countUndeletedStmt = db.Prepare("SELECT COUNT(*) FROM objects WHERE id = $1 AND is_deleted = false")
+	countLockedIdStmt = db.Prepare("SELECT COUNT(*) FROM objects WHERE id = $1 AND (legal_hold != 0 OR retain_until > $2)")
 	deleteObjectStmt = db.Prepare("DELETE FROM objects WHERE id = ?")
 	findDeletedObjectsStmt = db.Prepare("SELECT id FROM objects WHERE is_deleted = true LIMIT 1000")
 	addObjectChunkStmt = db.Prepare("INSERT INTO object_chunks (object, chunk, seq) VALUES ($1, $2, $3)")
 	findObjectChunksStmt = db.Prepare("SELECT chunk FROM object_chunks WHERE object = $1 ORDER BY seq")
+	findObjectChunkRefsStmt = db.Prepare("SELECT oc.chunk, c.size FROM object_chunks oc JOIN chunks c ON c.id = oc.chunk WHERE oc.object = $1 ORDER BY oc.seq")
 	deleteObjectChunksStmt = db.Prepare("DELETE FROM object_chunks WHERE object = $1")
 	countStmt = db.Prepare("SELECT COUNT(*) FROM objects WHERE bucket = $1 AND key > $2 AND is_deleted  = $3")
+	listRangeStmt = db.Prepare("SELECT " + objectFields + " FROM objects WHERE bucket = $1 AND key > $2 AND key >= $3 AND ($4 = '' OR key < $4) AND is_deleted = false AND (expires_at IS NULL OR expires_at > $5) ORDER BY key LIMIT $6")
+	countRangeStmt = db.Prepare("SELECT COUNT(*) FROM objects WHERE bucket = $1 AND key >= $2 AND ($3 = '' OR key < $3) AND is_deleted = false AND (expires_at IS NULL OR expires_at > $4)")
+	statsRangeStmt = db.Prepare("SELECT COUNT(*), TOTAL(size) FROM objects WHERE bucket = $1 AND key >= $2 AND ($3 = '' OR key < $3) AND is_deleted = false AND (expires_at IS NULL OR expires_at > $4)")
+	renameStmt = db.Prepare("UPDATE objects SET key = $1 WHERE id = $2 AND is_deleted = false AND legal_hold = 0 AND (retain_until IS NULL OR retain_until <= $4) AND NOT EXISTS (SELECT 1 FROM objects WHERE bucket = $3 AND key = $1 AND is_deleted = false AND (expires_at IS NULL OR expires_at > $4))")
+	markExpiredKeyDeletedStmt = db.Prepare("UPDATE objects SET is_deleted = 1 WHERE bucket = $1 AND key = $2 AND is_deleted = false AND expires_at <= $3 AND legal_hold = 0 AND (retain_until IS NULL OR retain_until <= $3) RETURNING id")
+	countLockedExpiredKeyStmt = db.Prepare("SELECT COUNT(*) FROM objects WHERE bucket = $1 AND key = $2 AND is_deleted = false AND expires_at <= $3 AND (legal_hold != 0 OR retain_until > $3)")
+	findExpiredStmt = db.Prepare("SELECT id, bucket FROM objects WHERE expires_at <= $1 AND is_deleted = false AND legal_hold = 0 AND (retain_until IS NULL OR retain_until <= $1) LIMIT 1000")
 	statsStmt = db.Prepare("SELECT COUNT(*), TOTAL(size) FROM objects WHERE bucket = $1 AND is_deleted = $2")
 	createObjectVersionStmt = db.Prepare("INSERT INTO object_versions (id, object, content_type, size, created_at, etag, is_deleted) VALUES (?, ?, ?, ?, ?, ?, 0)")
-	updateObjectMetadataStmt = db.Prepare("UPDATE objects SET content_type = ?, size = ?, etag = ?, current = ? WHERE id = ?")
+	updateObjectMetadataStmt = db.Prepare("UPDATE objects SET content_type = ?, size = ?, etag = ?, current = ?, expires_at = ?, retention_mode = ?, retain_until = ?, legal_hold = ? WHERE id = ?")
 	markObjectVersionsDeletedStmt = db.Prepare("UPDATE object_versions SET is_deleted = 1 WHERE object = ?")
 	markObjectVersionDeletedStmt = db.Prepare("UPDATE object_versions SET is_deleted = 1 WHERE id = ?")
 	findDeletedObjectVersionsStmt = db.Prepare("SELECT id FROM object_versions WHERE is_deleted = true LIMIT 1000")
 	deleteObjectVersionStmt = db.Prepare("DELETE FROM object_versions WHERE id = ?")
 
+	configureRetention()
+	configureTags()
+	configureSearch()
+	configureChanges()
+
 	go worker()
+	go expiryWorker()
 }
 
 func ValidateKey(key string) error {
@@ -128,7 +189,7 @@ func ValidateKey(key string) error {
 }
 
 func List(ctx context.Context, bucketName, startAfter string, limit int) ([]*Object, error) {
-	return decodeRows(listStmt.QueryContext(ctx, bucketName, startAfter, false, limit))
+	return decodeRows(listStmt.QueryContext(ctx, bucketName, startAfter, false, domain.TimeNow(), limit))
 }
 
 func Count(ctx context.Context, bucketName, startAfter string) (int, error) {
@@ -139,6 +200,70 @@ func Count(ctx context.Context, bucketName, startAfter string) (int, error) {
 	return count, nil
 }
 
+// ListPrefix lists objects whose keys start with prefix
+func ListPrefix(ctx context.Context, bucketName, prefix, startAfter string, limit int) ([]*Object, error) {
+	return decodeRows(listRangeStmt.QueryContext(ctx, bucketName, startAfter, prefix, PrefixEnd(prefix), domain.TimeNow(), limit))
+}
+
+// CountPrefix counts objects whose keys start with prefix
+func CountPrefix(ctx context.Context, bucketName, prefix string) (int, error) {
+	var count int
+	if err := countRangeStmt.QueryRowContext(ctx, bucketName, prefix, PrefixEnd(prefix), domain.TimeNow()).Scan(&count); err != nil {
+		return 0, fmt.Errorf("unable to count objects: %w", err)
+	}
+	return count, nil
+}
+
+// PruneVersions marks all but the newest keep versions of the objects matched by f as deleted.
+// The current version of an object and the versions of locked objects are never pruned. Returns the number of pruned versions.
+func PruneVersions(ctx context.Context, bucketName string, f Filter, keep int) (int, error) {
+	where, filterArgs := f.where()
+	query := `SELECT id FROM (
+		SELECT v.id, objects.current, ROW_NUMBER() OVER (PARTITION BY v.object ORDER BY v.created_at DESC, v.id DESC) AS n
+		FROM object_versions v JOIN objects ON objects.id = v.object
+		WHERE objects.bucket = ? AND objects.is_deleted = false AND v.is_deleted = 0
+		AND objects.legal_hold = 0 AND (objects.retain_until IS NULL OR objects.retain_until <= ?)` + where + `
+	) WHERE n > ? AND id != current LIMIT 1000`
+	pruned := 0
+	for {
+		args := append([]any{bucketName, domain.TimeNow()}, filterArgs...)
+		ids, err := findVersionIds(ctx, query, append(args, keep)...)
+		if err != nil {
+			return pruned, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, id := range ids {
+			if _, err := markObjectVersionDeletedStmt.ExecContext(ctx, id); err != nil {
+				return pruned, fmt.Errorf("unable to mark object version as deleted: %w", err)
+			}
+			pruned++
+		}
+	}
+	if pruned > 0 {
+		triggerPurge()
+	}
+	return pruned, nil
+}
+
+func findVersionIds(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find prunable object versions: %w", err)
+	}
+	defer rows.Close()
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("unable to decode object version row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 type Stats struct {
 	ObjectCount int64
 	TotalSize   int64
@@ -157,35 +282,45 @@ func StatsForBucket(ctx context.Context, bucketName string) (*Stats, error) {
 	}, nil
 }
 
+// StatsForPrefix counts and sums the sizes of the objects whose keys start with prefix
+func StatsForPrefix(ctx context.Context, bucketName, prefix string) (*Stats, error) {
+	var objects int64
+	var size float64
+	if err := statsRangeStmt.QueryRowContext(ctx, bucketName, prefix, PrefixEnd(prefix), domain.TimeNow()).Scan(&objects, &size); err != nil {
+		return nil, fmt.Errorf("unable to query object stats: %w", err)
+	}
+
+	return &Stats{
+		ObjectCount: objects,
+		TotalSize:   int64(size),
+	}, nil
+}
+
 func decodeRows(rows *sql.Rows, err error) ([]*Object, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to find object records: %w", err)
 	}
 	objects := make([]*Object, 0)
 	for rows.Next() {
-		var o Object
-		if err := rows.Scan(
-			&o.ID,
-			&o.Bucket,
-			&o.Key,
-			&o.ETag,
-			&o.ContentType,
-			&o.Size,
-			&o.CreatedAt,
-			&o.Deleted,
-			&o.CurrentVersion,
-		); err != nil {
+		o, err := scanObject(rows)
+		if err != nil {
 			return nil, fmt.Errorf("unable to decode object record: %w", err)
 		}
-		objects = append(objects, &o)
+		objects = append(objects, o)
 	}
 	return objects, nil
 }
 
-// FindOne finds an object. Returns ec.NoSuchKey if the object cannot be found
-func FindOne(ctx context.Context, bucketName, key string, deleted bool) (*Object, error) {
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanObject decodes a row that contains objectFields
+func scanObject(row scanner) (*Object, error) {
 	var o Object
-	if err := findOneStmt.QueryRowContext(ctx, bucketName, key, deleted).Scan(
+	var expiresAt, retainUntil sql.NullTime
+	var retentionMode string
+	if err := row.Scan(
 		&o.ID,
 		&o.Bucket,
 		&o.Key,
@@ -195,18 +330,40 @@ func FindOne(ctx context.Context, bucketName, key string, deleted bool) (*Object
 		&o.CreatedAt,
 		&o.Deleted,
 		&o.CurrentVersion,
+		&expiresAt,
+		&retentionMode,
+		&retainUntil,
+		&o.LegalHold,
 	); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		o.ExpiresAt = &expiresAt.Time
+	}
+	if retentionMode != "" && retainUntil.Valid {
+		o.Retention = &Retention{
+			Mode:        retentionMode,
+			RetainUntil: retainUntil.Time,
+		}
+	}
+	return &o, nil
+}
+
+// FindOne finds an object. Returns ec.NoSuchKey if the object cannot be found
+func FindOne(ctx context.Context, bucketName, key string, deleted bool) (*Object, error) {
+	o, err := scanObject(findOneStmt.QueryRowContext(ctx, bucketName, key, deleted, domain.TimeNow()))
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ec.NoSuchKey
 		}
 		return nil, fmt.Errorf("unable to find object record: %w", err)
 	}
-	return &o, nil
+	return o, nil
 }
 
 func FindMany(ctx context.Context, bucketName string, keys []string, deleted bool) ([]*Object, error) {
 	query := strings.Builder{}
-	query.WriteString("SELECT " + objectFields + " FROM objects WHERE bucket = $1 AND is_deleted = $2 AND key IN (")
+	query.WriteString("SELECT " + objectFields + " FROM objects WHERE bucket = $1 AND is_deleted = $2 AND (expires_at IS NULL OR expires_at > $3) AND key IN (")
 	first := true
 	for i := range keys {
 		if first {
@@ -214,7 +371,7 @@ func FindMany(ctx context.Context, bucketName string, keys []string, deleted boo
 		} else {
 			query.WriteString(", ")
 		}
-		query.WriteString("$" + strconv.Itoa(i+3))
+		query.WriteString("$" + strconv.Itoa(i+4))
 	}
 	query.WriteString(")")
 	stmt, err := db.PrepareOne(query.String())
@@ -224,6 +381,7 @@ func FindMany(ctx context.Context, bucketName string, keys []string, deleted boo
 	args := make([]any, 0, len(keys)+3)
 	args = append(args, bucketName)
 	args = append(args, deleted)
+	args = append(args, domain.TimeNow())
 	for _, k := range keys {
 		args = append(args, k)
 	}
@@ -232,7 +390,7 @@ func FindMany(ctx context.Context, bucketName string, keys []string, deleted boo
 
 func Exists(ctx context.Context, bucketName, key string) (bool, error) {
 	var count int
-	if err := existsStmt.QueryRowContext(ctx, bucketName, key, false).Scan(&count); err != nil {
+	if err := existsStmt.QueryRowContext(ctx, bucketName, key, false, domain.TimeNow()).Scan(&count); err != nil {
 		return false, fmt.Errorf("unable to count objects: %w", err)
 	}
 	return count > 0, nil
@@ -262,6 +420,9 @@ func CreateWithChunk(ctx context.Context, bucketId, chunkId string, cmd CreateCo
 		Size:           size,
 		CreatedAt:      domain.TimeNow(),
 		CurrentVersion: domain.RandomId(),
+		ExpiresAt:      cmd.ExpiresAt,
+		Retention:      cmd.Retention,
+		LegalHold:      cmd.LegalHold,
 	}
 	if err := create(ctx, o, []string{chunkId}); err != nil {
 		return nil, err
@@ -269,56 +430,103 @@ func CreateWithChunk(ctx context.Context, bucketId, chunkId string, cmd CreateCo
 	return o, nil
 }
 
-// Copy creates a new object by copying src to destKey.
-func Copy(ctx context.Context, src *Object, destKey string) (*Object, error) {
+// CreateWithChunks creates an object from a list of existing chunks. This operation does not increase the chunks' reference counts.
+func CreateWithChunks(ctx context.Context, bucketId string, chunkIds []string, cmd CreateCommand) (*Object, error) {
+	o := &Object{
+		ID:             domain.RandomId(),
+		Bucket:         bucketId,
+		Key:            cmd.Key,
+		ETag:           domain.NewEtag(),
+		ContentType:    cmd.ContentType,
+		Size:           cmd.Size,
+		CreatedAt:      domain.TimeNow(),
+		CurrentVersion: domain.RandomId(),
+		ExpiresAt:      cmd.ExpiresAt,
+		Retention:      cmd.Retention,
+		LegalHold:      cmd.LegalHold,
+	}
+	if err := create(ctx, o, chunkIds); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+type CopyCommand struct {
+	Bucket string
+	Key    string
+	// ContentType replaces the content type of the source. The source's content type is kept if empty.
+	ContentType string
+	// ExpiresAt is the time after which the copy is deleted. The copy never expires if nil.
+	ExpiresAt *time.Time
+	Retention *Retention
+	LegalHold bool
+}
+
+// Copy creates a new object by copying src. The destination may be in a different bucket.
+// The chunks of src are shared with the new object by increasing their reference counts.
+func Copy(ctx context.Context, src *Object, cmd CopyCommand) (*Object, error) {
+	contentType := cmd.ContentType
+	if contentType == "" {
+		contentType = src.ContentType
+	}
 	o := &Object{
 		ID:             domain.RandomId(),
-		Bucket:         src.Bucket,
-		Key:            destKey,
+		Bucket:         cmd.Bucket,
+		Key:            cmd.Key,
 		ETag:           domain.NewEtag(),
-		ContentType:    src.ContentType,
+		ContentType:    contentType,
 		Size:           src.Size,
 		CreatedAt:      domain.TimeNow(),
 		CurrentVersion: domain.RandomId(),
+		ExpiresAt:      cmd.ExpiresAt,
+		Retention:      cmd.Retention,
+		LegalHold:      cmd.LegalHold,
 	}
 
-	chunkIds, err := findObjectChunks(ctx, src.CurrentVersion)
+	chunkIds, err := referenceObjectChunks(ctx, src)
 	if err != nil {
 		return nil, err
 	}
 
 	if err := create(ctx, o, chunkIds); err != nil {
-		return nil, err
-	}
-
-	for _, chunkId := range chunkIds {
-		if err := chunk.IncreaseReferenceCount(ctx, chunkId); err != nil {
-			return nil, err
-		}
+		return nil, dereferenceChunks(ctx, chunkIds, err)
 	}
 
 	return o, nil
 }
 
 func create(ctx context.Context, o *Object, chunkIds []string) error {
-	if _, err := createObjectVersionStmt.ExecContext(ctx, o.CurrentVersion, o.ID, o.ContentType, o.Size, o.CreatedAt, o.ETag); err != nil {
-		return fmt.Errorf("unable to create object version: %w", err)
-	}
+	return change(ctx, func(tx *sql.Tx) error {
+		if err := deleteExpiredKey(ctx, tx, o.Bucket, o.Key); err != nil {
+			return err
+		}
+		if _, err := tx.StmtContext(ctx, createObjectVersionStmt).ExecContext(ctx, o.CurrentVersion, o.ID, o.ContentType, o.Size, o.CreatedAt, o.ETag); err != nil {
+			return fmt.Errorf("unable to create object version: %w", err)
+		}
 
-	for seq, chunkId := range chunkIds {
-		if _, err := addObjectChunkStmt.ExecContext(ctx, o.CurrentVersion, chunkId, seq+1); err != nil {
-			return fmt.Errorf("unable to persist object chunk record: %w", err)
+		addChunk := tx.StmtContext(ctx, addObjectChunkStmt)
+		for seq, chunkId := range chunkIds {
+			if _, err := addChunk.ExecContext(ctx, o.CurrentVersion, chunkId, seq+1); err != nil {
+				return fmt.Errorf("unable to persist object chunk record: %w", err)
+			}
 		}
-	}
-	if _, err := createStmt.ExecContext(ctx, o.ID, o.Bucket, o.Key, o.ETag, o.ContentType, o.Size, o.CreatedAt, o.CurrentVersion); err != nil {
-		return fmt.Errorf("unable to persist object record: %w", err)
-	}
-	return nil
+		mode, retainUntil := o.Retention.values()
+		if _, err := tx.StmtContext(ctx, createStmt).ExecContext(ctx, o.ID, o.Bucket, o.Key, o.ETag, o.ContentType, o.Size, o.CreatedAt, o.CurrentVersion, o.ExpiresAt, mode, retainUntil, o.LegalHold); err != nil {
+			return fmt.Errorf("unable to persist object record: %w", err)
+		}
+		if err := indexKey(ctx, tx, o.ID, o.Key); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, ChangeCreated, o.ID)
+	})
 }
 
 var updateLock sync.Mutex
 
 func Update(ctx context.Context, o *Object, cmd UpdateCommand) (*Object, error) {
+	if o.Locked() {
+		return nil, ec.ObjectLocked
+	}
 	size := cmd.Size
 	if size == 0 && cmd.Data != nil {
 		size = int64(len(cmd.Data))
@@ -328,23 +536,49 @@ func Update(ctx context.Context, o *Object, cmd UpdateCommand) (*Object, error)
 		return nil, err
 	}
 
+	cmd.Size = size
+	return updateWithChunks(ctx, o, []string{chunkId}, cmd)
+}
+
+// UpdateWithChunks creates a new version of an object from existing chunks. This operation does not increase the chunks' reference counts.
+func UpdateWithChunks(ctx context.Context, o *Object, chunkIds []string, cmd UpdateCommand) (*Object, error) {
+	return updateWithChunks(ctx, o, chunkIds, cmd)
+}
+
+func updateWithChunks(ctx context.Context, o *Object, chunkIds []string, cmd UpdateCommand) (*Object, error) {
+	if o.Locked() {
+		return nil, ec.ObjectLocked
+	}
+
 	updateLock.Lock()
 	defer updateLock.Unlock()
 
 	versionId := domain.RandomId()
 	now := domain.TimeNow()
 	etag := domain.NewEtag()
-	if _, err := createObjectVersionStmt.ExecContext(ctx, versionId, o.ID, cmd.ContentType, size, now, etag); err != nil {
-		return nil, fmt.Errorf("unable to create object version: %w", err)
-	}
-	if _, err := addObjectChunkStmt.ExecContext(ctx, versionId, chunkId, 1); err != nil {
-		return nil, fmt.Errorf("unable to persist object chunk record: %w", err)
-	}
-	if _, err := updateObjectMetadataStmt.ExecContext(ctx, cmd.ContentType, size, etag, versionId, o.ID); err != nil {
-		return nil, fmt.Errorf("unable to update object: %w", err)
-	}
-	if _, err := markObjectVersionDeletedStmt.ExecContext(ctx, o.CurrentVersion); err != nil {
-		return nil, fmt.Errorf("unable to set previous object version as deleted")
+	err := change(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.StmtContext(ctx, createObjectVersionStmt).ExecContext(ctx, versionId, o.ID, cmd.ContentType, cmd.Size, now, etag); err != nil {
+			return fmt.Errorf("unable to create object version: %w", err)
+		}
+		addChunk := tx.StmtContext(ctx, addObjectChunkStmt)
+		for seq, chunkId := range chunkIds {
+			if _, err := addChunk.ExecContext(ctx, versionId, chunkId, seq+1); err != nil {
+				return fmt.Errorf("unable to persist object chunk record: %w", err)
+			}
+		}
+		mode, retainUntil := cmd.Retention.values()
+		if _, err := tx.StmtContext(ctx, updateObjectMetadataStmt).ExecContext(ctx, cmd.ContentType, cmd.Size, etag, versionId, cmd.ExpiresAt, mode, retainUntil, cmd.LegalHold, o.ID); err != nil {
+			return fmt.Errorf("unable to update object: %w", err)
+		}
+		if !cmd.KeepPreviousVersion {
+			if _, err := tx.StmtContext(ctx, markObjectVersionDeletedStmt).ExecContext(ctx, o.CurrentVersion); err != nil {
+				return fmt.Errorf("unable to set previous object version as deleted")
+			}
+		}
+		return recordChange(ctx, tx, ChangeUpdated, o.ID)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	triggerPurge()
@@ -354,54 +588,125 @@ func Update(ctx context.Context, o *Object, cmd UpdateCommand) (*Object, error)
 		Bucket:         o.Bucket,
 		Key:            o.Key,
 		ContentType:    cmd.ContentType,
-		Size:           size,
+		Size:           cmd.Size,
+		CreatedAt:      o.CreatedAt,
 		Deleted:        o.Deleted,
 		ETag:           etag,
 		CurrentVersion: versionId,
+		ExpiresAt:      cmd.ExpiresAt,
+		Retention:      cmd.Retention,
+		LegalHold:      cmd.LegalHold,
 	}, nil
 }
 
-func UpdateFromCopy(ctx context.Context, src, dest *Object) (*Object, error) {
-	updateLock.Lock()
-	defer updateLock.Unlock()
-
-	versionId := domain.RandomId()
-	contentType := src.ContentType
-	size := src.Size
-	now := domain.TimeNow()
-	etag := domain.NewEtag()
-	chunkIds, err := findObjectChunks(ctx, src.CurrentVersion)
+// UpdateFromCopy creates a new version of dest with the contents of src. The objects may be in different buckets.
+// The data and size of cmd are ignored. The content type of src is kept if cmd's content type is empty.
+func UpdateFromCopy(ctx context.Context, src, dest *Object, cmd UpdateCommand) (*Object, error) {
+	if dest.Locked() {
+		return nil, ec.ObjectLocked
+	}
+	if cmd.ContentType == "" {
+		cmd.ContentType = src.ContentType
+	}
+	cmd.Data = nil
+	cmd.Size = src.Size
+	chunkIds, err := referenceObjectChunks(ctx, src)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err := createObjectVersionStmt.ExecContext(ctx, versionId, dest.ID, contentType, size, now, etag); err != nil {
-		return nil, fmt.Errorf("unable to create object version: %w", err)
+	o, err := updateWithChunks(ctx, dest, chunkIds, cmd)
+	if err != nil {
+		return nil, dereferenceChunks(ctx, chunkIds, err)
 	}
-	for seq, chunkId := range chunkIds {
-		if _, err := addObjectChunkStmt.ExecContext(ctx, versionId, chunkId, seq+1); err != nil {
-			return nil, fmt.Errorf("unable to persist object chunk record: %w", err)
-		}
+	return o, nil
+}
+
+// referenceObjectChunks increases the reference counts of the chunks of the current version of o
+// and returns the chunk ids in order
+func referenceObjectChunks(ctx context.Context, o *Object) ([]string, error) {
+	chunkIds, err := findObjectChunks(ctx, o.CurrentVersion)
+	if err != nil {
+		return nil, err
 	}
-	if _, err := updateObjectMetadataStmt.ExecContext(ctx, contentType, size, etag, versionId, dest.ID); err != nil {
-		return nil, fmt.Errorf("unable to update object: %w", err)
+	missing, err := chunk.Reference(ctx, chunkIds)
+	if err != nil {
+		return nil, err
 	}
-	if _, err := markObjectVersionDeletedStmt.ExecContext(ctx, dest.CurrentVersion); err != nil {
-		return nil, fmt.Errorf("unable to set previous object version as deleted")
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("object %s references missing chunks: %v", o.ID, missing)
 	}
+	return chunkIds, nil
+}
 
-	triggerPurge()
+// dereferenceChunks reverts referenceObjectChunks after the object that should have used the chunks
+// couldn't be written. Returns err unless the reference counts can't be decreased.
+func dereferenceChunks(ctx context.Context, chunkIds []string, err error) error {
+	for _, id := range chunkIds {
+		if derr := chunk.DecreaseReferenceCount(ctx, id); derr != nil {
+			return derr
+		}
+	}
+	return err
+}
 
-	return &Object{
-		ID:             dest.ID,
-		Bucket:         dest.Bucket,
-		Key:            dest.Key,
-		ContentType:    contentType,
-		Size:           size,
-		Deleted:        dest.Deleted,
-		ETag:           etag,
-		CurrentVersion: versionId,
-	}, nil
+// Rename changes the key of an object. No data is copied. Returns ec.ObjectAlreadyExists if key is taken,
+// ec.ObjectLocked if the object is locked and ec.NoSuchKey if it has been deleted.
+func Rename(ctx context.Context, o *Object, key string) error {
+	if o.Locked() {
+		return ec.ObjectLocked
+	}
+	err := change(ctx, func(tx *sql.Tx) error {
+		if err := deleteExpiredKey(ctx, tx, o.Bucket, key); err != nil {
+			return err
+		}
+		// the old key is recorded as deleted before it is replaced
+		if err := recordChange(ctx, tx, ChangeDeleted, o.ID); err != nil {
+			return err
+		}
+		res, err := tx.StmtContext(ctx, renameStmt).ExecContext(ctx, key, o.ID, o.Bucket, domain.TimeNow())
+		if err != nil {
+			return fmt.Errorf("unable to rename object: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("unable to rename object: %w", err)
+		}
+		if n == 0 {
+			return renameConflict(ctx, tx, o)
+		}
+		if err := unindexKey(ctx, tx, o.ID); err != nil {
+			return err
+		}
+		if err := indexKey(ctx, tx, o.ID, key); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, ChangeCreated, o.ID)
+	})
+	if err != nil {
+		return err
+	}
+	o.Key = key
+	return nil
+}
+
+// renameConflict returns the reason why o couldn't be renamed. The object may have been deleted or locked
+// after it has been loaded.
+func renameConflict(ctx context.Context, tx *sql.Tx, o *Object) error {
+	var count int
+	if err := tx.StmtContext(ctx, countUndeletedStmt).QueryRowContext(ctx, o.ID).Scan(&count); err != nil {
+		return fmt.Errorf("unable to count objects: %w", err)
+	}
+	if count == 0 {
+		return ec.NoSuchKey
+	}
+	if err := tx.StmtContext(ctx, countLockedIdStmt).QueryRowContext(ctx, o.ID, domain.TimeNow()).Scan(&count); err != nil {
+		return fmt.Errorf("unable to count locked objects: %w", err)
+	}
+	if count > 0 {
+		return ec.ObjectLocked
+	}
+	return ec.ObjectAlreadyExists
 }
 
 func Write(ctx context.Context, o *Object, w io.Writer) error {
@@ -417,15 +722,63 @@ func Write(ctx context.Context, o *Object, w io.Writer) error {
 	return nil
 }
 
+// Delete marks an object as deleted. Returns ec.ObjectLocked if the object is under retention or legal hold.
 func Delete(ctx context.Context, o *Object) error {
-	o.Deleted = true
-	if _, err := markObjectDeletedStmt.ExecContext(ctx, o.ID); err != nil {
-		return fmt.Errorf("unable to update object record: %w", err)
+	err := change(ctx, func(tx *sql.Tx) error {
+		res, err := tx.StmtContext(ctx, markUnlockedObjectDeletedStmt).ExecContext(ctx, o.ID, domain.TimeNow())
+		if err != nil {
+			return fmt.Errorf("unable to update object record: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("unable to update object record: %w", err)
+		}
+		if n > 0 {
+			return recordChange(ctx, tx, ChangeDeleted, o.ID)
+		}
+		var count int
+		if err := tx.StmtContext(ctx, countUndeletedStmt).QueryRowContext(ctx, o.ID).Scan(&count); err != nil {
+			return fmt.Errorf("unable to count objects: %w", err)
+		}
+		if count > 0 {
+			return ec.ObjectLocked
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	o.Deleted = true
 	triggerPurge()
 	return nil
 }
 
+type ChunkRef struct {
+	ID   string
+	Size int64
+}
+
+// Chunks returns the chunks of the current version of an object in order
+func Chunks(ctx context.Context, o *Object) ([]ChunkRef, error) {
+	rows, err := findObjectChunkRefsStmt.QueryContext(ctx, o.CurrentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find chunks: %w", err)
+	}
+	defer rows.Close()
+	refs := make([]ChunkRef, 0)
+	for rows.Next() {
+		var ref ChunkRef
+		if err := rows.Scan(&ref.ID, &ref.Size); err != nil {
+			return nil, fmt.Errorf("unable to decode chunk: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to find chunks: %w", err)
+	}
+	return refs, nil
+}
+
 func findObjectChunks(ctx context.Context, objectId string) ([]string, error) {
 	//TODO: when the number of chunks becomes large, this needs to "cursor" its way through
 	rows, err := findObjectChunksStmt.QueryContext(ctx, objectId)
@@ -458,7 +811,10 @@ func worker() {
 }
 
 func purge() {
-	if !purgeFlag {
+	purgeMutex.Lock()
+	flagged := purgeFlag
+	purgeMutex.Unlock()
+	if !flagged {
 		return
 	}
 	ctx := context.Background()
@@ -528,6 +884,12 @@ func getDeletedObjectIds(ctx context.Context) ([]string, error) {
 }
 
 func purgeObject(ctx context.Context, objectId string) error {
+	if err := deleteTags(ctx, objectId); err != nil {
+		return err
+	}
+	if err := db.Tx(ctx, func(tx *sql.Tx) error { return unindexKey(ctx, tx, objectId) }); err != nil {
+		return err
+	}
 	if _, err := markObjectVersionsDeletedStmt.ExecContext(ctx, objectId); err != nil {
 		return fmt.Errorf("unable to mark object versions as deleted: %w", err)
 	}
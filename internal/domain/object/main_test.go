@@ -0,0 +1,24 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cfichtmueller/stor/internal/config"
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain/chunk"
+)
+
+// TestMain configures the package once. Configure starts the workers of the package, so configuring it again
+// would replace the statements under running workers.
+func TestMain(m *testing.M) {
+	config.DataDir = os.TempDir()
+	db.Configure()
+	chunk.Configure()
+	Configure()
+	os.Exit(m.Run())
+}
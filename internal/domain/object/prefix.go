@@ -4,44 +4,15 @@
 
 package object
 
-import "strings"
-
-type PrefixIndex struct {
-	prefixIndex    map[string]bool
-	CommonPrefixes []string
-	keys           []string
-	delimiter      string
-	prefix         string
-}
-
-func NewPrefixIndex(delimiter, prefix string) *PrefixIndex {
-	return &PrefixIndex{
-		prefixIndex:    make(map[string]bool),
-		CommonPrefixes: make([]string, 0),
-		keys:           make([]string, 0),
-		delimiter:      delimiter,
-		prefix:         prefix,
-	}
-}
-
-// AddKey adds a key to the index. The method returns true if this is a key match
-func (i *PrefixIndex) AddKey(key string) bool {
-	if i.prefix == "" && !strings.Contains(key, i.delimiter) {
-		i.keys = append(i.keys, key)
-		return true
-	}
-	if !strings.HasPrefix(key, i.prefix) || !strings.Contains(key, i.delimiter) {
-		return false
-	}
-	parts := strings.Split(key[len(i.prefix):], i.delimiter)
-	if len(parts) == 1 {
-		i.keys = append(i.keys, i.prefix+parts[0])
-		return true
-	}
-	commonPrefix := i.prefix + parts[0] + i.delimiter
-	if _, ok := i.prefixIndex[commonPrefix]; !ok {
-		i.prefixIndex[commonPrefix] = true
-		i.CommonPrefixes = append(i.CommonPrefixes, commonPrefix)
+// PrefixEnd returns the smallest key that is greater than all keys starting with prefix.
+// Returns an empty string if there is no such key.
+func PrefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
 	}
-	return false
+	return ""
 }
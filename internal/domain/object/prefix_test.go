@@ -5,58 +5,71 @@
 package object
 
 import (
+	"context"
 	"testing"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
 )
 
-type PrefixTest struct {
-	Delimiter        string
-	Prefix           string
-	Keys             []string
-	ExpectedPrefixes []string
-	ExpectedKeys     []string
+func TestPrefixEnd(t *testing.T) {
+	tests := map[string]string{
+		"":         "",
+		"a":        "b",
+		"docs/":    "docs0",
+		"a\xff":    "b",
+		"\xff\xff": "",
+	}
+	for prefix, expected := range tests {
+		if actual := PrefixEnd(prefix); actual != expected {
+			t.Errorf("PrefixEnd(%q) = %q, expected %q", prefix, actual, expected)
+		}
+	}
 }
 
-func TestPrefixIndex(t *testing.T) {
-	keys := []string{
-		"documents/2020/contract.pdf",
-		"documents/2021/contract.pdf",
-		"documents/2021/info.pdf",
-		"photos/2020/avatar.jpg",
-		"photos/2021/avatar.jpg",
-		"photos/avatar.jpg",
-		"photos/group.jpg",
-		"image.jpg",
+func TestStatsForPrefix(t *testing.T) {
+	ctx := context.Background()
+	bucketName := uniqueString("stats-test")
+	now := domain.TimeNow()
+	expired := now.Add(-time.Minute)
+	// expired objects are put on legal hold so that they aren't deleted while other tests run
+	stmt := db.Prepare("INSERT INTO objects (id, bucket, key, etag, content_type, size, created_at, is_deleted, current, expires_at, legal_hold) VALUES ($1, $2, $3, $4, 'text/plain', $5, $6, false, $7, $8, $8 IS NOT NULL)")
+	defer stmt.Close()
+	for _, o := range []struct {
+		key       string
+		size      int64
+		expiresAt *time.Time
+	}{
+		{"logs/a.txt", 100, nil},
+		{"logs/b.txt", 200, nil},
+		{"logs/c.txt", 800, &expired},
+		{"logs0.txt", 1600, nil},
+	} {
+		if _, err := stmt.Exec(domain.RandomId(), bucketName, o.key, domain.NewEtag(), o.size, now, domain.RandomId(), o.expiresAt); err != nil {
+			t.Fatalf("unable to insert object: %v", err)
+		}
 	}
 
-	tests := []PrefixTest{
-		{Delimiter: "/", Prefix: "", Keys: keys, ExpectedPrefixes: []string{"documents/", "photos/"}, ExpectedKeys: []string{"image.jpg"}},
-		{Delimiter: "/", Prefix: "documents/", Keys: keys, ExpectedPrefixes: []string{"documents/2020/", "documents/2021/"}, ExpectedKeys: []string{}},
-		{Delimiter: "/", Prefix: "photos/", Keys: keys, ExpectedPrefixes: []string{"photos/2020/", "photos/2021/"}, ExpectedKeys: []string{"photos/avatar.jpg", "photos/group.jpg"}},
+	tests := map[string]Stats{
+		"logs/":  {ObjectCount: 2, TotalSize: 300},
+		"logs":   {ObjectCount: 3, TotalSize: 1900},
+		"other/": {},
 	}
-
-	for testid, test := range tests {
-		x := NewPrefixIndex(test.Delimiter, test.Prefix)
-		for _, k := range test.Keys {
-			x.AddKey(k)
-		}
-		actualPrefixes := x.CommonPrefixes
-		if len(actualPrefixes) != len(test.ExpectedPrefixes) {
-			t.Errorf("Expected %d prefixes, got %d: %v", len(test.ExpectedPrefixes), len(actualPrefixes), actualPrefixes)
-			continue
+	for prefix, expected := range tests {
+		s, err := StatsForPrefix(ctx, bucketName, prefix)
+		if err != nil {
+			t.Fatalf("unable to query stats: %v", err)
 		}
-		for i, a := range actualPrefixes {
-			if a != test.ExpectedPrefixes[i] {
-				t.Errorf("Expected prefix '%s' in position %d, got: '%s'", test.ExpectedPrefixes[i], i, a)
-			}
+		if *s != expected {
+			t.Errorf("StatsForPrefix(%q) = %+v, expected %+v", prefix, *s, expected)
 		}
-		actualKeys := x.keys
-		if len(actualKeys) != len(test.ExpectedKeys) {
-			t.Errorf("[Test %d]: Expected %d keys, got %d, %v", testid, len(test.ExpectedKeys), len(actualKeys), actualKeys)
+		count, err := CountPrefix(ctx, bucketName, prefix)
+		if err != nil {
+			t.Fatalf("unable to count objects: %v", err)
 		}
-		for i, a := range actualKeys {
-			if a != test.ExpectedKeys[i] {
-				t.Errorf("Expected key '%s' in position %d, got: '%s'", test.ExpectedKeys[i], i, a)
-			}
+		if int64(count) != expected.ObjectCount {
+			t.Errorf("CountPrefix(%q) = %d, expected %d", prefix, count, expected.ObjectCount)
 		}
 	}
 }
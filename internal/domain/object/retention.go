@@ -0,0 +1,125 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+const (
+	// RetentionGovernance protects an object from deletion and overwrites. The retention can be
+	// shortened or removed by bypassing governance.
+	RetentionGovernance = "GOVERNANCE"
+	// RetentionCompliance protects an object from deletion and overwrites. The retention can only be extended.
+	RetentionCompliance = "COMPLIANCE"
+)
+
+var (
+	// Updates the retention of an object. Input: mode, retain until, object id
+	updateRetentionStmt *sql.Stmt
+	// Updates the legal hold of an object. Input: legal hold, object id
+	updateLegalHoldStmt *sql.Stmt
+	// Counts the locked objects of a bucket. Input: bucket, now
+	countLockedStmt *sql.Stmt
+)
+
+type Retention struct {
+	Mode        string
+	RetainUntil time.Time
+}
+
+// Active returns true if the retention protects the object at the current time
+func (r *Retention) Active() bool {
+	return r != nil && r.RetainUntil.After(domain.TimeNow())
+}
+
+// values returns the column values of a retention
+func (r *Retention) values() (string, *time.Time) {
+	if r == nil {
+		return "", nil
+	}
+	return r.Mode, &r.RetainUntil
+}
+
+// ValidateRetentionMode returns ec.InvalidRetention if mode is not a retention mode
+func ValidateRetentionMode(mode string) error {
+	if mode != RetentionGovernance && mode != RetentionCompliance {
+		return ec.InvalidRetention
+	}
+	return nil
+}
+
+// NewRetention creates a retention. Returns ec.InvalidRetention if the mode is invalid or retainUntil is not in the future.
+func NewRetention(mode string, retainUntil time.Time) (*Retention, error) {
+	if err := ValidateRetentionMode(mode); err != nil {
+		return nil, err
+	}
+	r := &Retention{Mode: mode, RetainUntil: retainUntil.UTC().Truncate(time.Millisecond)}
+	if !r.Active() {
+		return nil, ec.InvalidRetention
+	}
+	return r, nil
+}
+
+// Locked returns true if the object must not be deleted or overwritten
+func (o *Object) Locked() bool {
+	return o.LegalHold || o.Retention.Active()
+}
+
+func configureRetention() {
+	updateRetentionStmt = db.Prepare("UPDATE objects SET retention_mode = $1, retain_until = $2 WHERE id = $3")
+	updateLegalHoldStmt = db.Prepare("UPDATE objects SET legal_hold = $1 WHERE id = $2")
+	countLockedStmt = db.Prepare("SELECT COUNT(*) FROM objects WHERE bucket = $1 AND is_deleted = false AND (legal_hold = 1 OR retain_until > $2)")
+}
+
+// SetRetention replaces the retention of an object. A nil retention removes it. An active compliance retention can
+// only be extended. Shortening or removing an active governance retention requires bypassGovernance.
+// Returns ec.ObjectLocked if the change is not permitted.
+func SetRetention(ctx context.Context, o *Object, r *Retention, bypassGovernance bool) error {
+	if cur := o.Retention; cur.Active() {
+		shortened := r == nil || r.RetainUntil.Before(cur.RetainUntil)
+		switch cur.Mode {
+		case RetentionCompliance:
+			if shortened || r.Mode != RetentionCompliance {
+				return ec.ObjectLocked
+			}
+		case RetentionGovernance:
+			if shortened && !bypassGovernance {
+				return ec.ObjectLocked
+			}
+		}
+	}
+	mode, retainUntil := r.values()
+	if _, err := updateRetentionStmt.ExecContext(ctx, mode, retainUntil, o.ID); err != nil {
+		return fmt.Errorf("unable to update object retention: %w", err)
+	}
+	o.Retention = r
+	return nil
+}
+
+// SetLegalHold places or clears the legal hold of an object
+func SetLegalHold(ctx context.Context, o *Object, hold bool) error {
+	if _, err := updateLegalHoldStmt.ExecContext(ctx, hold, o.ID); err != nil {
+		return fmt.Errorf("unable to update object legal hold: %w", err)
+	}
+	o.LegalHold = hold
+	return nil
+}
+
+// CountLocked counts the objects of a bucket that are under retention or legal hold
+func CountLocked(ctx context.Context, bucketName string) (int, error) {
+	var count int
+	if err := countLockedStmt.QueryRowContext(ctx, bucketName, domain.TimeNow()).Scan(&count); err != nil {
+		return 0, fmt.Errorf("unable to count locked objects: %w", err)
+	}
+	return count, nil
+}
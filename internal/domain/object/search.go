@@ -0,0 +1,110 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+// MinSearchLength is the minimum length of a search query
+const MinSearchLength = 3
+
+var (
+	// Adds a trigram of an object key. Input: trigram, object id
+	insertTrigramStmt *sql.Stmt
+	// Deletes all trigrams of an object key. Input: object id
+	deleteTrigramsStmt *sql.Stmt
+)
+
+func configureSearch() {
+	insertTrigramStmt = db.Prepare("INSERT OR IGNORE INTO object_key_trigrams (trigram, object) VALUES ($1, $2)")
+	deleteTrigramsStmt = db.Prepare("DELETE FROM object_key_trigrams WHERE object = $1")
+}
+
+// SearchCursor is the position of the last object of a page of search results
+type SearchCursor struct {
+	Bucket string
+	Key    string
+}
+
+// Search finds the objects whose keys contain query, ignoring case. The results are ordered by bucket and key.
+// The search is restricted to buckets if it is not nil and starts after the cursor if it is not nil.
+// Returns ec.InvalidSearchQuery if the query is shorter than MinSearchLength.
+func Search(ctx context.Context, query string, buckets []string, after *SearchCursor, limit int) ([]*Object, error) {
+	grams := domain.Trigrams(query)
+	if len(grams) == 0 {
+		return nil, ec.InvalidSearchQuery
+	}
+	if buckets != nil && len(buckets) == 0 {
+		return make([]*Object, 0), nil
+	}
+	needle := strings.ToLower(query)
+	results := make([]*Object, 0)
+	for len(results) < limit {
+		candidates, err := findCandidates(ctx, grams, buckets, after, limit-len(results))
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range candidates {
+			// the trigrams of a key may contain all trigrams of the query without containing the query
+			if strings.Contains(strings.ToLower(o.Key), needle) {
+				results = append(results, o)
+			}
+			after = &SearchCursor{Bucket: o.Bucket, Key: o.Key}
+		}
+		if len(candidates) < limit-len(results) {
+			break
+		}
+	}
+	return results, nil
+}
+
+// findCandidates finds the objects whose keys contain all trigrams
+func findCandidates(ctx context.Context, grams []string, buckets []string, after *SearchCursor, limit int) ([]*Object, error) {
+	args := make([]any, 0, len(grams)+len(buckets)+6)
+	for _, g := range grams {
+		args = append(args, g)
+	}
+	args = append(args, len(grams), domain.TimeNow())
+	query := "SELECT " + objectFields + " FROM objects WHERE id IN (SELECT object FROM object_key_trigrams WHERE trigram IN (?" +
+		strings.Repeat(", ?", len(grams)-1) + ") GROUP BY object HAVING COUNT(*) = ?) AND is_deleted = false AND (expires_at IS NULL OR expires_at > ?)"
+	if buckets != nil {
+		query += " AND bucket IN (?" + strings.Repeat(", ?", len(buckets)-1) + ")"
+		for _, b := range buckets {
+			args = append(args, b)
+		}
+	}
+	if after != nil {
+		query += " AND (bucket, key) > (?, ?)"
+		args = append(args, after.Bucket, after.Key)
+	}
+	query += " ORDER BY bucket, key LIMIT ?"
+	args = append(args, limit)
+	return decodeRows(db.QueryContext(ctx, query, args...))
+}
+
+func indexKey(ctx context.Context, tx *sql.Tx, objectId, key string) error {
+	insert := tx.StmtContext(ctx, insertTrigramStmt)
+	for _, g := range domain.Trigrams(key) {
+		if _, err := insert.ExecContext(ctx, g, objectId); err != nil {
+			return fmt.Errorf("unable to index object key: %w", err)
+		}
+	}
+	return nil
+}
+
+func unindexKey(ctx context.Context, tx *sql.Tx, objectId string) error {
+	if _, err := tx.StmtContext(ctx, deleteTrigramsStmt).ExecContext(ctx, objectId); err != nil {
+		return fmt.Errorf("unable to delete object key index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	query := uniqueString("needle")
+	first := uniqueString("search-a")
+	second := uniqueString("search-b")
+	objects := make(map[string]*Object)
+	for _, o := range []struct {
+		bucket string
+		key    string
+	}{
+		{first, "docs/" + query + ".txt"},
+		{first, "IMAGES/" + query + ".PNG"},
+		{first, "other.txt"},
+		{second, query},
+	} {
+		created, err := Create(ctx, o.bucket, CreateCommand{Key: o.key, ContentType: "text/plain", Data: []byte("x"), Size: 1})
+		if err != nil {
+			t.Fatalf("unable to create object: %v", err)
+		}
+		objects[o.key] = created
+	}
+
+	search := func(q string, buckets []string, after *SearchCursor, limit int) []string {
+		t.Helper()
+		res, err := Search(ctx, q, buckets, after, limit)
+		if err != nil {
+			t.Fatalf("unable to search: %v", err)
+		}
+		keys := make([]string, 0, len(res))
+		for _, o := range res {
+			keys = append(keys, o.Bucket+":"+o.Key)
+		}
+		return keys
+	}
+	expect := func(name string, got, expected []string) {
+		t.Helper()
+		if !slices.Equal(got, expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, got)
+		}
+	}
+
+	all := []string{first + ":IMAGES/" + query + ".PNG", first + ":docs/" + query + ".txt", second + ":" + query}
+	expect("all buckets", search(query, nil, nil, 10), all)
+	expect("ignores case", search("images/"+query, nil, nil, 10), all[:1])
+	expect("restricted buckets", search(query, []string{second}, nil, 10), all[2:])
+	expect("no buckets", search(query, []string{}, nil, 10), []string{})
+	expect("first page", search(query, nil, nil, 2), all[:2])
+	expect("second page", search(query, nil, &SearchCursor{Bucket: first, Key: "docs/" + query + ".txt"}, 2), all[2:])
+	// all trigrams of "x.txt" are trigrams of other keys, but no key contains it
+	expect("verifies candidates", search(query+"x.txt", nil, nil, 10), []string{})
+
+	if _, err := Search(ctx, "ab", nil, nil, 10); !errors.Is(err, ec.InvalidSearchQuery) {
+		t.Errorf("expected InvalidSearchQuery, got %v", err)
+	}
+
+	if err := Rename(ctx, objects["other.txt"], "renamed-"+query); err != nil {
+		t.Fatalf("unable to rename object: %v", err)
+	}
+	if err := Rename(ctx, objects[query], "moved.txt"); err != nil {
+		t.Fatalf("unable to rename object: %v", err)
+	}
+	all = []string{first + ":IMAGES/" + query + ".PNG", first + ":docs/" + query + ".txt", first + ":renamed-" + query}
+	expect("renamed", search(query, nil, nil, 10), all)
+
+	if err := Delete(ctx, objects["docs/"+query+".txt"]); err != nil {
+		t.Fatalf("unable to delete object: %v", err)
+	}
+	purge()
+	expect("purged", search(query, nil, nil, 10), []string{all[0], all[2]})
+}
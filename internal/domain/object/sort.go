@@ -0,0 +1,86 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+const (
+	SortKey       = "key"
+	SortCreatedAt = "created_at"
+	SortSize      = "size"
+)
+
+// Sort is the order of a listing. Objects with equal values are ordered by key.
+type Sort struct {
+	Field      string
+	Descending bool
+}
+
+// ValidateSortField returns ec.InvalidArgument if field is not a sort field
+func ValidateSortField(field string) error {
+	switch field {
+	case SortKey, SortCreatedAt, SortSize:
+		return nil
+	}
+	return ec.InvalidArgument
+}
+
+// SortCursor is the position of the last object of a page in a sorted listing
+type SortCursor struct {
+	Key       string
+	CreatedAt time.Time
+	Size      int64
+}
+
+// CursorOf returns the cursor that continues a listing after o
+func CursorOf(o *Object) *SortCursor {
+	return &SortCursor{
+		Key:       o.Key,
+		CreatedAt: o.CreatedAt,
+		Size:      o.Size,
+	}
+}
+
+// ListSorted lists the objects of a bucket that match f in the order of s. The listing starts after the
+// cursor if it is not nil.
+func ListSorted(ctx context.Context, bucketName string, f Filter, s Sort, after *SortCursor, limit int) ([]*Object, error) {
+	if err := ValidateSortField(s.Field); err != nil {
+		return nil, err
+	}
+	direction, op := "", ">"
+	if s.Descending {
+		direction, op = " DESC", "<"
+	}
+	where, filterArgs := f.where()
+	args := append([]any{bucketName, domain.TimeNow()}, filterArgs...)
+	if after != nil {
+		switch s.Field {
+		case SortKey:
+			where += " AND key " + op + " ?"
+			args = append(args, after.Key)
+		case SortCreatedAt:
+			where += " AND (created_at, key) " + op + " (?, ?)"
+			args = append(args, after.CreatedAt, after.Key)
+		case SortSize:
+			where += " AND (size, key) " + op + " (?, ?)"
+			args = append(args, after.Size, after.Key)
+		}
+	}
+	order := "key" + direction
+	if s.Field != SortKey {
+		order = s.Field + direction + ", " + order
+	}
+	query := "SELECT " + objectFields + " FROM objects WHERE bucket = ? AND is_deleted = false AND (expires_at IS NULL OR expires_at > ?)" +
+		where + " ORDER BY " + order + " LIMIT ?"
+	args = append(args, limit)
+	return decodeRows(db.QueryContext(ctx, query, args...))
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/domain"
+)
+
+func TestListSorted(t *testing.T) {
+	ctx := context.Background()
+	bucketName := uniqueString("sorted-test")
+	now := domain.TimeNow()
+	stmt := db.Prepare("INSERT INTO objects (id, bucket, key, etag, content_type, size, created_at, is_deleted, current) VALUES ($1, $2, $3, $4, $5, $6, $7, false, $8)")
+	defer stmt.Close()
+	for _, o := range []struct {
+		key         string
+		contentType string
+		size        int64
+		age         time.Duration
+	}{
+		{"a.jpg", "image/jpeg", 300, 3 * time.Hour},
+		{"b.png", "image/png", 100, 10 * time.Minute},
+		{"c.txt", "text/plain", 300, 30 * time.Minute},
+		{"d.txt", "text/plain", 50, 2 * time.Hour},
+	} {
+		if _, err := stmt.Exec(domain.RandomId(), bucketName, o.key, domain.NewEtag(), o.contentType, o.size, now.Add(-o.age), domain.RandomId()); err != nil {
+			t.Fatalf("unable to insert object: %v", err)
+		}
+	}
+	hourAgo := now.Add(-time.Hour)
+	minSize := int64(100)
+
+	tests := []struct {
+		filter   Filter
+		sort     Sort
+		expected []string
+	}{
+		{sort: Sort{Field: SortKey}, expected: []string{"a.jpg", "b.png", "c.txt", "d.txt"}},
+		{sort: Sort{Field: SortKey, Descending: true}, expected: []string{"d.txt", "c.txt", "b.png", "a.jpg"}},
+		{sort: Sort{Field: SortCreatedAt}, expected: []string{"a.jpg", "d.txt", "c.txt", "b.png"}},
+		{sort: Sort{Field: SortCreatedAt, Descending: true}, expected: []string{"b.png", "c.txt", "d.txt", "a.jpg"}},
+		{sort: Sort{Field: SortSize}, expected: []string{"d.txt", "b.png", "a.jpg", "c.txt"}},
+		{sort: Sort{Field: SortSize, Descending: true}, expected: []string{"c.txt", "a.jpg", "b.png", "d.txt"}},
+		{filter: Filter{CreatedAfter: &hourAgo}, sort: Sort{Field: SortKey}, expected: []string{"b.png", "c.txt"}},
+		{filter: Filter{CreatedBefore: &hourAgo}, sort: Sort{Field: SortKey}, expected: []string{"a.jpg", "d.txt"}},
+		{filter: Filter{MinSize: &minSize}, sort: Sort{Field: SortSize}, expected: []string{"b.png", "a.jpg", "c.txt"}},
+		{filter: Filter{MaxSize: &minSize}, sort: Sort{Field: SortKey}, expected: []string{"b.png", "d.txt"}},
+		{filter: Filter{ContentType: "image/*"}, sort: Sort{Field: SortKey}, expected: []string{"a.jpg", "b.png"}},
+		{filter: Filter{ContentType: "text/plain"}, sort: Sort{Field: SortKey}, expected: []string{"c.txt", "d.txt"}},
+	}
+
+	for _, test := range tests {
+		// pages of one object have to continue at the cursor
+		actual := make([]string, 0)
+		var cursor *SortCursor
+		for {
+			objects, err := ListSorted(ctx, bucketName, test.filter, test.sort, cursor, 1)
+			if err != nil {
+				t.Fatalf("unable to list objects: %v", err)
+			}
+			if len(objects) == 0 {
+				break
+			}
+			actual = append(actual, objects[0].Key)
+			cursor = CursorOf(objects[0])
+		}
+		if !slices.Equal(actual, test.expected) {
+			t.Errorf("%+v %+v: expected %v, got %v", test.filter, test.sort, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/db"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+const (
+	// MaxTags is the maximum number of tags of an object
+	MaxTags           = 10
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+var (
+	// Finds the tags of an object. Input: object id
+	findTagsStmt *sql.Stmt
+	// Creates a tag. Input: object id, key, value
+	insertTagStmt *sql.Stmt
+	// Deletes all tags of an object. Input: object id
+	deleteTagsStmt *sql.Stmt
+)
+
+func configureTags() {
+	findTagsStmt = db.Prepare("SELECT key, value FROM object_tags WHERE object = $1 ORDER BY key")
+	insertTagStmt = db.Prepare("INSERT INTO object_tags (object, key, value) VALUES ($1, $2, $3)")
+	deleteTagsStmt = db.Prepare("DELETE FROM object_tags WHERE object = $1")
+}
+
+// ValidateTags returns ec.InvalidTag if there are too many tags or a key or value has an invalid length
+func ValidateTags(tags map[string]string) error {
+	if len(tags) > MaxTags {
+		return ec.InvalidTag
+	}
+	for k, v := range tags {
+		if len(k) == 0 || len(k) > maxTagKeyLength || len(v) > maxTagValueLength {
+			return ec.InvalidTag
+		}
+	}
+	return nil
+}
+
+// ValidateTagsField validates tags as part of a request. The violations are added to v.
+func ValidateTagsField(field string, tags map[string]string, v *srv.ValidationError) *srv.ValidationError {
+	return srv.Require(field, srv.ValidationCodeInvalid, fmt.Sprintf("at most %d tags with keys of up to %d and values of up to %d characters are allowed", MaxTags, maxTagKeyLength, maxTagValueLength),
+		ValidateTags(tags) == nil, v)
+}
+
+// ParseTags parses tags in URL query format (k1=v1&k2=v2). Returns nil if s is empty.
+func ParseTags(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	values, err := url.ParseQuery(s)
+	if err != nil {
+		return nil, ec.InvalidTag
+	}
+	tags := make(map[string]string, len(values))
+	for k, v := range values {
+		if len(v) != 1 {
+			return nil, ec.InvalidTag
+		}
+		tags[k] = v[0]
+	}
+	if err := ValidateTags(tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// FormatTags formats tags in URL query format
+func FormatTags(tags map[string]string) string {
+	values := make(url.Values, len(tags))
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// Tags returns the tags of an object
+func Tags(ctx context.Context, o *Object) (map[string]string, error) {
+	rows, err := findTagsStmt.QueryContext(ctx, o.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find object tags: %w", err)
+	}
+	tags := make(map[string]string)
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, fmt.Errorf("unable to decode object tag: %w", err)
+		}
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+// TagsMany returns the tags of multiple objects by object id. Objects without tags are omitted.
+func TagsMany(ctx context.Context, objects []*Object) (map[string]map[string]string, error) {
+	tags := make(map[string]map[string]string)
+	if len(objects) == 0 {
+		return tags, nil
+	}
+	args := make([]any, 0, len(objects))
+	for _, o := range objects {
+		args = append(args, o.ID)
+	}
+	rows, err := db.Query("SELECT object, key, value FROM object_tags WHERE object IN (?"+strings.Repeat(", ?", len(args)-1)+")", args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find object tags: %w", err)
+	}
+	for rows.Next() {
+		var id, k, v string
+		if err := rows.Scan(&id, &k, &v); err != nil {
+			return nil, fmt.Errorf("unable to decode object tag: %w", err)
+		}
+		if tags[id] == nil {
+			tags[id] = make(map[string]string)
+		}
+		tags[id][k] = v
+	}
+	return tags, nil
+}
+
+// SetTags replaces the tags of an object
+func SetTags(ctx context.Context, o *Object, tags map[string]string) error {
+	if err := ValidateTags(tags); err != nil {
+		return err
+	}
+	if err := deleteTags(ctx, o.ID); err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(tags) {
+		if _, err := insertTagStmt.ExecContext(ctx, o.ID, k, tags[k]); err != nil {
+			return fmt.Errorf("unable to create object tag: %w", err)
+		}
+	}
+	return nil
+}
+
+func deleteTags(ctx context.Context, objectId string) error {
+	if _, err := deleteTagsStmt.ExecContext(ctx, objectId); err != nil {
+		return fmt.Errorf("unable to delete object tags: %w", err)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package object
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		s     string
+		valid bool
+		tags  int
+	}{
+		{s: "", valid: true, tags: 0},
+		{s: "env=prod", valid: true, tags: 1},
+		{s: "env=prod&team=a%20b", valid: true, tags: 2},
+		{s: "env=", valid: true, tags: 1},
+		{s: "=prod", valid: false},
+		{s: "env=prod&env=dev", valid: false},
+		{s: "env=%zz", valid: false},
+		{s: strings.Repeat("k", maxTagKeyLength+1) + "=v", valid: false},
+		{s: "k=" + strings.Repeat("v", maxTagValueLength+1), valid: false},
+		{s: "a=1&b=2&c=3&d=4&e=5&f=6&g=7&h=8&i=9&j=10&k=11", valid: false},
+	}
+
+	for _, test := range tests {
+		tags, err := ParseTags(test.s)
+		if (err == nil) != test.valid {
+			t.Errorf("expected %q to be valid=%v, got %v", test.s, test.valid, err)
+			continue
+		}
+		if test.valid && len(tags) != test.tags {
+			t.Errorf("expected %q to have %d tags, got %v", test.s, test.tags, tags)
+		}
+	}
+}
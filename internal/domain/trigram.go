@@ -0,0 +1,23 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package domain
+
+import "strings"
+
+// Trigrams returns the distinct trigrams of the lower case form of s. Strings that are shorter than
+// three characters have no trigrams.
+func Trigrams(s string) []string {
+	runes := []rune(strings.ToLower(s))
+	seen := make(map[string]bool)
+	grams := make([]string, 0)
+	for i := 0; i+3 <= len(runes); i++ {
+		g := string(runes[i : i+3])
+		if !seen[g] {
+			seen[g] = true
+			grams = append(grams, g)
+		}
+	}
+	return grams
+}
@@ -5,21 +5,46 @@
 package ec
 
 var (
-	AccountDisabled     = &Error{StatusCode: 401, Code: "AccountDisabled", Message: "The user account is disabled"}
-	ArchiveNotAbortable = &Error{StatusCode: 409, Code: "ArchiveNotAbortable", Message: "The archive is not abortable"}
-	ArchiveNotPending   = &Error{StatusCode: 409, Code: "ArchiveNotPending", Message: "The archive is not pending"}
-	BucketAlreadyExists = &Error{StatusCode: 409, Code: "BucketAlreadyExists", Message: "The requested bucket name is not available"}
-	BucketNotEmpty      = &Error{StatusCode: 409, Code: "BucketNotEmpty", Message: "The bucket is not empty"}
-	InvalidArgument     = &Error{StatusCode: 400, Code: "InvalidArgument", Message: "Invalid argument"}
-	InvalidCredentials  = &Error{StatusCode: 401, Code: "InvalidCredentials", Message: "Invalid Credentials"}
-	NoSuchArchive       = &Error{StatusCode: 404, Code: "NoSuchArchive", Message: "The specified archive does not exist"}
-	NoSuchApiKey        = &Error{StatusCode: 404, Code: "NoSuchApiKey", Message: "The specified api key does not exist"}
-	NoSuchBucket        = &Error{StatusCode: 404, Code: "NoSuchBucket", Message: "The specified bucket does not exist"}
-	NoSuchKey           = &Error{StatusCode: 404, Code: "NoSuchKey", Message: "The specified key does not exist"}
-	NoSuchUser          = &Error{StatusCode: 404, Code: "NoSuchUser", Message: "The specified user does not exist"}
-	ObjectAlreadyExists = &Error{StatusCode: 409, Code: "ObjectAlreadyExists", Message: "The requested object name is not available"}
-	Unauthorized        = &Error{StatusCode: 401, Code: "Unauthorized", Message: "Unauthorized"}
-	UserAlreadyExists   = &Error{StatusCode: 409, Code: "UserAlreadyExists", Message: "The requested user name is not available"}
+	AccessDenied             = &Error{StatusCode: 403, Code: "AccessDenied", Message: "The API key is not allowed to perform the request"}
+	AccountDisabled          = &Error{StatusCode: 401, Code: "AccountDisabled", Message: "The user account is disabled"}
+	ArchiveNotAbortable      = &Error{StatusCode: 409, Code: "ArchiveNotAbortable", Message: "The archive is not abortable"}
+	ArchiveNotCancellable    = &Error{StatusCode: 409, Code: "ArchiveNotCancellable", Message: "The archive is not processing"}
+	ArchiveNotPending        = &Error{StatusCode: 409, Code: "ArchiveNotPending", Message: "The archive is not pending"}
+	ArchiveNotRetryable      = &Error{StatusCode: 409, Code: "ArchiveNotRetryable", Message: "The archive has not failed or been cancelled"}
+	BadDigest                = &Error{StatusCode: 400, Code: "BadDigest", Message: "The content does not match the specified digest"}
+	BucketAlreadyExists      = &Error{StatusCode: 409, Code: "BucketAlreadyExists", Message: "The requested bucket name is not available"}
+	BucketHasLockedObjects   = &Error{StatusCode: 409, Code: "BucketHasLockedObjects", Message: "The bucket contains objects under retention or legal hold"}
+	BucketNotEmpty           = &Error{StatusCode: 409, Code: "BucketNotEmpty", Message: "The bucket is not empty"}
+	ExpiredCursor            = &Error{StatusCode: 410, Code: "ExpiredCursor", Message: "The cursor refers to changes that are no longer retained"}
+	InvalidArgument          = &Error{StatusCode: 400, Code: "InvalidArgument", Message: "Invalid argument"}
+	InvalidChunk             = &Error{StatusCode: 400, Code: "InvalidChunk", Message: "The specified chunk is invalid"}
+	InvalidContinuationToken = &Error{StatusCode: 400, Code: "InvalidContinuationToken", Message: "The continuation token is invalid"}
+	InvalidCredentials       = &Error{StatusCode: 401, Code: "InvalidCredentials", Message: "Invalid Credentials"}
+	InvalidExpiration        = &Error{StatusCode: 400, Code: "InvalidExpiration", Message: "The expiration must be a time in the future"}
+	InvalidRetention         = &Error{StatusCode: 400, Code: "InvalidRetention", Message: "The retention must have a valid mode and a retain until date in the future"}
+	InvalidSearchQuery       = &Error{StatusCode: 400, Code: "InvalidSearchQuery", Message: "The search query must have at least 3 characters"}
+	InvalidTag               = &Error{StatusCode: 400, Code: "InvalidTag", Message: "The tags are invalid"}
+	JobNotCancellable        = &Error{StatusCode: 409, Code: "JobNotCancellable", Message: "The job has already finished"}
+	JobNotRetryable          = &Error{StatusCode: 409, Code: "JobNotRetryable", Message: "The job has not failed or been cancelled"}
+	NoSuchArchive            = &Error{StatusCode: 404, Code: "NoSuchArchive", Message: "The specified archive does not exist"}
+	NoSuchApiKey             = &Error{StatusCode: 404, Code: "NoSuchApiKey", Message: "The specified api key does not exist"}
+	NoSuchBucket             = &Error{StatusCode: 404, Code: "NoSuchBucket", Message: "The specified bucket does not exist"}
+	NoSuchDelivery           = &Error{StatusCode: 404, Code: "NoSuchDelivery", Message: "The specified webhook delivery does not exist"}
+	NoSuchJob                = &Error{StatusCode: 404, Code: "NoSuchJob", Message: "The specified job does not exist"}
+	NoSuchKey                = &Error{StatusCode: 404, Code: "NoSuchKey", Message: "The specified key does not exist"}
+	NoSuchLifecycleRule      = &Error{StatusCode: 404, Code: "NoSuchLifecycleRule", Message: "The specified lifecycle rule does not exist"}
+	NoSuchMove               = &Error{StatusCode: 404, Code: "NoSuchMove", Message: "The specified move does not exist"}
+	NoSuchUser               = &Error{StatusCode: 404, Code: "NoSuchUser", Message: "The specified user does not exist"}
+	NoSuchVersion            = &Error{StatusCode: 404, Code: "NoSuchVersion", Message: "The specified version does not exist"}
+	NoSuchWebhook            = &Error{StatusCode: 404, Code: "NoSuchWebhook", Message: "The specified webhook does not exist"}
+	ObjectAlreadyExists      = &Error{StatusCode: 409, Code: "ObjectAlreadyExists", Message: "The requested object name is not available"}
+	ObjectLocked             = &Error{StatusCode: 409, Code: "ObjectLocked", Message: "The object is protected by a retention period or legal hold"}
+	PreconditionFailed       = &Error{StatusCode: 412, Code: "PreconditionFailed", Message: "At least one of the preconditions did not hold"}
+	TooManyKeys              = &Error{StatusCode: 400, Code: "TooManyKeys", Message: "The request selects too many objects"}
+	TooManyLifecycleRules    = &Error{StatusCode: 400, Code: "TooManyLifecycleRules", Message: "The bucket has too many lifecycle rules"}
+	TooManyWebhooks          = &Error{StatusCode: 400, Code: "TooManyWebhooks", Message: "The bucket has too many webhooks"}
+	Unauthorized             = &Error{StatusCode: 401, Code: "Unauthorized", Message: "Unauthorized"}
+	UserAlreadyExists        = &Error{StatusCode: 409, Code: "UserAlreadyExists", Message: "The requested user name is not available"}
 )
 
 type Error struct {
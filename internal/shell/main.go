@@ -10,13 +10,18 @@ import (
 	"log"
 	"os"
 
+	"github.com/cfichtmueller/stor/internal/bus"
 	"github.com/cfichtmueller/stor/internal/config"
 	"github.com/cfichtmueller/stor/internal/db"
 	"github.com/cfichtmueller/stor/internal/domain/apikey"
 	"github.com/cfichtmueller/stor/internal/domain/archive"
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
 	"github.com/cfichtmueller/stor/internal/domain/chunk"
+	"github.com/cfichtmueller/stor/internal/domain/job"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+	"github.com/cfichtmueller/stor/internal/domain/move"
 	"github.com/cfichtmueller/stor/internal/domain/nonce"
+	"github.com/cfichtmueller/stor/internal/domain/notification"
 	"github.com/cfichtmueller/stor/internal/domain/object"
 	"github.com/cfichtmueller/stor/internal/domain/session"
 	"github.com/cfichtmueller/stor/internal/domain/user"
@@ -28,6 +33,8 @@ func Configure() {
 	}
 
 	db.Configure()
+	bus.Configure()
+	job.Configure()
 
 	user.Configure()
 	apikey.Configure()
@@ -36,7 +43,10 @@ func Configure() {
 	bucket.Configure()
 	object.Configure()
 	archive.Configure()
+	move.Configure()
+	lifecycle.Configure()
 	nonce.Configure()
+	notification.Configure()
 }
 
 func Check() bool {
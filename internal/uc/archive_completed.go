@@ -11,9 +11,7 @@ import (
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
 )
 
-func onArchiveCompleted(ctx context.Context, e any) error {
-	d := e.(archive.CompletedEvent)
-
+func onArchiveCompleted(ctx context.Context, d archive.CompletedEvent) error {
 	b, err := bucket.FindOne(ctx, d.Bucket)
 	if err != nil {
 		return err
@@ -23,5 +21,5 @@ func onArchiveCompleted(ctx context.Context, e any) error {
 		return err
 	}
 
-	return nil
+	return notifyArchiveCompleted(ctx, d)
 }
@@ -0,0 +1,120 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package uc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/chunk"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+type CommitObjectManifestCommand struct {
+	Key         string
+	ContentType string
+	Chunks      []string
+	// ExpiresAt is the time after which the object is deleted. The object never expires if nil.
+	ExpiresAt *time.Time
+	// Tags replace the tags of the object
+	Tags map[string]string
+}
+
+type CommitObjectManifestResult struct {
+	// Object is the created or updated object. It is nil if chunks are missing.
+	Object *object.Object
+	// Missing contains the ids of all chunks that need to be uploaded before the manifest can be committed
+	Missing []string
+}
+
+// CommitObjectManifest creates a new version of an object from a list of chunks. If any of the chunks
+// is not stored yet, nothing is written and the missing chunks are reported in the result.
+func CommitObjectManifest(ctx context.Context, b *bucket.Bucket, cmd CommitObjectManifestCommand) (*CommitObjectManifestResult, error) {
+	if err := object.ValidateTags(cmd.Tags); err != nil {
+		return nil, err
+	}
+	for _, id := range cmd.Chunks {
+		if !chunk.IsValidId(id) {
+			return nil, ec.InvalidChunk
+		}
+	}
+
+	missing, err := chunk.Reference(ctx, cmd.Chunks)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return &CommitObjectManifestResult{Missing: missing}, nil
+	}
+
+	o, err := commitObjectManifest(ctx, b, cmd)
+	if err != nil {
+		releaseChunks(ctx, cmd.Chunks)
+		return nil, err
+	}
+
+	if err := ReconcileBucket(ctx, b); err != nil {
+		return nil, err
+	}
+
+	return &CommitObjectManifestResult{Object: o, Missing: missing}, nil
+}
+
+func commitObjectManifest(ctx context.Context, b *bucket.Bucket, cmd CommitObjectManifestCommand) (*object.Object, error) {
+	size, err := chunk.Size(ctx, cmd.Chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := object.LockKey(b.Name, cmd.Key)
+	defer unlock()
+
+	existing, err := findExisting(ctx, b, cmd.Key)
+	if err != nil {
+		return nil, err
+	}
+	var o *object.Object
+	if existing != nil {
+		var keep bool
+		if keep, err = lifecycle.KeepsVersions(ctx, existing); err != nil {
+			return nil, err
+		}
+		o, err = object.UpdateWithChunks(ctx, existing, cmd.Chunks, object.UpdateCommand{
+			ContentType:         cmd.ContentType,
+			Size:                size,
+			ExpiresAt:           cmd.ExpiresAt,
+			Retention:           retentionFor(b, nil),
+			KeepPreviousVersion: keep,
+		})
+	} else {
+		o, err = object.CreateWithChunks(ctx, b.Name, cmd.Chunks, object.CreateCommand{
+			Key:         cmd.Key,
+			ContentType: cmd.ContentType,
+			Size:        size,
+			ExpiresAt:   cmd.ExpiresAt,
+			Retention:   retentionFor(b, nil),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := object.SetTags(ctx, o, cmd.Tags); err != nil {
+		return nil, err
+	}
+	publishObjectCreated(ctx, o)
+	return o, nil
+}
+
+func releaseChunks(ctx context.Context, ids []string) {
+	for _, id := range ids {
+		if err := chunk.Delete(ctx, id); err != nil {
+			slog.Error("unable to release chunk", "chunk", id, "error", err)
+		}
+	}
+}
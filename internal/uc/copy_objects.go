@@ -0,0 +1,165 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package uc
+
+import (
+	"context"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+// FindCopySource resolves a copy source (see object.ParseCopySource). Keys without a bucket are looked up in b.
+func FindCopySource(ctx context.Context, b *bucket.Bucket, source string) (*object.Object, error) {
+	s, err := object.ParseCopySource(source, b.Name)
+	if err != nil {
+		return nil, err
+	}
+	if s.Bucket != b.Name {
+		if err := bucket.ValidateName(s.Bucket); err != nil {
+			return nil, err
+		}
+		if _, err := bucket.FindOne(ctx, s.Bucket); err != nil {
+			return nil, err
+		}
+	}
+	if err := object.ValidateKey(s.Key); err != nil {
+		return nil, err
+	}
+	o, err := object.FindOne(ctx, s.Bucket, s.Key, false)
+	if err != nil {
+		return nil, err
+	}
+	if s.VersionId != "" && s.VersionId != o.CurrentVersion {
+		return nil, ec.NoSuchVersion
+	}
+	return o, nil
+}
+
+type CopyObjectCommand struct {
+	Source string
+	Key    string
+	// ContentType replaces the source's content type. The source's content type is kept if empty.
+	ContentType string
+	// Conditions are checked against the destination object
+	Conditions object.Conditions
+	// ExpiresAt is the time after which the copy is deleted. The copy never expires if nil.
+	ExpiresAt *time.Time
+	// Retention protects the copy. The bucket's default retention is applied if nil.
+	Retention *object.Retention
+	LegalHold bool
+	// Tags replace the tags of the copy. The source's tags are copied if nil.
+	Tags map[string]string
+}
+
+type CopyObjectResult struct {
+	Key    string
+	Object *object.Object
+	Error  error
+}
+
+// CopyObject copies an object into b. The destination is created or updated.
+func CopyObject(ctx context.Context, b *bucket.Bucket, cmd CopyObjectCommand) (*object.Object, error) {
+	o, err := copyObject(ctx, b, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ReconcileBucket(ctx, b); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// CopyObjects copies multiple objects into b. A failing copy doesn't abort the remaining copies.
+// The bucket is reconciled once after all copies are done.
+func CopyObjects(ctx context.Context, b *bucket.Bucket, cmds []CopyObjectCommand) ([]CopyObjectResult, error) {
+	results := make([]CopyObjectResult, 0, len(cmds))
+	for _, cmd := range cmds {
+		o, err := copyObject(ctx, b, cmd)
+		results = append(results, CopyObjectResult{
+			Key:    cmd.Key,
+			Object: o,
+			Error:  err,
+		})
+	}
+
+	if err := ReconcileBucket(ctx, b); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func copyObject(ctx context.Context, b *bucket.Bucket, cmd CopyObjectCommand) (*object.Object, error) {
+	if err := object.ValidateKey(cmd.Key); err != nil {
+		return nil, err
+	}
+	if err := object.ValidateTags(cmd.Tags); err != nil {
+		return nil, err
+	}
+	src, err := FindCopySource(ctx, b, cmd.Source)
+	if err != nil {
+		return nil, err
+	}
+	return copyObjectFrom(ctx, b, src, cmd)
+}
+
+// copyObjectFrom copies src into b. The source of cmd is ignored.
+func copyObjectFrom(ctx context.Context, b *bucket.Bucket, src *object.Object, cmd CopyObjectCommand) (*object.Object, error) {
+	unlock := object.LockKey(b.Name, cmd.Key)
+	defer unlock()
+
+	existing, err := findExisting(ctx, b, cmd.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Conditions.Check(existing); err != nil {
+		return nil, err
+	}
+	tags := cmd.Tags
+	if tags == nil {
+		tags, err = object.Tags(ctx, src)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var o *object.Object
+	if existing != nil {
+		var keep bool
+		if keep, err = lifecycle.KeepsVersions(ctx, existing); err != nil {
+			return nil, err
+		}
+		o, err = object.UpdateFromCopy(ctx, src, existing, object.UpdateCommand{
+			ContentType:         cmd.ContentType,
+			ExpiresAt:           cmd.ExpiresAt,
+			Retention:           retentionFor(b, cmd.Retention),
+			LegalHold:           cmd.LegalHold,
+			KeepPreviousVersion: keep,
+		})
+	} else {
+		o, err = object.Copy(ctx, src, object.CopyCommand{
+			Bucket:      b.Name,
+			Key:         cmd.Key,
+			ContentType: cmd.ContentType,
+			ExpiresAt:   cmd.ExpiresAt,
+			Retention:   retentionFor(b, cmd.Retention),
+			LegalHold:   cmd.LegalHold,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := object.SetTags(ctx, o, tags); err != nil {
+		return nil, err
+	}
+	publishObjectCreated(ctx, o)
+	return o, nil
+}
@@ -8,11 +8,21 @@ import (
 	"context"
 
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+	"github.com/cfichtmueller/stor/internal/domain/notification"
 	"github.com/cfichtmueller/stor/internal/domain/object"
 	"github.com/cfichtmueller/stor/internal/ec"
 )
 
 func DeleteBucket(ctx context.Context, b *bucket.Bucket) error {
+	locked, err := object.CountLocked(ctx, b.Name)
+	if err != nil {
+		return err
+	}
+	if locked > 0 {
+		return ec.BucketHasLockedObjects
+	}
+
 	count, err := object.Count(ctx, b.Name, "")
 	if err != nil {
 		return err
@@ -21,6 +31,14 @@ func DeleteBucket(ctx context.Context, b *bucket.Bucket) error {
 		return ec.BucketNotEmpty
 	}
 
+	if err := lifecycle.DeleteAll(ctx, b.Name); err != nil {
+		return err
+	}
+
+	if err := notification.DeleteAll(ctx, b.Name); err != nil {
+		return err
+	}
+
 	if err := bucket.Delete(ctx, b.Name); err != nil {
 		return err
 	}
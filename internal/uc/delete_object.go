@@ -9,12 +9,29 @@ import (
 
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
 	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
 )
 
-func DeleteObject(ctx context.Context, b *bucket.Bucket, o *object.Object) error {
+// DeleteObject deletes an object. The conditions are checked and the object is deleted while holding the key's lock.
+func DeleteObject(ctx context.Context, b *bucket.Bucket, key string, conditions object.Conditions) error {
+	unlock := object.LockKey(b.Name, key)
+	defer unlock()
+
+	o, err := findExisting(ctx, b, key)
+	if err != nil {
+		return err
+	}
+	if err := conditions.Check(o); err != nil {
+		return err
+	}
+	if o == nil {
+		return ec.NoSuchKey
+	}
+
 	if err := object.Delete(ctx, o); err != nil {
 		return err
 	}
+	publishObjectDeleted(ctx, o)
 
 	if err := ReconcileBucket(ctx, b); err != nil {
 		return err
@@ -22,3 +39,17 @@ func DeleteObject(ctx context.Context, b *bucket.Bucket, o *object.Object) error
 
 	return nil
 }
+
+// DeleteObjects deletes objects and returns the errors of the objects that couldn't be deleted by key.
+// The bucket is not reconciled.
+func DeleteObjects(ctx context.Context, objects []*object.Object) map[string]error {
+	failed := make(map[string]error)
+	for _, o := range objects {
+		if err := object.Delete(ctx, o); err != nil {
+			failed[o.Key] = err
+			continue
+		}
+		publishObjectDeleted(ctx, o)
+	}
+	return failed
+}
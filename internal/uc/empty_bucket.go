@@ -6,39 +6,47 @@ package uc
 
 import (
 	"context"
+	"errors"
 
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
 	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
 )
 
+// EmptyBucket deletes all objects of a bucket. Objects under retention or legal hold are kept, in which case
+// ec.BucketHasLockedObjects is returned after all other objects have been deleted.
 func EmptyBucket(ctx context.Context, b *bucket.Bucket) error {
-	var deleteObjects func() error
-
-	deleteObjects = func() error {
-		objects, err := object.List(ctx, b.Name, "", 1000)
+	locked := 0
+	startAfter := ""
+	for {
+		objects, err := object.List(ctx, b.Name, startAfter, 1000)
 		if err != nil {
 			return err
 		}
 
 		if len(objects) == 0 {
-			return nil
+			break
 		}
 
 		for _, o := range objects {
+			startAfter = o.Key
 			if err := object.Delete(ctx, o); err != nil {
+				if errors.Is(err, ec.ObjectLocked) {
+					locked++
+					continue
+				}
 				return err
 			}
+			publishObjectDeleted(ctx, o)
 		}
-
-		return deleteObjects()
 	}
 
-	if err := deleteObjects(); err != nil {
+	if err := ReconcileBucket(ctx, b); err != nil {
 		return err
 	}
 
-	if err := ReconcileBucket(ctx, b); err != nil {
-		return err
+	if locked > 0 {
+		return ec.BucketHasLockedObjects
 	}
 
 	return nil
@@ -0,0 +1,307 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package uc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/chunk"
+	"github.com/cfichtmueller/stor/internal/domain/job"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+var extractArchiveJob job.Type[ExtractArchiveCommand]
+
+func configureExtractArchiveJob() {
+	extractArchiveJob = job.Register("archive.extract", extractArchive, job.Cleanup(archive.PruneResults))
+}
+
+// ExtractArchiveCommand extracts the files of an archive object into objects of the same bucket
+type ExtractArchiveCommand struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	// Type is the archive type. It is detected from the key and content type of the archive if empty.
+	Type string `json:"type"`
+	// Prefix is prepended to the names of the files
+	Prefix string `json:"prefix"`
+	// Overwrite is one of archive.OverwriteSkip, archive.OverwriteReplace and archive.OverwriteFail.
+	// Existing objects are skipped if empty.
+	Overwrite string `json:"overwrite"`
+}
+
+func (c ExtractArchiveCommand) Validate() error {
+	v := srv.RequireMaxLength("prefix", 1024, c.Prefix, nil)
+	v = srv.Require("overwrite", srv.ValidationCodeInvalid, "overwrite must be one of skip, replace or fail",
+		c.Overwrite == "" || slices.Contains([]string{archive.OverwriteSkip, archive.OverwriteReplace, archive.OverwriteFail}, c.Overwrite), v)
+	return srv.Validate(v)
+}
+
+// extractCheckpoint is the state an extraction resumes from
+type extractCheckpoint struct {
+	// Files is the number of files that have been processed
+	Files int `json:"files"`
+	// Bytes is the number of bytes that have been extracted
+	Bytes int64 `json:"bytes"`
+}
+
+// ExtractArchive queues a job that extracts an archive object. The results of the files are recorded with
+// archive.RecordResult. Returns ec.InvalidArgument if the type of the archive is not supported.
+func ExtractArchive(ctx context.Context, o *object.Object, cmd ExtractArchiveCommand) (*job.Job, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	cmd.Bucket = o.Bucket
+	cmd.Key = o.Key
+	if cmd.Type == "" {
+		t, ok := archive.DetectType(o.Key, o.ContentType)
+		if !ok {
+			return nil, ec.InvalidArgument
+		}
+		cmd.Type = t
+	}
+	if _, ok := archive.FindFormat(cmd.Type); !ok {
+		return nil, ec.InvalidArgument
+	}
+	if cmd.Overwrite == "" {
+		cmd.Overwrite = archive.OverwriteSkip
+	}
+	return job.Enqueue(ctx, extractArchiveJob, cmd.Bucket, "", cmd)
+}
+
+func extractArchive(ctx context.Context, r *job.Run, cmd ExtractArchiveCommand) error {
+	b, err := bucket.FindOne(ctx, cmd.Bucket)
+	if err != nil {
+		return prefixJobError(err)
+	}
+	o, err := object.FindOne(ctx, cmd.Bucket, cmd.Key, false)
+	if err != nil {
+		if errors.Is(err, ec.NoSuchKey) {
+			return job.Permanent(err)
+		}
+		return err
+	}
+	format, ok := archive.FindFormat(cmd.Type)
+	if !ok {
+		return job.Permanent(ec.InvalidArgument)
+	}
+	var c extractCheckpoint
+	if _, err := r.Resume(&c); err != nil {
+		return err
+	}
+
+	// zip archives can only be read with random access
+	f, err := chunk.TempFile()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	if err := object.Write(ctx, o, f); err != nil {
+		return err
+	}
+	progress := &progressReaderAt{r: f}
+	reader, err := format.NewReader(progress, o.Size)
+	if err != nil {
+		return job.Permanent(err)
+	}
+	defer reader.Close()
+
+	defer reconcileAfterJob(ctx, b)
+
+	x := archive.NewExtractor(reader, cmd.Prefix)
+	x.Resume(c.Bytes)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		e, err := x.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// broken archives and exceeded limits fail the same way every time
+			return job.Permanent(err)
+		}
+		if e.Seq <= c.Files {
+			continue
+		}
+		var since time.Time
+		if r.Job().Attempts > 1 && e.Seq == c.Files+1 {
+			// the previous attempt may have been interrupted after it wrote this file
+			since = r.Job().CreatedAt
+		}
+		res, err := extractEntry(ctx, b, e, cmd.Overwrite, since)
+		if rerr := archive.RecordResult(ctx, r.Job().ID, res); rerr != nil {
+			return rerr
+		}
+		if err != nil {
+			return err
+		}
+		c.Files, c.Bytes = e.Seq, x.Size()
+		if err := r.Checkpoint(ctx, c, min(progress.n, o.Size), o.Size); err != nil {
+			return err
+		}
+	}
+
+	return r.Progress(ctx, o.Size, o.Size)
+}
+
+// extractEntry writes a file of an archive to its object. Files that cannot be extracted are reported
+// in the result. The returned error aborts the extraction. Unless overwrite is archive.OverwriteReplace, an
+// existing object that has been created at or after since is reported as created by the extraction.
+func extractEntry(ctx context.Context, b *bucket.Bucket, e *archive.ExtractEntry, overwrite string, since time.Time) (archive.Result, error) {
+	res := archive.Result{Seq: e.Seq, Name: e.Name, Key: e.Key, Result: archive.ResultFailed}
+	if e.KeyError != nil {
+		res.Error = e.KeyError.Error()
+		return res, nil
+	}
+
+	unlock := object.LockKey(b.Name, e.Key)
+	defer unlock()
+
+	existing, err := findExisting(ctx, b, e.Key)
+	if err != nil {
+		res.Error = err.Error()
+		return res, err
+	}
+	if existing != nil && overwrite != archive.OverwriteReplace && !since.IsZero() && !existing.CreatedAt.Before(since) {
+		// an earlier attempt created the object before it was interrupted
+		res.Result, res.Size = archive.ResultCreated, existing.Size
+		return res, nil
+	}
+	if existing != nil {
+		switch {
+		case overwrite == archive.OverwriteSkip:
+			res.Result = archive.ResultSkipped
+			return res, nil
+		case overwrite == archive.OverwriteFail:
+			res.Error = ec.ObjectAlreadyExists.Error()
+			return res, job.Permanent(ec.ObjectAlreadyExists)
+		case existing.Locked():
+			res.Error = ec.ObjectLocked.Error()
+			return res, nil
+		}
+	}
+
+	keep := false
+	if existing != nil {
+		if keep, err = lifecycle.KeepsVersions(ctx, existing); err != nil {
+			res.Error = err.Error()
+			return res, err
+		}
+	}
+
+	contentType, content, err := detectContentType(e.Name, e.Content)
+	if err != nil {
+		res.Error = err.Error()
+		return res, extractError(err)
+	}
+	w, err := chunk.NewWriter()
+	if err != nil {
+		res.Error = err.Error()
+		return res, err
+	}
+	size, err := io.Copy(w, content)
+	if err != nil {
+		_ = w.Abort()
+		res.Error = err.Error()
+		return res, extractError(err)
+	}
+	if err := w.Close(); err != nil {
+		res.Error = err.Error()
+		return res, err
+	}
+	chunkId, err := w.Commit(ctx)
+	if err != nil {
+		res.Error = err.Error()
+		return res, err
+	}
+
+	var o *object.Object
+	if existing != nil {
+		o, err = object.UpdateWithChunks(ctx, existing, []string{chunkId}, object.UpdateCommand{
+			ContentType:         contentType,
+			Size:                size,
+			Retention:           retentionFor(b, nil),
+			KeepPreviousVersion: keep,
+		})
+		res.Result = archive.ResultReplaced
+	} else {
+		o, err = object.CreateWithChunk(ctx, b.Name, chunkId, object.CreateCommand{
+			Key:         e.Key,
+			ContentType: contentType,
+			Size:        size,
+			Retention:   retentionFor(b, nil),
+		})
+		res.Result = archive.ResultCreated
+	}
+	if err != nil {
+		if derr := chunk.DecreaseReferenceCount(ctx, chunkId); derr != nil {
+			return res, derr
+		}
+		res.Result = archive.ResultFailed
+		res.Error = err.Error()
+		if errors.Is(err, ec.ObjectLocked) {
+			return res, nil
+		}
+		return res, err
+	}
+	res.Size = size
+	publishObjectCreated(ctx, o)
+	return res, nil
+}
+
+// extractError fails an extraction permanently if the archive is broken or exceeds a limit
+func extractError(err error) error {
+	if errors.Is(err, archive.ErrEntryTooLarge) || errors.Is(err, archive.ErrExtractTooLarge) {
+		return job.Permanent(err)
+	}
+	return err
+}
+
+// detectContentType returns the content type of a file from its extension or from its first bytes.
+// The returned reader reads the complete file.
+func detectContentType(name string, r io.Reader) (string, io.Reader, error) {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return t, r, nil
+	}
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+	return http.DetectContentType(head), br, nil
+}
+
+// progressReaderAt counts the bytes that are read from an archive
+type progressReaderAt struct {
+	r io.ReaderAt
+	n int64
+}
+
+func (p *progressReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	n, err := p.r.ReadAt(b, off)
+	p.n += int64(n)
+	return n, err
+}
@@ -3,8 +3,17 @@ package uc
 import (
 	"github.com/cfichtmueller/stor/internal/bus"
 	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+	"github.com/cfichtmueller/stor/internal/domain/object"
 )
 
 func Configure() {
-	bus.SubscribeCE(archive.EventCompleted, onArchiveCompleted)
+	bus.Subscribe(archive.EventCompleted, "uc.archive-completed", onArchiveCompleted)
+	bus.Subscribe(lifecycle.EventApplied, "uc.lifecycle-applied", onLifecycleApplied)
+	bus.Subscribe(object.EventExpired, "uc.objects-expired", onObjectsExpired)
+	bus.Subscribe(object.EventCreated, "uc.notify-object-created", onObjectCreated, bus.Workers(4))
+	bus.Subscribe(object.EventDeleted, "uc.notify-object-deleted", onObjectDeleted, bus.Workers(4))
+
+	configurePrefixJobs()
+	configureExtractArchiveJob()
 }
@@ -0,0 +1,51 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package uc
+
+import (
+	"context"
+
+	"github.com/cfichtmueller/stor/internal/bus"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/domain/notification"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+)
+
+func publishObjectCreated(ctx context.Context, o *object.Object) {
+	bus.Publish(ctx, object.EventCreated, object.Event{Object: o})
+}
+
+func publishObjectDeleted(ctx context.Context, o *object.Object) {
+	bus.Publish(ctx, object.EventDeleted, object.Event{Object: o})
+}
+
+func onObjectCreated(ctx context.Context, e object.Event) error {
+	return notification.Notify(ctx, newObjectNotification(notification.EventObjectCreated, e.Object))
+}
+
+func onObjectDeleted(ctx context.Context, e object.Event) error {
+	return notification.Notify(ctx, newObjectNotification(notification.EventObjectDeleted, e.Object))
+}
+
+func notifyArchiveCompleted(ctx context.Context, d archive.CompletedEvent) error {
+	return notification.Notify(ctx, notification.Event{
+		Type:      notification.EventArchiveCompleted,
+		Bucket:    d.Bucket,
+		Key:       d.Key,
+		ArchiveId: d.ArchiveId,
+	})
+}
+
+func newObjectNotification(eventType string, o *object.Object) notification.Event {
+	return notification.Event{
+		Type:        eventType,
+		Bucket:      o.Bucket,
+		Key:         o.Key,
+		Size:        o.Size,
+		ETag:        o.ETag,
+		ContentType: o.ContentType,
+		VersionId:   o.CurrentVersion,
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package uc
+
+import (
+	"context"
+
+	"github.com/cfichtmueller/stor/internal/domain"
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+// MaxDefaultRetentionDays is the longest default retention a bucket can have
+const MaxDefaultRetentionDays = 36500
+
+// SetBucketDefaultRetention sets the retention that is applied to new objects of b. An empty mode removes it.
+func SetBucketDefaultRetention(ctx context.Context, b *bucket.Bucket, mode string, days int) error {
+	if mode == "" {
+		return bucket.SetDefaultRetention(ctx, b, "", 0)
+	}
+	if err := object.ValidateRetentionMode(mode); err != nil {
+		return err
+	}
+	if days < 1 || days > MaxDefaultRetentionDays {
+		return ec.InvalidRetention
+	}
+	return bucket.SetDefaultRetention(ctx, b, mode, days)
+}
+
+// SetObjectRetention replaces the retention of an object. See object.SetRetention.
+func SetObjectRetention(ctx context.Context, b *bucket.Bucket, key string, r *object.Retention, bypassGovernance bool) (*object.Object, error) {
+	unlock := object.LockKey(b.Name, key)
+	defer unlock()
+
+	o, err := object.FindOne(ctx, b.Name, key, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := object.SetRetention(ctx, o, r, bypassGovernance); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// SetObjectLegalHold places or clears the legal hold of an object
+func SetObjectLegalHold(ctx context.Context, b *bucket.Bucket, key string, hold bool) (*object.Object, error) {
+	unlock := object.LockKey(b.Name, key)
+	defer unlock()
+
+	o, err := object.FindOne(ctx, b.Name, key, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := object.SetLegalHold(ctx, o, hold); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// retentionFor returns r or the bucket's default retention if r is nil
+func retentionFor(b *bucket.Bucket, r *object.Retention) *object.Retention {
+	if r != nil || b.LockMode == "" {
+		return r
+	}
+	return &object.Retention{
+		Mode:        b.LockMode,
+		RetainUntil: domain.TimeNow().AddDate(0, 0, b.LockDays),
+	}
+}
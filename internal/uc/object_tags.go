@@ -0,0 +1,27 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package uc
+
+import (
+	"context"
+
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+)
+
+// SetObjectTags replaces the tags of an object
+func SetObjectTags(ctx context.Context, b *bucket.Bucket, key string, tags map[string]string) (*object.Object, error) {
+	unlock := object.LockKey(b.Name, key)
+	defer unlock()
+
+	o, err := object.FindOne(ctx, b.Name, key, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := object.SetTags(ctx, o, tags); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
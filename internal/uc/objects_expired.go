@@ -0,0 +1,21 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package uc
+
+import (
+	"context"
+
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+)
+
+func onObjectsExpired(ctx context.Context, d object.ExpiredEvent) error {
+	b, err := bucket.FindOne(ctx, d.Bucket)
+	if err != nil {
+		return err
+	}
+
+	return ReconcileBucket(ctx, b)
+}
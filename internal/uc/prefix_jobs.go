@@ -0,0 +1,271 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package uc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cfichtmueller/srv"
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/job"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+// prefixBatchSize is the number of objects a prefix job processes between two checkpoints
+const prefixBatchSize = 1000
+
+var (
+	deletePrefixJob job.Type[DeletePrefixCommand]
+	copyPrefixJob   job.Type[CopyPrefixCommand]
+)
+
+func configurePrefixJobs() {
+	deletePrefixJob = job.Register("objects.delete-prefix", deletePrefix)
+	copyPrefixJob = job.Register("objects.copy-prefix", copyPrefix)
+}
+
+// DeletePrefixCommand deletes all objects of a bucket whose keys start with a prefix
+type DeletePrefixCommand struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix"`
+}
+
+func (c DeletePrefixCommand) Validate() error {
+	v := srv.RequireNotEmpty("prefix", c.Prefix, nil)
+	v = srv.RequireMaxLength("prefix", 1024, c.Prefix, v)
+	return srv.Validate(v)
+}
+
+// CopyPrefixCommand copies all objects of a bucket whose keys start with a prefix. The prefix of the copies'
+// keys is replaced with Destination.
+type CopyPrefixCommand struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix"`
+	// DestinationBucket is the bucket the objects are copied into. Objects are copied within Bucket if empty.
+	DestinationBucket string `json:"destinationBucket"`
+	Destination       string `json:"destination"`
+}
+
+func (c CopyPrefixCommand) Validate() error {
+	v := srv.RequireMaxLength("prefix", 1024, c.Prefix, nil)
+	v = srv.RequireMaxLength("destination", 1024, c.Destination, v)
+	if c.DestinationBucket == "" || c.DestinationBucket == c.Bucket {
+		// copies under the prefix would be copied again
+		v = srv.Require("destination", srv.ValidationCodeInvalid, "destination must not overlap with prefix",
+			!strings.HasPrefix(c.Destination, c.Prefix) && !strings.HasPrefix(c.Prefix, c.Destination), v)
+	}
+	return srv.Validate(v)
+}
+
+func (c CopyPrefixCommand) destinationBucket() string {
+	if c.DestinationBucket == "" {
+		return c.Bucket
+	}
+	return c.DestinationBucket
+}
+
+// prefixCheckpoint is the state a prefix job resumes from
+type prefixCheckpoint struct {
+	StartAfter string `json:"startAfter"`
+	Done       int64  `json:"done"`
+	Total      int64  `json:"total"`
+	// Skipped is the number of objects that couldn't be deleted or copied
+	Skipped int64 `json:"skipped"`
+}
+
+// PreviewDeletePrefix returns the number and size of the objects a DeletePrefix would delete
+func PreviewDeletePrefix(ctx context.Context, cmd DeletePrefixCommand) (*object.Stats, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	return object.StatsForPrefix(ctx, cmd.Bucket, cmd.Prefix)
+}
+
+// DeletePrefix queues a job that deletes all objects under a prefix. Objects under retention or legal hold are kept,
+// in which case the job fails with ec.BucketHasLockedObjects after all other objects have been deleted.
+func DeletePrefix(ctx context.Context, cmd DeletePrefixCommand) (*job.Job, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	return job.Enqueue(ctx, deletePrefixJob, cmd.Bucket, cmd.Bucket+"/"+cmd.Prefix, cmd)
+}
+
+// PreviewCopyPrefix returns the number and size of the objects a CopyPrefix would copy
+func PreviewCopyPrefix(ctx context.Context, cmd CopyPrefixCommand) (*object.Stats, error) {
+	if err := validateCopyPrefix(ctx, cmd); err != nil {
+		return nil, err
+	}
+	return object.StatsForPrefix(ctx, cmd.Bucket, cmd.Prefix)
+}
+
+// CopyPrefix queues a job that copies all objects under a prefix. A failing copy doesn't abort the remaining copies,
+// the job fails after all other objects have been copied.
+func CopyPrefix(ctx context.Context, cmd CopyPrefixCommand) (*job.Job, error) {
+	if err := validateCopyPrefix(ctx, cmd); err != nil {
+		return nil, err
+	}
+	return job.Enqueue(ctx, copyPrefixJob, cmd.Bucket, "", cmd)
+}
+
+func validateCopyPrefix(ctx context.Context, cmd CopyPrefixCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	if cmd.DestinationBucket != "" && cmd.DestinationBucket != cmd.Bucket {
+		if err := bucket.ValidateName(cmd.DestinationBucket); err != nil {
+			return err
+		}
+		if _, err := bucket.FindOne(ctx, cmd.DestinationBucket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deletePrefix(ctx context.Context, r *job.Run, cmd DeletePrefixCommand) error {
+	b, err := bucket.FindOne(ctx, cmd.Bucket)
+	if err != nil {
+		return prefixJobError(err)
+	}
+	c, err := resumePrefix(ctx, r, cmd.Bucket, cmd.Prefix)
+	if err != nil {
+		return err
+	}
+	// the counters are reconciled once, even if the job is cancelled or fails
+	defer reconcileAfterJob(ctx, b)
+
+	for {
+		objects, err := object.ListPrefix(ctx, b.Name, cmd.Prefix, c.StartAfter, prefixBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			break
+		}
+		for _, o := range objects {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			c.StartAfter = o.Key
+			c.Done++
+			if err := deletePrefixObject(ctx, b, o.Key); err != nil {
+				if errors.Is(err, ec.ObjectLocked) {
+					c.Skipped++
+					continue
+				}
+				return err
+			}
+		}
+		if err := r.Checkpoint(ctx, c, c.Done, c.Total); err != nil {
+			return err
+		}
+	}
+
+	if c.Skipped > 0 {
+		return job.Permanent(ec.BucketHasLockedObjects)
+	}
+	return nil
+}
+
+// deletePrefixObject deletes the object stored under key. The object is read again under the key lock
+// so that writes which happened since the listing are taken into account.
+func deletePrefixObject(ctx context.Context, b *bucket.Bucket, key string) error {
+	unlock := object.LockKey(b.Name, key)
+	defer unlock()
+
+	o, err := findExisting(ctx, b, key)
+	if err != nil {
+		return err
+	}
+	if o == nil {
+		return nil
+	}
+	if err := object.Delete(ctx, o); err != nil {
+		return err
+	}
+	publishObjectDeleted(ctx, o)
+	return nil
+}
+
+func copyPrefix(ctx context.Context, r *job.Run, cmd CopyPrefixCommand) error {
+	dst, err := bucket.FindOne(ctx, cmd.destinationBucket())
+	if err != nil {
+		return prefixJobError(err)
+	}
+	c, err := resumePrefix(ctx, r, cmd.Bucket, cmd.Prefix)
+	if err != nil {
+		return err
+	}
+	defer reconcileAfterJob(ctx, dst)
+
+	for {
+		objects, err := object.ListPrefix(ctx, cmd.Bucket, cmd.Prefix, c.StartAfter, prefixBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			break
+		}
+		for _, o := range objects {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			c.StartAfter = o.Key
+			c.Done++
+			if _, err := copyObjectFrom(ctx, dst, o, CopyObjectCommand{Key: cmd.Destination + o.Key[len(cmd.Prefix):]}); err != nil {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				slog.Warn("unable to copy object", "bucket", o.Bucket, "key", o.Key, "destination", dst.Name, "error", err)
+				c.Skipped++
+			}
+		}
+		if err := r.Checkpoint(ctx, c, c.Done, c.Total); err != nil {
+			return err
+		}
+	}
+
+	if c.Skipped > 0 {
+		return job.Permanent(fmt.Errorf("%d of %d objects couldn't be copied", c.Skipped, c.Done))
+	}
+	return nil
+}
+
+// resumePrefix returns the checkpoint of a prefix job. A new checkpoint is created on the first attempt.
+func resumePrefix(ctx context.Context, r *job.Run, bucketName, prefix string) (*prefixCheckpoint, error) {
+	var c prefixCheckpoint
+	ok, err := r.Resume(&c)
+	if err != nil || ok {
+		return &c, err
+	}
+	s, err := object.StatsForPrefix(ctx, bucketName, prefix)
+	if err != nil {
+		return nil, err
+	}
+	c.Total = s.ObjectCount
+	if err := r.Progress(ctx, 0, c.Total); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// prefixJobError fails a prefix job permanently if its bucket has been deleted
+func prefixJobError(err error) error {
+	if errors.Is(err, ec.NoSuchBucket) {
+		return job.Permanent(err)
+	}
+	return err
+}
+
+func reconcileAfterJob(ctx context.Context, b *bucket.Bucket) {
+	if err := ReconcileBucket(context.WithoutCancel(ctx), b); err != nil {
+		slog.Error("unable to reconcile bucket", "bucket", b.Name, "error", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package uc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+	"github.com/cfichtmueller/stor/internal/ec"
+)
+
+type PutObjectCommand struct {
+	Key         string
+	ContentType string
+	Data        []byte
+	Conditions  object.Conditions
+	// ExpiresAt is the time after which the object is deleted. The object never expires if nil.
+	ExpiresAt *time.Time
+	// Retention protects the object. The bucket's default retention is applied if nil.
+	Retention *object.Retention
+	LegalHold bool
+	// Tags replace the tags of the object
+	Tags map[string]string
+}
+
+// PutObject creates an object or a new version of an existing object. The conditions are checked
+// and the object is written while holding the key's lock.
+func PutObject(ctx context.Context, b *bucket.Bucket, cmd PutObjectCommand) (*object.Object, error) {
+	if err := object.ValidateTags(cmd.Tags); err != nil {
+		return nil, err
+	}
+	unlock := object.LockKey(b.Name, cmd.Key)
+	defer unlock()
+
+	existing, err := findExisting(ctx, b, cmd.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Conditions.Check(existing); err != nil {
+		return nil, err
+	}
+
+	var o *object.Object
+	if existing != nil {
+		var keep bool
+		if keep, err = lifecycle.KeepsVersions(ctx, existing); err != nil {
+			return nil, err
+		}
+		o, err = object.Update(ctx, existing, object.UpdateCommand{
+			ContentType:         cmd.ContentType,
+			Data:                cmd.Data,
+			ExpiresAt:           cmd.ExpiresAt,
+			Retention:           retentionFor(b, cmd.Retention),
+			LegalHold:           cmd.LegalHold,
+			KeepPreviousVersion: keep,
+		})
+	} else {
+		o, err = object.Create(ctx, b.Name, object.CreateCommand{
+			Key:         cmd.Key,
+			ContentType: cmd.ContentType,
+			Data:        cmd.Data,
+			ExpiresAt:   cmd.ExpiresAt,
+			Retention:   retentionFor(b, cmd.Retention),
+			LegalHold:   cmd.LegalHold,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := object.SetTags(ctx, o, cmd.Tags); err != nil {
+		return nil, err
+	}
+	publishObjectCreated(ctx, o)
+
+	if err := ReconcileBucket(ctx, b); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// findExisting finds an object. Returns nil if the object doesn't exist.
+func findExisting(ctx context.Context, b *bucket.Bucket, key string) (*object.Object, error) {
+	o, err := object.FindOne(ctx, b.Name, key, false)
+	if err != nil {
+		if errors.Is(err, ec.NoSuchKey) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return o, nil
+}
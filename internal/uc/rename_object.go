@@ -0,0 +1,31 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package uc
+
+import (
+	"context"
+
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+)
+
+// RenameObject changes the key of an object within its bucket. The object's data is not touched.
+func RenameObject(ctx context.Context, b *bucket.Bucket, o *object.Object, key string) error {
+	if err := object.ValidateKey(key); err != nil {
+		return err
+	}
+	if key == o.Key {
+		return nil
+	}
+	unlock := object.LockKeys(b.Name, o.Key, key)
+	defer unlock()
+	old := *o
+	if err := object.Rename(ctx, o, key); err != nil {
+		return err
+	}
+	publishObjectDeleted(ctx, &old)
+	publishObjectCreated(ctx, o)
+	return nil
+}
@@ -5,6 +5,8 @@
 package ui
 
 import (
+	"strings"
+
 	"github.com/cfichtmueller/goparts/e"
 	"github.com/cfichtmueller/stor/internal/domain/apikey"
 )
@@ -42,6 +44,7 @@ func ApiKeyCreatedDialog(k *apikey.ApiKey, plain string) e.Node {
 			e.Div(
 				e.Class("grid gap-4 py-4"),
 				e.Div(e.Raw("Your API key has been created. Please copy your key since you won't be able to access it again")),
+				e.Div(e.Raw("The key isn't allowed to do anything until you grant it actions on buckets in its settings.")),
 				e.Div(e.Raw("Your API key")),
 				e.Div(
 					e.Class("border p-2 flex items-center"),
@@ -83,6 +86,7 @@ func ApiKeySheet(key *apikey.ApiKey) e.Node {
 			Title: "API Key",
 			Lead:  "Make changes to the API key here.",
 		},
+		apiKeyPolicyForm(key),
 		e.Div(
 			e.Class("flex justify-end"),
 			e.Button(
@@ -96,6 +100,54 @@ func ApiKeySheet(key *apikey.ApiKey) e.Node {
 	)
 }
 
+// apiKeyPolicyForm edits what an API key is allowed to do. Everything that isn't granted is denied.
+func apiKeyPolicyForm(key *apikey.ApiKey) e.Node {
+	return e.Form(
+		e.Class("grid gap-4 py-4"),
+		e.HXPut("/r/api-key-policy?key="+key.ID),
+		e.Div(
+			e.Class("flex flex-col gap-y-1"),
+			e.Span(e.Class(cnLabel), e.Text("Actions")),
+			e.Mapf(apikey.Actions, func(action string) e.Node {
+				return e.Label(
+					e.Class("inline-flex items-center gap-x-2 text-sm"),
+					e.Input(
+						e.Type("checkbox"),
+						e.Name("actions"),
+						e.Value(action),
+						e.If(key.Policy.HasAction(action), e.Attr("checked", "checked")),
+					),
+					e.Text(action),
+				)
+			}),
+		),
+		apiKeyPolicyLines("buckets", "Buckets", "One bucket name or pattern per line, e.g. photos-*", key.Policy.Buckets),
+		apiKeyPolicyLines("prefixes", "Key prefixes", "One prefix per line. All keys if empty.", key.Policy.Prefixes),
+		e.Div(
+			e.Class("flex justify-end"),
+			e.Button(
+				e.Class(cn(btn, btnPrimary)),
+				e.Type("submit"),
+				e.Raw("Save"),
+			),
+		),
+	)
+}
+
+func apiKeyPolicyLines(name, label, hint string, lines []string) e.Node {
+	return e.Div(
+		e.Class("flex flex-col gap-y-1"),
+		e.Label(e.For(name), e.Class(cnLabel), e.Text(label)),
+		e.Textarea(
+			e.Class(cn(cnInput, "h-24")),
+			e.Id(name),
+			e.Name(name),
+			e.Text(strings.Join(lines, "\n")),
+		),
+		e.P(e.Class("text-sm text-muted-foreground"), e.Text(hint)),
+	)
+}
+
 func CreateApiKeyDialog() e.Node {
 	return e.Form(
 		e.HXPost("/r/api-key"),
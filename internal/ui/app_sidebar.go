@@ -9,6 +9,8 @@ import "github.com/cfichtmueller/goparts/e"
 const (
 	app_sidebar_active_dashboard = "dashboard"
 	app_sidebar_active_buckets   = "buckets"
+	app_sidebar_active_search    = "search"
+	app_sidebar_active_jobs      = "jobs"
 	app_sidebar_active_admin     = "admin"
 	app_sidebar_active_profile   = "profile"
 )
@@ -17,6 +19,8 @@ func appSidebar(active string) e.Node {
 	return Sidebar(
 		SidebarItem{Title: "Dashboard", Link: dashboardLink, Active: active == app_sidebar_active_dashboard, Icon: IconGauge},
 		SidebarItem{Title: "Buckets", Link: bucketsLink, Active: active == app_sidebar_active_buckets, Icon: IconArchive},
+		SidebarItem{Title: "Search", Link: searchLink, Active: active == app_sidebar_active_search, Icon: IconSearch},
+		SidebarItem{Title: "Jobs", Link: jobsLink, Active: active == app_sidebar_active_jobs, Icon: IconListChecks},
 		SidebarItem{Title: "Admin", Link: adminLink, Active: active == app_sidebar_active_admin, Icon: IconCog},
 		SidebarItem{Title: "Profile", Link: profileLink, Active: active == app_sidebar_active_profile, Icon: IconUserRound},
 	)
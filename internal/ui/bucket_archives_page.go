@@ -0,0 +1,105 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"net/url"
+
+	"github.com/cfichtmueller/goparts/e"
+	"github.com/cfichtmueller/stor/internal/domain/archive"
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+)
+
+type BucketArchivesPageData struct {
+	Bucket   *bucket.Bucket
+	Archives []*archive.Archive
+	// Cursor lists the older archives if it isn't empty
+	Cursor string
+}
+
+func BucketArchivesPage(d BucketArchivesPageData) e.Node {
+	links := NewBucketLinks(d.Bucket.Name)
+	return BucketPage(
+		links,
+		bucket_navtabs_active_archives,
+		PathBreadcrumbs(links, d.Bucket, ""),
+		PageTitle(""),
+		e.If(len(d.Archives) == 0, e.P(
+			e.Class("p-4 text-sm text-muted-foreground"),
+			e.Text("No archives have been created. Select objects in the objects tab to create an archive."),
+		)),
+		e.If(len(d.Archives) > 0, ArchivesTable(d.Archives)),
+		e.If(d.Cursor != "", e.A(
+			e.Class(cn(btn, "mt-2")),
+			e.Href(links.Archives+"?cursor="+url.QueryEscape(d.Cursor)),
+			e.Raw("Older archives"),
+		)),
+	)
+}
+
+func ArchivesTable(archives []*archive.Archive) e.Node {
+	return Table(
+		TableHeader(
+			TableHead("", e.Text("Key")),
+			TableHead("", e.Text("State")),
+			TableHead("text-right", e.Text("Entries")),
+			TableHead("text-right", e.Text("Size")),
+			TableHead("", e.Text("Created at")),
+			TableHead("", e.Text("Created by")),
+			TableHead("", e.Text("")),
+		),
+		TableBody(
+			e.Mapf(archives, ArchivesTableRow),
+		),
+	)
+}
+
+func ArchivesTableRow(a *archive.Archive) e.Node {
+	rpcQuery := "?bucket=" + a.Bucket + "&key=" + url.QueryEscape(a.Key) + "&archive=" + a.ID
+	return TableRow(
+		TableCellC("p-2 align-middle font-mono", e.Text(a.Key)),
+		TableCellC("p-2 align-middle",
+			e.Text(a.State),
+			e.If(a.FailureReason != "", e.P(
+				e.Class("text-sm text-muted-foreground"),
+				e.Text(a.FailureReason),
+			)),
+		),
+		TableCellC("text-right", e.Text(formatArchiveEntries(a))),
+		TableCellC("text-right", e.Text(formatBytes(a.BytesWritten))),
+		TableCellC("p-2 align-middle whitespace-nowrap", e.Text(formatDateTime(a.CreatedAt))),
+		TableCellC("p-2 align-middle", e.Text(formatFilter(a.CreatedBy))),
+		TableCellC("p-2 flex w-full justify-end gap-x-2 align-middle",
+			e.If(a.State == archive.StateComplete, e.A(
+				e.Class(cn(btn, "shadow")),
+				e.Href(DownloadObjectLink(a.Bucket, a.Key)),
+				e.Text("Download"),
+			)),
+			e.If(a.State == archive.StateProcessing, e.Button(
+				e.Class(cn(btn, btnDanger)),
+				e.HXPost("/r/cancel-archive"+rpcQuery),
+				e.Text("Cancel"),
+			)),
+			e.If(a.State == archive.StateFailed || a.State == archive.StateCancelled, e.Button(
+				e.Class(cn(btn, "shadow")),
+				e.HXPost("/r/retry-archive"+rpcQuery),
+				e.Text("Retry"),
+			)),
+			e.If(a.State == archive.StatePending || a.State == archive.StateFailed || a.State == archive.StateCancelled, e.Button(
+				e.Class(cn(btn, btnDanger)),
+				e.HXDelete("/r/archive"+rpcQuery),
+				e.Text("Abort"),
+			)),
+		),
+	)
+}
+
+func formatArchiveEntries(a *archive.Archive) string {
+	s := formatInt64(a.EntriesDone) + " / " + formatInt64(a.EntriesTotal)
+	if a.EntriesSkipped > 0 {
+		s += " (" + formatInt64(a.EntriesSkipped) + " skipped)"
+	}
+	return s
+}
@@ -9,6 +9,7 @@ import "github.com/cfichtmueller/goparts/e"
 const (
 	bucket_navtabs_active_objects    = "objects"
 	bucket_navtabs_active_properties = "properties"
+	bucket_navtabs_active_archives   = "archives"
 	bucket_navtabs_active_settings   = "settings"
 )
 
@@ -21,6 +22,12 @@ func BucketNavTabs(links *BucketLinks, active string) e.Node {
 			Icon:   IconSlidersHorizontal,
 			Active: active == bucket_navtabs_active_properties,
 		},
+		&NavLink{
+			Title:  "Archives",
+			Link:   links.Archives,
+			Icon:   IconArchive,
+			Active: active == bucket_navtabs_active_archives,
+		},
 		&NavLink{
 			Title:  "Settings",
 			Link:   links.Settings,
@@ -12,6 +12,7 @@ import (
 type BucketObjectsPageData struct {
 	Bucket  *bucket.Bucket
 	Prefix  string
+	Query   ObjectsQuery
 	Objects []ObjectData
 }
 
@@ -27,9 +28,43 @@ func BucketObjectsPage(d BucketObjectsPageData) e.Node {
 			BucketNavTabs(links, bucket_navtabs_active_objects),
 			e.Div(
 				e.Class("p-2"),
+				ObjectsFilterForm(links, d.Query),
+				e.If(hasObjects, objectsSelectionActions(d.Bucket.Name)),
 				e.Iff(hasObjects, e.F(ObjectsTable, d.Objects)),
-				e.Iff(!hasObjects, BucketEmptyState),
+				e.Iff(!hasObjects && !d.Query.Filtered(), BucketEmptyState),
+				e.If(!hasObjects && d.Query.Filtered(), e.P(
+					e.Class("p-4 text-sm text-muted-foreground"),
+					e.Text("No objects match the filters"),
+				)),
 			),
 		),
 	)
 }
+
+// objectsSelectionActions downloads or archives the objects that are selected in the objects table
+func objectsSelectionActions(bucketName string) e.Node {
+	return e.Form(
+		e.Id(objectsSelectionForm),
+		e.Class("flex items-center gap-2 pb-2"),
+		e.Method("post"),
+		e.Action(downloadZipLink),
+		e.Input(e.Type("hidden"), e.Name("bucket"), e.Value(bucketName)),
+		e.Button(
+			e.Class(cn(btn)),
+			e.Type("submit"),
+			e.Raw("Download selected as zip"),
+		),
+		e.Input(
+			e.Class(cn(cnInput, "w-48")),
+			e.Type("text"),
+			e.Name("archive-key"),
+			e.Placeholder("Archive key, e.g. backup.zip"),
+		),
+		e.Button(
+			e.Class(cn(btn)),
+			e.Type("button"),
+			e.HXPost("/r/archive"),
+			e.Raw("Create archive"),
+		),
+	)
+}
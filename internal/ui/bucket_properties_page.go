@@ -21,6 +21,11 @@ func BucketPropertiesPage(b *bucket.Bucket) e.Node {
 			Detail("Objects", formatInt(int(b.Objects))),
 			Detail("Size", formatBytes(b.Size)),
 			Detail("Created at", formatDateTime(b.CreatedAt)),
+			e.If(b.LockMode != "", Detail("Default retention", formatDefaultRetention(b))),
 		),
 	)
 }
+
+func formatDefaultRetention(b *bucket.Bucket) string {
+	return formatRetentionMode(b.LockMode) + ", " + formatDays(b.LockDays)
+}
@@ -7,15 +7,32 @@ package ui
 import (
 	"github.com/cfichtmueller/goparts/e"
 	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+	"github.com/cfichtmueller/stor/internal/domain/notification"
 )
 
-func BucketSettingsPage(b *bucket.Bucket) e.Node {
+func BucketSettingsPage(b *bucket.Bucket, rules []*lifecycle.Rule, webhooks []*notification.Webhook, deadLetters []*notification.Delivery) e.Node {
 	links := NewBucketLinks(b.Name)
 	return BucketPage(
 		links,
 		bucket_navtabs_active_settings,
 		PathBreadcrumbs(links, b, ""),
 		PageTitle(""),
+		e.Div(
+			e.Class("flex flex-col gap-y-2 mb-8"),
+			e.H3(e.Class("text-lg font-semibold"), e.Text("Lifecycle Rules")),
+			LifecycleRulesTable(b.Name, rules),
+		),
+		e.Div(
+			e.Class("flex flex-col gap-y-2 mb-8"),
+			e.H3(e.Class("text-lg font-semibold"), e.Text("Webhooks")),
+			WebhooksTable(b.Name, webhooks),
+		),
+		e.Div(
+			e.Class("flex flex-col gap-y-2 mb-8"),
+			e.H3(e.Class("text-lg font-semibold"), e.Text("Failed Deliveries")),
+			DeadLettersTable(deadLetters),
+		),
 		e.Div(
 			e.Class("flex flex-col gap-y-2"),
 			e.Button(
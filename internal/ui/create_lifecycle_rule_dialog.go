@@ -0,0 +1,63 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"github.com/cfichtmueller/goparts/e"
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+)
+
+func CreateLifecycleRuleDialog(b *bucket.Bucket) e.Node {
+	return e.Form(
+		e.HXPost("/r/lifecycle-rule?bucket="+b.Name),
+		e.HXSwap("delete"),
+		e.Id("create-lifecycle-rule-dialog"),
+		DialogBackdrop(),
+		DialogContent(
+			DialogTitle(e.Text("Add Lifecycle Rule")),
+			e.Div(
+				e.Class("grid gap-4 py-4"),
+				lifecycleRuleField("rulePrefix", "prefix", "Prefix", e.Type("text")),
+				lifecycleRuleField("ruleTags", "tags", "Tags", e.Type("text"), e.Placeholder("key1=value1&key2=value2")),
+				lifecycleRuleField("ruleExpirationDays", "expirationDays", "Expire after (days)", e.Type("number"), e.Min("0"), e.Value("0")),
+				lifecycleRuleField("ruleKeepVersions", "keepVersions", "Keep versions", e.Type("number"), e.Min("0"), e.Value("0")),
+				lifecycleRuleField("ruleAbortIncompleteDays", "abortIncompleteDays", "Abort uploads after (days)", e.Type("number"), e.Min("0"), e.Value("0")),
+				e.Div(
+					e.Class("flex flex-col-reverse sm:flex-row sm:justify-end sm:space-x-2"),
+					e.Button(
+						e.Class(cn(btn, btnPrimary)),
+						e.Type("submit"),
+						e.Raw("Add"),
+					),
+				),
+				e.Button(
+					e.Type("button"),
+					e.Class(cnDIalogCloseButton),
+					e.Attr("data-remove", "create-lifecycle-rule-dialog"),
+					IconDialogClose,
+					e.Span(srOnly(), e.Raw("Close")),
+				),
+			),
+		),
+	)
+}
+
+func lifecycleRuleField(id, name, label string, attrs ...e.Node) e.Node {
+	return e.Div(
+		e.Class("grid grid-cols-4 items-center gap-4"),
+		e.Label(
+			e.For(id),
+			e.Class(cn(cnLabel, "text-right")),
+			e.Text(label),
+		),
+		e.Input(
+			append([]e.Node{
+				e.Id(id),
+				e.Class(cn(cnInput, "col-span-3")),
+				e.Name(name),
+			}, attrs...)...,
+		),
+	)
+}
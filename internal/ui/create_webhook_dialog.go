@@ -0,0 +1,66 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"github.com/cfichtmueller/goparts/e"
+	"github.com/cfichtmueller/stor/internal/domain/bucket"
+	"github.com/cfichtmueller/stor/internal/domain/notification"
+)
+
+func CreateWebhookDialog(b *bucket.Bucket) e.Node {
+	return e.Form(
+		e.HXPost("/r/webhook?bucket="+b.Name),
+		e.HXSwap("delete"),
+		e.Id("create-webhook-dialog"),
+		DialogBackdrop(),
+		DialogContent(
+			DialogTitle(e.Text("Add Webhook")),
+			e.Div(
+				e.Class("grid gap-4 py-4"),
+				lifecycleRuleField("webhookUrl", "url", "URL", e.Type("url"), e.Placeholder("https://example.com/hook")),
+				e.Div(
+					e.Class("grid grid-cols-4 items-start gap-4"),
+					e.Span(
+						e.Class(cn(cnLabel, "text-right")),
+						e.Text("Events"),
+					),
+					e.Div(
+						e.Class("col-span-3 flex flex-col gap-y-1"),
+						e.Mapf(notification.Events, func(event string) e.Node {
+							return e.Label(
+								e.Class("inline-flex items-center gap-x-2 text-sm"),
+								e.Input(
+									e.Type("checkbox"),
+									e.Name("events"),
+									e.Value(event),
+									e.Attr("checked", "checked"),
+								),
+								e.Text(event),
+							)
+						}),
+					),
+				),
+				lifecycleRuleField("webhookPrefix", "prefix", "Prefix", e.Type("text")),
+				lifecycleRuleField("webhookSuffix", "suffix", "Suffix", e.Type("text"), e.Placeholder(".jpg")),
+				e.Div(
+					e.Class("flex flex-col-reverse sm:flex-row sm:justify-end sm:space-x-2"),
+					e.Button(
+						e.Class(cn(btn, btnPrimary)),
+						e.Type("submit"),
+						e.Raw("Add"),
+					),
+				),
+				e.Button(
+					e.Type("button"),
+					e.Class(cnDIalogCloseButton),
+					e.Attr("data-remove", "create-webhook-dialog"),
+					IconDialogClose,
+					e.Span(srOnly(), e.Raw("Close")),
+				),
+			),
+		),
+	)
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"github.com/cfichtmueller/goparts/e"
+	"github.com/cfichtmueller/stor/internal/domain/job"
+)
+
+type JobsPageData struct {
+	Running []*job.Job
+	Queued  []*job.Job
+	Failed  []*job.Job
+}
+
+func JobsPage(d JobsPageData) e.Node {
+	return ListPageLayout(
+		"Jobs",
+		appSidebar(app_sidebar_active_jobs),
+		jobsSection("Running", "No jobs are running.", d.Running),
+		jobsSection("Queued", "No jobs are queued.", d.Queued),
+		jobsSection("Failed", "No jobs have failed.", d.Failed),
+	)
+}
+
+func jobsSection(title, empty string, jobs []*job.Job) e.Node {
+	return e.Div(
+		e.Class("flex flex-col gap-y-2 mb-8"),
+		e.H3(e.Class("text-lg font-semibold"), e.Text(title)),
+		e.If(len(jobs) == 0, e.P(
+			e.Class("text-sm text-muted-foreground"),
+			e.Text(empty),
+		)),
+		e.If(len(jobs) > 0, JobsTable(jobs)),
+	)
+}
+
+func JobsTable(jobs []*job.Job) e.Node {
+	return Table(
+		TableHeader(
+			TableHead("", e.Text("Type")),
+			TableHead("", e.Text("Bucket")),
+			TableHead("text-right", e.Text("Progress")),
+			TableHead("text-right", e.Text("Attempts")),
+			TableHead("", e.Text("Error")),
+			TableHead("", e.Text("Updated at")),
+			TableHead("", e.Text("")),
+		),
+		TableBody(
+			e.Mapf(jobs, JobsTableRow),
+		),
+	)
+}
+
+func JobsTableRow(j *job.Job) e.Node {
+	return TableRow(
+		TableCellC("p-2 align-middle font-mono", e.Text(j.Type)),
+		TableCellC("p-2 align-middle", e.Text(formatFilter(j.Bucket))),
+		TableCellC("text-right", e.Text(formatJobProgress(j))),
+		TableCellC("text-right", e.Text(formatInt(j.Attempts)+" / "+formatInt(j.MaxAttempts))),
+		TableCellC("p-2 align-middle", e.Text(j.Error)),
+		TableCellC("p-2 align-middle whitespace-nowrap", e.Text(formatDateTime(j.UpdatedAt))),
+		TableCellC("p-2 flex w-full justify-end gap-x-2 align-middle",
+			e.If(!j.Finished() && !j.CancelRequested, e.Button(
+				e.Class(cn(btn, btnDanger)),
+				e.HXPost("/r/cancel-job?job="+j.ID),
+				e.Text("Cancel"),
+			)),
+			e.If(j.CancelRequested && !j.Finished(), e.Span(
+				e.Class("text-sm text-muted-foreground"),
+				e.Text("Cancelling"),
+			)),
+			e.If(j.State == job.StateFailed || j.State == job.StateCancelled, e.Button(
+				e.Class(cn(btn, "shadow")),
+				e.HXPost("/r/retry-job?job="+j.ID),
+				e.Text("Retry"),
+			)),
+		),
+	)
+}
+
+func formatJobProgress(j *job.Job) string {
+	if p := j.Percent(); p >= 0 {
+		return formatInt64(j.Done) + " / " + formatInt64(j.Total) + " (" + formatInt(p) + "%)"
+	}
+	if j.Done > 0 {
+		return formatInt64(j.Done)
+	}
+	return "-"
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"github.com/cfichtmueller/goparts/e"
+	"github.com/cfichtmueller/stor/internal/domain/lifecycle"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+)
+
+func LifecycleRulesTable(bucket string, rules []*lifecycle.Rule) e.Node {
+	return Table(
+		TableHeader(
+			TableHead("", e.Text("Prefix")),
+			TableHead("", e.Text("Tags")),
+			TableHead("text-right", e.Text("Expire after")),
+			TableHead("text-right", e.Text("Keep versions")),
+			TableHead("text-right", e.Text("Abort uploads after")),
+			TableHead("flex justify-end", e.Button(
+				e.Class(cn(btn, btnPrimary)),
+				e.HXGet("/c/create-lifecycle-rule-dialog?bucket="+bucket),
+				e.HXTarget("body"),
+				e.HXSwap("beforeend"),
+				IconPlus,
+				e.Span(
+					srOnly(),
+					e.Text("Add Rule"),
+				),
+			)),
+		),
+		TableBody(
+			e.Mapf(rules, LifecycleRulesTableRow),
+		),
+	)
+}
+
+func LifecycleRulesTableRow(r *lifecycle.Rule) e.Node {
+	prefix := r.Prefix
+	if prefix == "" {
+		prefix = "(all objects)"
+	}
+	return TableRow(
+		TableCellC("p-2 align-middle", e.Text(prefix)),
+		TableCellC("p-2 align-middle", e.Text(formatTags(r.Tags))),
+		TableCellC("text-right", e.Text(formatDays(r.ExpirationDays))),
+		TableCellC("text-right", e.Text(formatCount(r.KeepVersions))),
+		TableCellC("text-right", e.Text(formatDays(r.AbortIncompleteDays))),
+		TableCellC("p-2 flex w-full justify-end align-middle",
+			e.Button(
+				e.Class(cn(btn, btnDanger)),
+				e.HXDelete("/r/lifecycle-rule?bucket="+r.Bucket+"&rule="+r.ID),
+				IconTrash,
+				e.Span(srOnly(), e.Text("Delete Rule")),
+			),
+		),
+	)
+}
+
+func formatDays(days int) string {
+	switch days {
+	case 0:
+		return "-"
+	case 1:
+		return "1 day"
+	default:
+		return formatInt(days) + " days"
+	}
+}
+
+func formatCount(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return formatInt(n)
+}
+
+func formatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return "-"
+	}
+	return object.FormatTags(tags)
+}
@@ -12,16 +12,21 @@ import (
 const (
 	dashboardLink = "/u"
 	bucketsLink   = "/u/buckets"
+	searchLink    = "/u/search"
+	jobsLink      = "/u/jobs"
 	adminLink     = "/u/admin"
 	usersLink     = "/u/admin/users"
 	apiKeysLink   = "/u/admin/api-keys"
 	profileLink   = "/u/profile"
+
+	downloadZipLink = "/download-zip"
 )
 
 type BucketLinks struct {
 	base       string
 	Objects    string
 	Properties string
+	Archives   string
 	Settings   string
 }
 
@@ -31,6 +36,7 @@ func NewBucketLinks(bucketName string) *BucketLinks {
 		base:       base,
 		Objects:    base + "/objects",
 		Properties: base + "/properties",
+		Archives:   base + "/archives",
 		Settings:   base + "/settings",
 	}
 }
@@ -50,3 +56,11 @@ func DownloadObjectLink(bucket, key string) string {
 func OpenObjectLink(bucket, key string) string {
 	return fmt.Sprintf("/open?bucket=%s&key=%s", bucket, url.QueryEscape(key))
 }
+
+func RenameObjectDialogLink(bucket, key string) string {
+	return fmt.Sprintf("/c/rename-object-dialog?bucket=%s&key=%s", bucket, url.QueryEscape(key))
+}
+
+func SearchPageLink(query, afterBucket, afterKey string) string {
+	return fmt.Sprintf("%s?q=%s&after-bucket=%s&after-key=%s", searchLink, url.QueryEscape(query), url.QueryEscape(afterBucket), url.QueryEscape(afterKey))
+}
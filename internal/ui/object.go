@@ -4,10 +4,22 @@
 
 package ui
 
+import (
+	"time"
+
+	"github.com/cfichtmueller/stor/internal/domain/object"
+)
+
 type ObjectData struct {
-	Key  string
-	Size int64
-	Href string
+	Key string
+	// ObjectKey is the full key of an object. It is empty for folders.
+	ObjectKey string
+	Size      int64
+	// CreatedAt is the time the object has been created. It is zero for folders.
+	CreatedAt time.Time
+	Href      string
+	// Locked indicates that the object is under retention or legal hold
+	Locked bool
 }
 
 type objectModel struct {
@@ -36,3 +48,14 @@ func newObjectNavTabs(objectsLink, active string) *NavTabsModel {
 		},
 	}
 }
+
+func formatRetention(r *object.Retention) string {
+	return formatRetentionMode(r.Mode) + " until " + formatDateTime(r.RetainUntil)
+}
+
+func formatRetentionMode(mode string) string {
+	if mode == object.RetentionCompliance {
+		return "Compliance"
+	}
+	return "Governance"
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/cfichtmueller/stor/internal/domain/object"
 )
 
-func ObjectPropertiesPage(b *bucket.Bucket, o *object.Object) e.Node {
+func ObjectPropertiesPage(b *bucket.Bucket, o *object.Object, tags map[string]string) e.Node {
 	links := NewBucketLinks(b.Name)
 	return LoggedInLayout(
 		appSidebar(app_sidebar_active_buckets),
@@ -28,9 +28,24 @@ func ObjectPropertiesPage(b *bucket.Bucket, o *object.Object) e.Node {
 					Detail("Key", o.Key),
 					Detail("Size", formatBytes(o.Size)),
 					Detail("Created at", formatDateTime(o.CreatedAt)),
+					e.Iff(o.ExpiresAt != nil, func() e.Node {
+						return Detail("Expires at", formatDateTime(*o.ExpiresAt))
+					}),
+					e.Iff(o.Retention != nil, func() e.Node {
+						return Detail("Retention", formatRetention(o.Retention))
+					}),
+					e.If(o.LegalHold, Detail("Legal hold", "On")),
+					e.If(len(tags) > 0, Detail("Tags", formatTags(tags))),
 				),
 				e.Div(
 					e.Class("flex justify-end gap-x-2"),
+					e.Button(
+						e.Class(cn(btn, "shadow")),
+						e.HXGet(RenameObjectDialogLink(b.Name, o.Key)),
+						e.HXTarget("body"),
+						e.HXSwap("beforeend"),
+						e.Raw("Rename"),
+					),
 					e.Button(
 						e.Class(cn(btn, "shadow")),
 						e.A(
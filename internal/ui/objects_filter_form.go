@@ -0,0 +1,83 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"github.com/cfichtmueller/goparts/e"
+)
+
+// ObjectsQuery contains the sort and filter controls of the objects page
+type ObjectsQuery struct {
+	Prefix         string
+	SortBy         string
+	Order          string
+	ContentType    string
+	ModifiedWithin string
+}
+
+// Filtered returns true if the objects are not browsed by folder
+func (q ObjectsQuery) Filtered() bool {
+	return (q.SortBy != "" && q.SortBy != "key") || q.Order == "desc" || q.ContentType != "" || q.ModifiedWithin != ""
+}
+
+type selectOption struct {
+	value string
+	label string
+}
+
+func ObjectsFilterForm(links *BucketLinks, q ObjectsQuery) e.Node {
+	return e.Form(
+		e.Class("flex flex-wrap items-center gap-2 pb-2"),
+		e.Method("get"),
+		e.Action(links.Objects),
+		e.Input(e.Type("hidden"), e.Name("prefix"), e.Value(q.Prefix)),
+		objectsFilterSelect("sort-by", q.SortBy, []selectOption{
+			{"key", "Name"},
+			{"last-modified", "Created"},
+			{"size", "Size"},
+		}),
+		objectsFilterSelect("order", q.Order, []selectOption{
+			{"asc", "Ascending"},
+			{"desc", "Descending"},
+		}),
+		objectsFilterSelect("modified-within", q.ModifiedWithin, []selectOption{
+			{"", "Any time"},
+			{"1h", "Last hour"},
+			{"24h", "Last day"},
+			{"168h", "Last week"},
+		}),
+		e.Input(
+			e.Class(cn(cnInput, "w-48")),
+			e.Type("text"),
+			e.Name("content-type"),
+			e.Value(q.ContentType),
+			e.Placeholder("Content type, e.g. image/*"),
+		),
+		e.Button(
+			e.Class(cn(btn, "shadow")),
+			e.Type("submit"),
+			e.Raw("Apply"),
+		),
+		e.If(q.Filtered(), e.A(
+			e.Class(cn(btn)),
+			e.Href(links.Folder(q.Prefix)),
+			e.Raw("Reset"),
+		)),
+	)
+}
+
+func objectsFilterSelect(name, value string, options []selectOption) e.Node {
+	return e.Select(
+		e.Class(cn(cnInput, "w-40")),
+		e.Name(name),
+		e.Mapf(options, func(o selectOption) e.Node {
+			return e.Option(
+				e.Value(o.value),
+				e.If(o.value == value, e.Attr("selected", "selected")),
+				e.Text(o.label),
+			)
+		}),
+	)
+}
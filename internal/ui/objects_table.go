@@ -6,24 +6,47 @@ package ui
 
 import "github.com/cfichtmueller/goparts/e"
 
+// objectsSelectionForm is the id of the form that submits the selected objects
+const objectsSelectionForm = "objects-selection"
+
 func ObjectsTable(objects []ObjectData) e.Node {
 	return Table(
 		TableHeader(
+			TableHead("w-8"),
 			TableHead("", e.Text("Key")),
 			TableHead("", e.Text("Size")),
+			TableHead("", e.Text("Created")),
 		),
 		TableBody(
 			e.Mapf(objects, func(o ObjectData) e.Node {
 				return TableRow(
+					TableCellC(
+						"w-8",
+						e.If(o.ObjectKey != "", e.Input(
+							e.Type("checkbox"),
+							e.Name("key"),
+							e.Value(o.ObjectKey),
+							e.Attr("form", objectsSelectionForm),
+							e.Attr("aria-label", "Select "+o.Key),
+						)),
+					),
 					TableCell(
 						e.A(
+							e.Class("inline-flex items-center gap-x-1"),
 							e.Href(o.Href),
 							e.Text(o.Key),
+							e.If(o.Locked, e.Span(
+								e.Attr("title", "Locked"),
+								IconLock,
+							)),
 						),
 					),
 					TableCell(
 						e.Iff(o.Size > 0, e.F(bytesText, o.Size)),
 					),
+					TableCell(
+						e.Iff(!o.CreatedAt.IsZero(), func() e.Node { return e.Text(formatDateTime(o.CreatedAt)) }),
+					),
 				)
 			}),
 		),
@@ -0,0 +1,57 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"net/url"
+
+	"github.com/cfichtmueller/goparts/e"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+)
+
+func RenameObjectDialog(o *object.Object) e.Node {
+	return e.Form(
+		e.HXPost("/r/rename-object?bucket="+o.Bucket+"&key="+url.QueryEscape(o.Key)),
+		e.HXSwap("delete"),
+		e.Id("rename-object-dialog"),
+		DialogBackdrop(),
+		DialogContent(
+			DialogTitle(e.Text("Rename Object")),
+			e.Div(
+				e.Class("grid gap-4 py-4"),
+				e.Div(
+					e.Class("grid grid-cols-4 items-center gap-4"),
+					e.Label(
+						e.For("objectKey"),
+						e.Class(cn(cnLabel, "text-right")),
+						e.Text("Key"),
+					),
+					e.Input(
+						e.Id("objectKey"),
+						e.Class(cn(cnInput, "col-span-3")),
+						e.Name("key"),
+						e.Value(o.Key),
+						e.Required(),
+					),
+				),
+				e.Div(
+					e.Class("flex flex-col-reverse sm:flex-row sm:justify-end sm:space-x-2"),
+					e.Button(
+						e.Class(cn(btn, btnPrimary)),
+						e.Type("submit"),
+						e.Raw("Rename"),
+					),
+				),
+				e.Button(
+					e.Type("button"),
+					e.Class(cnDIalogCloseButton),
+					e.Attr("data-remove", "rename-object-dialog"),
+					IconDialogClose,
+					e.Span(srOnly(), e.Raw("Close")),
+				),
+			),
+		),
+	)
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"github.com/cfichtmueller/goparts/e"
+	"github.com/cfichtmueller/stor/internal/domain/object"
+)
+
+type SearchPageData struct {
+	Query   string
+	Error   string
+	Results []*object.Object
+	// NextLink is the link to the next page of results. It is empty on the last page.
+	NextLink string
+}
+
+func SearchPage(d SearchPageData) e.Node {
+	return ListPageLayout(
+		"Search",
+		appSidebar(app_sidebar_active_search),
+		searchForm(d.Query),
+		e.If(d.Error != "", e.P(
+			e.Class("text-sm text-red-600 py-2"),
+			e.Text(d.Error),
+		)),
+		e.If(d.Query != "" && d.Error == "" && len(d.Results) == 0, e.P(
+			e.Class("text-sm text-muted-foreground py-2"),
+			e.Text("No objects found."),
+		)),
+		e.If(len(d.Results) > 0, SearchResultsTable(d.Results)),
+		e.If(d.NextLink != "", e.Div(
+			e.Class("flex justify-end pt-2"),
+			e.A(
+				e.Class(cn(btn, "shadow")),
+				e.Href(d.NextLink),
+				e.Raw("Next page"),
+			),
+		)),
+	)
+}
+
+func searchForm(query string) e.Node {
+	return e.Form(
+		e.Class("flex items-center gap-2 pb-2"),
+		e.Method("get"),
+		e.Action(searchLink),
+		e.Input(
+			e.Class(cn(cnInput, "w-96")),
+			e.Type("search"),
+			e.Name("q"),
+			e.Value(query),
+			e.Placeholder("Search object keys in all buckets"),
+		),
+		e.Button(
+			e.Class(cn(btn, btnPrimary)),
+			e.Type("submit"),
+			e.Raw("Search"),
+		),
+	)
+}
+
+func SearchResultsTable(objects []*object.Object) e.Node {
+	return Table(
+		TableHeader(
+			TableHead("", e.Text("Bucket")),
+			TableHead("", e.Text("Key")),
+			TableHead("", e.Text("Size")),
+			TableHead("", e.Text("Last modified")),
+		),
+		TableBody(
+			e.Mapf(objects, func(o *object.Object) e.Node {
+				return TableRow(
+					TableCell(
+						e.A(
+							e.Href(NewBucketLinks(o.Bucket).Objects),
+							e.Text(o.Bucket),
+						),
+					),
+					TableCell(
+						e.A(
+							e.Href(NewBucketLinks(o.Bucket).Object(o.Key)),
+							e.Text(o.Key),
+						),
+					),
+					TableCell(bytesText(o.Size)),
+					TableCell(e.Text(formatDateTime(o.CreatedAt))),
+				)
+			}),
+		),
+	)
+}
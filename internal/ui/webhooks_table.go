@@ -0,0 +1,107 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"strings"
+
+	"github.com/cfichtmueller/goparts/e"
+	"github.com/cfichtmueller/stor/internal/domain/notification"
+)
+
+func WebhooksTable(bucket string, webhooks []*notification.Webhook) e.Node {
+	return Table(
+		TableHeader(
+			TableHead("", e.Text("URL")),
+			TableHead("", e.Text("Events")),
+			TableHead("", e.Text("Prefix")),
+			TableHead("", e.Text("Suffix")),
+			TableHead("", e.Text("Secret")),
+			TableHead("flex justify-end", e.Button(
+				e.Class(cn(btn, btnPrimary)),
+				e.HXGet("/c/create-webhook-dialog?bucket="+bucket),
+				e.HXTarget("body"),
+				e.HXSwap("beforeend"),
+				IconPlus,
+				e.Span(
+					srOnly(),
+					e.Text("Add Webhook"),
+				),
+			)),
+		),
+		TableBody(
+			e.Mapf(webhooks, WebhooksTableRow),
+		),
+	)
+}
+
+func WebhooksTableRow(w *notification.Webhook) e.Node {
+	return TableRow(
+		TableCellC("p-2 align-middle break-all", e.Text(w.URL)),
+		TableCellC("p-2 align-middle", e.Text(strings.Join(w.Events, ", "))),
+		TableCellC("p-2 align-middle", e.Text(formatFilter(w.Prefix))),
+		TableCellC("p-2 align-middle", e.Text(formatFilter(w.Suffix))),
+		TableCellC("p-2 align-middle font-mono", e.Text(w.Secret)),
+		TableCellC("p-2 flex w-full justify-end align-middle",
+			e.Button(
+				e.Class(cn(btn, btnDanger)),
+				e.HXDelete("/r/webhook?bucket="+w.Bucket+"&webhook="+w.ID),
+				IconTrash,
+				e.Span(srOnly(), e.Text("Delete Webhook")),
+			),
+		),
+	)
+}
+
+// DeadLettersTable lists the webhook deliveries that failed permanently
+func DeadLettersTable(deliveries []*notification.Delivery) e.Node {
+	if len(deliveries) == 0 {
+		return e.P(
+			e.Class("text-sm text-muted-foreground"),
+			e.Text("All events have been delivered."),
+		)
+	}
+	return Table(
+		TableHeader(
+			TableHead("", e.Text("Event")),
+			TableHead("", e.Text("URL")),
+			TableHead("text-right", e.Text("Attempts")),
+			TableHead("", e.Text("Last error")),
+			TableHead("", e.Text("Failed at")),
+			TableHead("", e.Text("")),
+		),
+		TableBody(
+			e.Mapf(deliveries, func(d *notification.Delivery) e.Node {
+				return TableRow(
+					TableCellC("p-2 align-middle", e.Text(d.Event)),
+					TableCellC("p-2 align-middle break-all", e.Text(d.URL)),
+					TableCellC("text-right", e.Text(formatInt(d.Attempts))),
+					TableCellC("p-2 align-middle", e.Text(d.LastError)),
+					TableCellC("p-2 align-middle", e.Text(formatDateTime(d.UpdatedAt))),
+					TableCellC("p-2 flex w-full justify-end gap-x-2 align-middle",
+						e.Button(
+							e.Class(cn(btn, "shadow")),
+							e.HXPost("/r/webhook-delivery?bucket="+d.Bucket+"&delivery="+d.ID),
+							e.Text("Retry"),
+						),
+						e.Button(
+							e.Class(cn(btn, btnDanger)),
+							e.HXDelete("/r/webhook-delivery?bucket="+d.Bucket+"&delivery="+d.ID),
+							IconTrash,
+							e.Span(srOnly(), e.Text("Discard Delivery")),
+						),
+					),
+				)
+			}),
+		),
+	)
+}
+
+func formatFilter(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
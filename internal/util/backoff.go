@@ -0,0 +1,16 @@
+// Copyright 2026 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package util
+
+import "time"
+
+// Backoff returns the delay after a number of failed attempts. It is delay after the first attempt and
+// doubles with every further attempt up to maxDelay.
+func Backoff(delay, maxDelay time.Duration, attempts int) time.Duration {
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
@@ -11,7 +11,7 @@ import (
 
 type Cache struct {
 	index      map[string]CacheEntry
-	writeMutex sync.Mutex
+	writeMutex sync.RWMutex
 }
 
 type CacheEntry struct {
@@ -26,7 +26,9 @@ func NewCache() *Cache {
 }
 
 func (c *Cache) Get(key string) (any, bool) {
+	c.writeMutex.RLock()
 	v, ok := c.index[key]
+	c.writeMutex.RUnlock()
 	if !ok {
 		return nil, false
 	}
@@ -47,3 +49,14 @@ func (c *Cache) SetTTL(key string, value any, ttl time.Duration) {
 	}
 	c.writeMutex.Unlock()
 }
+
+// DeleteFunc removes all entries for which del returns true
+func (c *Cache) DeleteFunc(del func(key string, value any) bool) {
+	c.writeMutex.Lock()
+	for k, v := range c.index {
+		if del(k, v.value) {
+			delete(c.index, k)
+		}
+	}
+	c.writeMutex.Unlock()
+}